@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/SUSE/saptune/app"
+	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/system"
 	"io"
 	"os"
@@ -18,6 +19,7 @@ const (
 	exitSaptuneStopped = 1
 	exitNotTuned       = 3
 	exitNotCompliant   = 4
+	exitDrift          = 6
 )
 
 // PackageArea is the package area with all notes and solutions shipped by
@@ -106,6 +108,14 @@ func SelectAction(writer io.Writer, stApp *app.App, saptuneVers string) {
 		RefreshAction(os.Stdin, writer, system.CliArg(2), stApp)
 	case "revert":
 		RevertAction(writer, system.CliArg(2), stApp)
+	case "block":
+		BlockAction(writer, system.CliArg(2), system.CliArg(3), stApp)
+	case "snapshot":
+		SnapshotAction(writer, system.CliArg(2), system.CliArgs(3), stApp)
+	case "history":
+		HistoryAction(writer, system.CliArg(2))
+	case "drift":
+		DriftAction(writer, system.CliArg(2), stApp)
 	case "staging":
 		StagingAction(system.CliArg(2), system.CliArgs(3), stApp)
 	case "status":
@@ -154,6 +164,32 @@ func RevertAction(writer io.Writer, actionName string, tuneApp *app.App) {
 		system.InfoLog("Parameters tuned by the notes and solutions have been successfully reverted.")
 		fmt.Fprintf(writer, "Parameters tuned by the notes and solutions have been successfully reverted.\n")
 	}
+	if system.IsFlagSet("to-baseline") {
+		system.InfoLog("Restoring the baseline values of all parameters...")
+		fmt.Fprintf(writer, "Restoring the baseline values of all parameters...\n")
+		if err := note.RestoreBaseline(true); err != nil {
+			system.ErrorExit("Failed to restore the baseline values: %v", err)
+		}
+		system.InfoLog("Parameters have been successfully restored to their baseline values.")
+		fmt.Fprintf(writer, "Parameters have been successfully restored to their baseline values.\n")
+	}
+}
+
+// BlockAction applies the block device settings of all applied Notes to a
+// block device added after the Notes were applied (e.g. called by udev for
+// a hot-plugged device)
+func BlockAction(writer io.Writer, actionName, bdev string, tuneApp *app.App) {
+	if actionName != "apply" || bdev == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	bdev = strings.TrimPrefix(bdev, "/dev/")
+	if len(tuneApp.NoteApplyOrder) == 0 {
+		system.InfoLog("No notes or solutions enabled, nothing to apply for block device '%s'.", bdev)
+		return
+	}
+	if err := tuneApp.TuneBlockDevice(bdev); err != nil {
+		system.ErrorExit("Failed to apply the block device settings to device '%s': %v", bdev, err)
+	}
 }
 
 // rememberMessage prints a reminder message
@@ -161,6 +161,20 @@ Parameters tuned by the notes and solutions have been successfully reverted.
 	txt := buffer.String()
 	checkOut(t, txt, revertMatchText)
 
+	// revert to baseline, no baseline values available
+	var baselineMatchText = `Restoring the baseline values of all parameters...
+Parameters have been successfully restored to their baseline values.
+`
+	orgArgs := os.Args
+	os.Args = []string{"saptune", "revert", "all", "--to-baseline"}
+	system.RereadArgs()
+	buffer.Reset()
+	RevertAction(&buffer, "all", tApp)
+	txt = buffer.String()
+	checkOut(t, txt, baselineMatchText)
+	os.Args = orgArgs
+	system.RereadArgs()
+
 	// test for PrintHelpAndExit
 	oldOSExit := system.OSExit
 	defer func() { system.OSExit = oldOSExit }()
@@ -194,6 +208,36 @@ Parameters tuned by the notes and solutions have been successfully reverted.
 	tearDown(t)
 }
 
+func TestBlockAction(t *testing.T) {
+	buffer := bytes.Buffer{}
+	// block device not available
+	BlockAction(&buffer, "apply", "/dev/sdzz", tApp)
+	txt := buffer.String()
+	checkOut(t, txt, "")
+
+	// test for PrintHelpAndExit
+	oldOSExit := system.OSExit
+	defer func() { system.OSExit = oldOSExit }()
+	system.OSExit = tstosExit
+	oldErrorExitOut := system.ErrorExitOut
+	defer func() { system.ErrorExitOut = oldErrorExitOut }()
+	system.ErrorExitOut = tstErrorExitOut
+
+	buffer.Reset()
+	errExitbuffer := bytes.Buffer{}
+	tstwriter = &errExitbuffer
+	BlockAction(&buffer, "revert", "sdzz", tApp)
+	txt = buffer.String()
+	checkOut(t, txt, PrintHelpAndExitMatchText)
+	if tstRetErrorExit != 1 {
+		t.Errorf("error exit should be '1' and NOT '%v'\n", tstRetErrorExit)
+	}
+	errExOut := errExitbuffer.String()
+	if errExOut != "" {
+		t.Errorf("wrong text returned by ErrorExit: '%v' instead of ''\n", errExOut)
+	}
+}
+
 func TestGetFileName(t *testing.T) {
 	tstRetErrorExit = -1
 	oldOSExit := system.OSExit
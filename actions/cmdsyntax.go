@@ -16,12 +16,14 @@ Daemon control:
 Tune system according to SAP and SUSE notes:
   saptune [--format FORMAT] [--force-color] [--fun] note ( list | verify | revertall | enabled | applied )
   saptune [--format FORMAT] [--force-color] [--fun] note ( apply | simulate | customise | create | edit | revert | show | delete ) NOTEID
+  saptune [--format FORMAT] [--force-color] [--fun] note apply [--atomic] NOTEID
   saptune [--format FORMAT] [--force-color] [--fun] note refresh [NOTEID|applied] ATTENTION: experimental
   saptune [--format FORMAT] [--force-color] [--fun] note verify [--colorscheme SCHEME] [--show-non-compliant] [NOTEID|applied]
   saptune [--format FORMAT] [--force-color] [--fun] note rename NOTEID NEWNOTEID
 Tune system for all notes applicable to your SAP solution:
   saptune [--format FORMAT] [--force-color] [--fun] solution ( list | verify | enabled | applied )
   saptune [--format FORMAT] [--force-color] [--fun] solution ( apply | simulate | customise | create | edit | revert | show | delete ) SOLUTIONNAME
+  saptune [--format FORMAT] [--force-color] [--fun] solution apply [--atomic] SOLUTIONNAME
   saptune [--format FORMAT] [--force-color] [--fun] solution change [--force] SOLUTIONNAME
   saptune [--format FORMAT] [--force-color] [--fun] solution verify [--colorscheme SCHEME] [--show-non-compliant] [SOLUTIONNAME]
   saptune [--format FORMAT] [--force-color] [--fun] solution rename SOLUTIONNAME NEWSOLUTIONNAME
@@ -37,7 +39,17 @@ Verify all applied Notes:
 Refresh all applied Notes:
   saptune [--format FORMAT] [--force-color] [--fun] refresh applied ATTENTION: experimental
 Revert all parameters tuned by the SAP notes or solutions:
-  saptune [--format FORMAT] [--force-color] [--fun] revert all
+  saptune [--format FORMAT] [--force-color] [--fun] revert all [--to-baseline]
+Apply the block device settings of all applied Notes to a hot-plugged block device:
+  saptune [--format FORMAT] [--force-color] [--fun] block apply DEVICE
+Create, list, show, compare or restore snapshots of the system tuning:
+  saptune [--format FORMAT] [--force-color] [--fun] snapshot ( create | show | restore ) NAME
+  saptune [--format FORMAT] [--force-color] [--fun] snapshot list
+  saptune [--format FORMAT] [--force-color] [--fun] snapshot diff NAME [NAME]
+Show the history of the parameter changes:
+  saptune [--format FORMAT] [--force-color] [--fun] history [--param KEY] [--note NOTEID] [--since TIME]
+Show the parameters changed after saptune has set them:
+  saptune [--format FORMAT] [--force-color] [--fun] drift
 Remove the pending lock file from a former saptune call
   saptune [--format FORMAT] [--force-color] [--fun] lock remove
 Call external script '/usr/sbin/saptune_check'
@@ -65,12 +77,14 @@ Daemon control:
 Tune system according to SAP and SUSE notes:
   saptune [--format FORMAT] [--force-color] [--fun] note ( list | verify | revertall | enabled | applied )
   saptune [--format FORMAT] [--force-color] [--fun] note ( apply | customise | create | edit | revert | show | delete ) NOTEID
+  saptune [--format FORMAT] [--force-color] [--fun] note apply [--atomic] NOTEID
   saptune [--format FORMAT] [--force-color] [--fun] note refresh [NOTEID|applied] ATTENTION: experimental
   saptune [--format FORMAT] [--force-color] [--fun] note verify [--colorscheme SCHEME] [--show-non-compliant] [NOTEID|applied]
   saptune [--format FORMAT] [--force-color] [--fun] note rename NOTEID NEWNOTEID
 Tune system for all notes applicable to your SAP solution:
   saptune [--format FORMAT] [--force-color] [--fun] solution ( list | verify | enabled | applied )
   saptune [--format FORMAT] [--force-color] [--fun] solution ( apply | customise | create | edit | revert | show | delete ) SOLUTIONNAME
+  saptune [--format FORMAT] [--force-color] [--fun] solution apply [--atomic] SOLUTIONNAME
   saptune [--format FORMAT] [--force-color] [--fun] solution change [--force] SOLUTIONNAME
   saptune [--format FORMAT] [--force-color] [--fun] solution verify [--colorscheme SCHEME] [--show-non-compliant] [SOLUTIONNAME]
   saptune [--format FORMAT] [--force-color] [--fun] solution rename SOLUTIONNAME NEWSOLUTIONNAME
@@ -86,7 +100,17 @@ Verify all applied Notes:
 Refresh all applied Notes:
   saptune [--format FORMAT] [--force-color] [--fun] refresh applied ATTENTION: experimental
 Revert all parameters tuned by the SAP notes or solutions:
-  saptune [--format FORMAT] [--force-color] [--fun] revert all
+  saptune [--format FORMAT] [--force-color] [--fun] revert all [--to-baseline]
+Apply the block device settings of all applied Notes to a hot-plugged block device:
+  saptune [--format FORMAT] [--force-color] [--fun] block apply DEVICE
+Create, list, show, compare or restore snapshots of the system tuning:
+  saptune [--format FORMAT] [--force-color] [--fun] snapshot ( create | show | restore ) NAME
+  saptune [--format FORMAT] [--force-color] [--fun] snapshot list
+  saptune [--format FORMAT] [--force-color] [--fun] snapshot diff NAME [NAME]
+Show the history of the parameter changes:
+  saptune [--format FORMAT] [--force-color] [--fun] history [--param KEY] [--note NOTEID] [--since TIME]
+Show the parameters changed after saptune has set them:
+  saptune [--format FORMAT] [--force-color] [--fun] drift
 Remove the pending lock file from a former saptune call
   saptune [--format FORMAT] [--force-color] [--fun] lock remove
 Call external script '/usr/sbin/saptune_check'
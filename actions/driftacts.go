@@ -0,0 +1,61 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/app"
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"io"
+)
+
+// DriftAction compares the current system values with the values saptune
+// has set last during the apply of the Notes and reports the parameters,
+// which were changed outside of saptune.
+// Exits with 'exitDrift', if drifted parameters were found
+func DriftAction(writer io.Writer, actionName string, tuneApp *app.App) {
+	if actionName != "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	result := system.JDrift{Drifts: []system.JDriftEntry{}}
+	if len(tuneApp.NoteApplyOrder) == 0 {
+		fmt.Fprintf(writer, "No Notes applied, so there are no parameter values set by saptune to check.\n")
+		system.Jcollect(result)
+		return
+	}
+	drifts := note.DriftedParameters(tuneApp.NoteApplyOrder, tuneApp.AllNotes, driftServices())
+	for _, drift := range drifts {
+		result.Drifts = append(result.Drifts, system.JDriftEntry(drift))
+	}
+	result.DriftDetected = len(drifts) != 0
+	system.Jcollect(result)
+	if len(drifts) == 0 {
+		fmt.Fprintf(writer, "No drift detected. All parameters still have the values set by saptune.\n")
+		return
+	}
+	fmt.Fprintf(writer, "\nThe following parameters were changed after saptune has set them:\n\n")
+	rows := [][]string{}
+	for _, drift := range drifts {
+		source := drift.Source
+		if source == "" {
+			source = "unknown"
+		}
+		rows = append(rows, []string{drift.Param, drift.NoteID, valueOrDash(drift.Expected), valueOrDash(drift.Actual), source})
+	}
+	printListTable(writer, []string{"Parameter", "Note ID", "Set by saptune", "Actual value", "Likely source"}, rows)
+	system.ErrorExit("", exitDrift)
+}
+
+// driftServices returns the active tuning services, which may change the
+// parameters set by saptune
+func driftServices() []string {
+	services := []string{}
+	if system.IsServiceAvailable(TunedService) {
+		if active, _ := system.SystemctlIsRunning(TunedService); active {
+			services = append(services, fmt.Sprintf("%s (profile '%s')", TunedService, system.GetTunedAdmProfile()))
+		}
+	}
+	if system.IsSapconfActive(SapconfService) {
+		services = append(services, SapconfService)
+	}
+	return services
+}
@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"bytes"
+	"github.com/SUSE/saptune/system"
+	"testing"
+)
+
+func TestDriftAction(t *testing.T) {
+	noDriftMatchText := "No drift detected. All parameters still have the values set by saptune.\n"
+	buffer := bytes.Buffer{}
+	DriftAction(&buffer, "", tApp)
+	txt := buffer.String()
+	checkOut(t, txt, noDriftMatchText)
+
+	// no Notes applied
+	noteApplyOrder := tApp.NoteApplyOrder
+	tApp.NoteApplyOrder = []string{}
+	buffer.Reset()
+	DriftAction(&buffer, "", tApp)
+	txt = buffer.String()
+	checkOut(t, txt, "No Notes applied, so there are no parameter values set by saptune to check.\n")
+	tApp.NoteApplyOrder = noteApplyOrder
+
+	// test for PrintHelpAndExit
+	oldOSExit := system.OSExit
+	defer func() { system.OSExit = oldOSExit }()
+	system.OSExit = tstosExit
+	oldErrorExitOut := system.ErrorExitOut
+	defer func() { system.ErrorExitOut = oldErrorExitOut }()
+	system.ErrorExitOut = tstErrorExitOut
+
+	buffer.Reset()
+	errExitbuffer := bytes.Buffer{}
+	tstwriter = &errExitbuffer
+	DriftAction(&buffer, "all", tApp)
+	txt = buffer.String()
+	checkOut(t, txt, PrintHelpAndExitMatchText+noDriftMatchText)
+	if tstRetErrorExit != 1 {
+		t.Errorf("error exit should be '1' and NOT '%v'\n", tstRetErrorExit)
+	}
+	errExOut := errExitbuffer.String()
+	if errExOut != "" {
+		t.Errorf("wrong text returned by ErrorExit: '%v' instead of ''\n", errExOut)
+	}
+}
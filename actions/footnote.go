@@ -30,6 +30,8 @@ const (
 	footnote14   = "[14] the parameter value exceeds the maximum possible number of open files. Check and increase fs.nr_open if really needed."
 	footnote15   = "[15] the parameter is only used to calculate the size of tmpfs (/dev/shm)"
 	footnote16   = "[16] parameter not available on the system, setting not possible"
+	footnote17   = "[17] not all requested hugepages are allocated, HPINFO. The free memory may be too fragmented, allocate the hugepages early during boot or reduce the requested number"
+	footnote18   = "[18] paths of the multipath device differ: MPINFO"
 )
 
 // set 'unsupported' footnote regarding the architecture
@@ -55,7 +57,7 @@ func prepFN(comparison note.FieldComparison, compliant, inform string) bool {
 			// compliant will be set to '-' during footnote preparation
 			prep = true
 		}
-		if strings.Contains(comparison.ReflectMapKey, "xfsopt_") {
+		if system.IsFSOption.MatchString(comparison.ReflectMapKey) {
 			prep = true
 		}
 	}
@@ -80,7 +82,7 @@ func prepareFootnote(comparison note.FieldComparison, compliant, comment, inform
 	}
 	// set footnote for unsupported or not available parameter [1],[2]
 	compliant, comment, footnote = setUsNa(comparison.ActualValue.(string), compliant, comment, footnote)
-	// set footnote for rpm or grub parameter [3],[6]
+	// set footnote for rpm, grub or check-only mount option parameter [3],[6]
 	compliant, comment, footnote = setRpmGrub(comparison, compliant, comment, footnote)
 	// set footnote for diffs in force_latency parameter [4]
 	compliant, comment, footnote = setFLdiffs(comparison.ReflectMapKey, compliant, comment, inform, footnote)
@@ -89,7 +91,7 @@ func prepareFootnote(comparison note.FieldComparison, compliant, comment, inform
 	// set footnote for untouched parameter [7]
 	compliant, comment, footnote = setUntouched(comparison.ExpectedValue.(string), compliant, comment, footnote)
 	// set footnote for secure boot [8]
-	compliant, comment, footnote = setSecBoot(comparison.ReflectMapKey, compliant, comment, footnote)
+	compliant, comment, footnote = setSecBoot(comparison.ReflectMapKey, comparison.ActualValue.(string), compliant, comment, footnote)
 	// set footnote for limited parameter value [9]
 	compliant, comment, footnote = setLimited(comparison.ReflectMapKey, compliant, comment, inform, footnote)
 	// set footnote for double defined parameters [10]
@@ -104,6 +106,10 @@ func prepareFootnote(comparison note.FieldComparison, compliant, comment, inform
 	compliant, comment, footnote = setNofile(comparison.ReflectMapKey, compliant, comment, inform, footnote)
 	// set footnote for VSZ_TMPFS_PERCENT parameter from mem section
 	compliant, comment, footnote = setMem(comparison.ReflectMapKey, compliant, comment, footnote)
+	// set footnote for not allocated hugepages [17]
+	compliant, comment, footnote = setHugepages(comparison.ReflectMapKey, compliant, comment, inform, footnote)
+	// set footnote for differing multipath paths [18]
+	compliant, comment, footnote = setMpathDiffs(comparison.ReflectMapKey, compliant, comment, inform, footnote)
 	return compliant, comment, footnote
 }
 
@@ -129,10 +135,11 @@ func setUsNa(actVal, compliant, comment string, footnote []string) (string, stri
 	return compliant, comment, footnote
 }
 
-// setRpmGrub sets footnote for rpm or grub parameter
+// setRpmGrub sets footnote for rpm or grub parameter and for mount options,
+// which can not be changed by a remount
 func setRpmGrub(comparison note.FieldComparison, compliant, comment string, footnote []string) (string, string, []string) {
 	mapKey := comparison.ReflectMapKey
-	if strings.Contains(mapKey, "rpm") || strings.Contains(mapKey, "grub") {
+	if strings.Contains(mapKey, "rpm") || strings.Contains(mapKey, "grub") || note.IsCheckOnlyFSOption(mapKey) {
 		compliant = compliant + " [3]"
 		comment = comment + " [3]"
 		footnote[2] = footnote3
@@ -159,8 +166,9 @@ func setUntouched(expVal, compliant, comment string, footnote []string) (string,
 }
 
 // setSecBoot sets footnote for secure boot affected parameter
-func setSecBoot(mapKey, compliant, comment string, footnote []string) (string, string, []string) {
-	if mapKey == "energy_perf_bias" && system.SecureBootEnabled() {
+// only, if the energy_perf_bias value is not available on the system
+func setSecBoot(mapKey, actVal, compliant, comment string, footnote []string) (string, string, []string) {
+	if mapKey == "energy_perf_bias" && actVal == "all:none" && system.SecureBootEnabled() {
 		compliant = compliant + " [8]"
 		comment = comment + " [8]"
 		footnote[7] = footnote8
@@ -212,7 +220,7 @@ func setLimited(mapKey, compliant, comment, info string, footnote []string) (str
 
 // setDouble sets footnote for double defined sys parameters
 func setDouble(mapKey, compliant, comment, info string, footnote []string) (string, string, []string) {
-	if (system.IsSched.MatchString(mapKey) || system.IsNrreq.MatchString(mapKey) || system.IsRahead.MatchString(mapKey) || system.IsMsect.MatchString(mapKey)) && info != "" {
+	if (system.IsSched.MatchString(mapKey) || system.IsNrreq.MatchString(mapKey) || system.IsRahead.MatchString(mapKey) || system.IsMsect.MatchString(mapKey) || system.IsRqAffinity.MatchString(mapKey) || system.IsNomerges.MatchString(mapKey) || system.IsWbtLat.MatchString(mapKey) || system.IsAddRandom.MatchString(mapKey) || system.IsIOSched.MatchString(mapKey)) && info != "" {
 		// check for double defined parameters
 		sect := regexp.MustCompile(`.*\[\w+\].*`)
 		inf := strings.Split(info, "§")
@@ -258,7 +266,7 @@ func setSysctlGlobal(compliant, comment, info string, footnote []string) (string
 // setFSOptions sets footnote for not matching filesystem options
 func setFSOptions(comparison note.FieldComparison, compliant, comment, info string, footnote []string) (string, string, []string) {
 	// check if there are mount points with wrong FS option settings
-	if strings.Contains(comparison.ReflectMapKey, "xfsopt_") {
+	if system.IsFSOption.MatchString(comparison.ReflectMapKey) {
 		if !system.IsFlagSet("show-non-compliant") && info != "" {
 			// fs option info
 			compliant = compliant + " [12]"
@@ -296,6 +304,27 @@ func setMem(mapKey, compliant, comment string, footnote []string) (string, strin
 	return compliant, comment, footnote
 }
 
+// setHugepages sets footnote for not allocated hugepages
+func setHugepages(mapKey, compliant, comment, info string, footnote []string) (string, string, []string) {
+	if strings.HasPrefix(mapKey, "nr_hugepages") && info != "" {
+		compliant = compliant + " [17]"
+		comment = comment + " [17]"
+		footnote[16] = writeFN(footnote[16], footnote17, mapKey+": "+info, "HPINFO")
+	}
+	return compliant, comment, footnote
+}
+
+// setMpathDiffs sets footnote for paths of a multipath device, which differ
+// from the multipath device
+func setMpathDiffs(mapKey, compliant, comment, info string, footnote []string) (string, string, []string) {
+	if strings.HasPrefix(info, note.MpathDiffsInfo) {
+		compliant = compliant + " [18]"
+		comment = comment + " [18]"
+		footnote[17] = writeFN(footnote[17], footnote18, mapKey+": "+strings.TrimPrefix(info, note.MpathDiffsInfo), "MPINFO")
+	}
+	return compliant, comment, footnote
+}
+
 // writeFN customizes the text for footnotes by replacing strings/placeholder
 func writeFN(footnote, fntxt, info, pat string) string {
 	if footnote == "" {
@@ -0,0 +1,36 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"io"
+)
+
+// HistoryAction prints the parameter changes made by saptune
+// The changes can be filtered by parameter name (--param), Note ID (--note)
+// and time (--since)
+func HistoryAction(writer io.Writer, actionName string) {
+	if actionName != "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	entries, err := note.GetHistory(system.GetFlagVal("param"), system.GetFlagVal("note"), system.GetFlagVal("since"))
+	if err != nil {
+		system.ErrorExit("Failed to read the parameter change history: %v", err)
+	}
+	result := system.JHistory{Changes: []system.JHistoryEntry{}}
+	for _, entry := range entries {
+		result.Changes = append(result.Changes, system.JHistoryEntry(entry))
+	}
+	system.Jcollect(result)
+	if len(entries) == 0 {
+		fmt.Fprintf(writer, "No parameter changes found.\n")
+		return
+	}
+	fmt.Fprintf(writer, "\nParameter changes:\n\n")
+	rows := [][]string{}
+	for _, entry := range entries {
+		rows = append(rows, []string{entry.Timestamp, entry.NoteID, entry.Action, entry.Param, valueOrDash(entry.OldValue), valueOrDash(entry.NewValue), entry.CmdLine})
+	}
+	printListTable(writer, []string{"Date", "Note ID", "Action", "Parameter", "Old value", "New value", "Command line"}, rows)
+}
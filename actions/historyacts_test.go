@@ -0,0 +1,80 @@
+package actions
+
+import (
+	"bytes"
+	"github.com/SUSE/saptune/system"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestHistoryAction(t *testing.T) {
+	// preserve the history file of the system
+	orgHistory, err := os.ReadFile(system.SaptuneHistoryFile)
+	defer func() {
+		if err != nil {
+			os.Remove(system.SaptuneHistoryFile)
+		} else {
+			_ = os.WriteFile(system.SaptuneHistoryFile, orgHistory, 0644)
+		}
+	}()
+	_ = os.MkdirAll(path.Dir(system.SaptuneHistoryFile), 0755)
+	entry := `{"timestamp":"2024-01-31 10:15:30","command line":"saptune note apply historyTest","action":"apply","Note ID":"historyTest","section":"sysctl","parameter":"vm.swappiness","old value":"60","new value":"10"}` + "\n"
+	if err := os.WriteFile(system.SaptuneHistoryFile, append(orgHistory, []byte(entry)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orgArgs := os.Args
+	defer func() {
+		os.Args = orgArgs
+		system.RereadArgs()
+	}()
+	buffer := bytes.Buffer{}
+	os.Args = []string{"saptune", "history", "--note", "historyTest"}
+	system.RereadArgs()
+	HistoryAction(&buffer, "")
+	txt := buffer.String()
+	if !strings.HasPrefix(txt, "\nParameter changes:\n\n") || !strings.Contains(txt, " 2024-01-31 10:15:30 | historyTest | apply  | vm.swappiness | 60        | 10        | saptune note apply historyTest\n") {
+		t.Errorf("wrong output of 'history': '%s'", txt)
+	}
+
+	buffer.Reset()
+	os.Args = []string{"saptune", "history", "--note", "historyTest", "--since", "2024-02-01"}
+	system.RereadArgs()
+	HistoryAction(&buffer, "")
+	txt = buffer.String()
+	checkOut(t, txt, "No parameter changes found.\n")
+
+	// test for PrintHelpAndExit and ErrorExit
+	oldOSExit := system.OSExit
+	defer func() { system.OSExit = oldOSExit }()
+	system.OSExit = tstosExit
+	oldErrorExitOut := system.ErrorExitOut
+	defer func() { system.ErrorExitOut = oldErrorExitOut }()
+	system.ErrorExitOut = tstErrorExitOut
+
+	buffer.Reset()
+	errExitbuffer := bytes.Buffer{}
+	tstwriter = &errExitbuffer
+	HistoryAction(&buffer, "all")
+	txt = buffer.String()
+	checkOut(t, txt, PrintHelpAndExitMatchText+"No parameter changes found.\n")
+	if tstRetErrorExit != 1 {
+		t.Errorf("error exit should be '1' and NOT '%v'\n", tstRetErrorExit)
+	}
+
+	tstRetErrorExit = -1
+	buffer.Reset()
+	errExitbuffer.Reset()
+	os.Args = []string{"saptune", "history", "--since", "yesterday"}
+	system.RereadArgs()
+	HistoryAction(&buffer, "")
+	if tstRetErrorExit != 1 {
+		t.Errorf("error exit should be '1' and NOT '%v'\n", tstRetErrorExit)
+	}
+	errExOut := errExitbuffer.String()
+	if !strings.Contains(errExOut, "wrong time 'yesterday'") {
+		t.Errorf("wrong text returned by ErrorExit: '%v'\n", errExOut)
+	}
+}
@@ -72,7 +72,12 @@ func NoteActionApply(writer io.Writer, noteID string, tuneApp *app.App) {
 		}
 		system.ErrorExit("", 0)
 	}
-	if err := tuneApp.TuneNote(noteID); err != nil {
+	tuneNote := tuneApp.TuneNote
+	if system.IsFlagSet("atomic") {
+		// transactional apply with rollback
+		tuneNote = tuneApp.TuneNoteAtomic
+	}
+	if err := tuneNote(noteID); err != nil {
 		system.ErrorExit("Failed to tune for note %s: %v", noteID, err)
 	}
 	fmt.Fprintf(writer, "The note has been applied successfully.\n")
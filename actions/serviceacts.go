@@ -8,6 +8,7 @@ import (
 	"github.com/SUSE/saptune/txtparser"
 	"io"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -230,6 +231,9 @@ func ServiceActionStatus(writer io.Writer, tuneApp *app.App, saptuneVersion stri
 	// check tuning result
 	infoTrigger["notCompliant"] = chkTuningResult(writer, tuneApp, &jstatus)
 
+	// check for boot options waiting for a reboot
+	infoTrigger["reboot"] = printRebootStatus(writer, &jstatus)
+
 	infoMsg := bytes.Buffer{}
 	if system.GetFlagVal("format") == "json" {
 		writer = &infoMsg
@@ -749,6 +753,19 @@ func chkTuningResult(writer io.Writer, tuneApp *app.App, jstat *system.JStatus)
 	return notCompliant
 }
 
+// printRebootStatus prints the boot options changed by saptune in the boot
+// loader configuration, which are not yet active in the running kernel
+func printRebootStatus(writer io.Writer, jstat *system.JStatus) bool {
+	pending := system.BootOptionsPending()
+	if len(pending) == 0 {
+		return false
+	}
+	sort.Strings(pending)
+	fmt.Fprintf(writer, "reboot required:          yes (%s)\n", strings.Join(pending, ", "))
+	jstat.RebootRequired = pending
+	return true
+}
+
 // printVirtStatus prints the virtualization environment
 func printVirtStatus(writer io.Writer, jstat *system.JStatus) {
 	vtype := system.GetVirtStatus()
@@ -780,6 +797,9 @@ func printInfoBlock(writer io.Writer, infoTrigger map[string]bool) {
 	if infoTrigger["chkHint"] {
 		fmt.Fprintf(writer, "The systemd system state is NOT ok.\n")
 	}
+	if infoTrigger["reboot"] {
+		fmt.Fprintf(writer, "The boot loader configuration was changed by saptune. Please reboot the system to activate the new kernel command line.\n")
+	}
 	if (infoTrigger["stenabled"] && infoTrigger["scenabled"]) || infoTrigger["chkHint"] {
 		fmt.Fprintf(writer, "Please call 'saptune check' to get guidance to resolve the issues!\n")
 	}
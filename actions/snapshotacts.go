@@ -0,0 +1,140 @@
+package actions
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/app"
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/system"
+	"io"
+	"strconv"
+)
+
+// SnapshotAction  Snapshot actions like create, list, show, diff and restore
+func SnapshotAction(writer io.Writer, actionName string, names []string, tuneApp *app.App) {
+	name := ""
+	if len(names) > 0 {
+		name = names[0]
+	}
+	switch actionName {
+	case "create":
+		SnapshotActionCreate(writer, name, tuneApp)
+	case "list":
+		SnapshotActionList(writer)
+	case "show":
+		SnapshotActionShow(writer, name)
+	case "diff":
+		SnapshotActionDiff(writer, names, tuneApp)
+	case "restore":
+		SnapshotActionRestore(writer, name, tuneApp)
+	default:
+		PrintHelpAndExit(writer, 1)
+	}
+}
+
+// SnapshotActionCreate captures the current values of all parameters known
+// by the Note definitions and stores them as snapshot
+func SnapshotActionCreate(writer io.Writer, name string, tuneApp *app.App) {
+	if name == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	snap, err := note.CreateSnapshot(name, tuneApp.AllNotes)
+	if err != nil {
+		system.ErrorExit("Failed to create snapshot '%s': %v", name, err)
+	}
+	fmt.Fprintf(writer, "Snapshot '%s' with %d parameters has been created successfully.\n", name, len(snap.Params))
+}
+
+// SnapshotActionList lists all available snapshots
+func SnapshotActionList(writer io.Writer) {
+	snaps := note.ListSnapshots()
+	if len(snaps) == 0 {
+		fmt.Fprintf(writer, "No snapshots available.\n")
+		return
+	}
+	fmt.Fprintf(writer, "\nAll snapshots:\n\n")
+	rows := [][]string{}
+	for _, snap := range snaps {
+		rows = append(rows, []string{snap.Name, snap.Date, snap.Kernel, strconv.Itoa(len(snap.Params))})
+	}
+	printListTable(writer, []string{"Name", "Date", "Kernel", "Parameters"}, rows)
+}
+
+// SnapshotActionShow prints the parameter values of a snapshot
+func SnapshotActionShow(writer io.Writer, name string) {
+	if name == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	snap, err := note.GetSnapshot(name)
+	if err != nil {
+		system.ErrorExit("%v", err)
+	}
+	fmt.Fprintf(writer, "\nSnapshot '%s' created at %s (kernel %s):\n\n", snap.Name, snap.Date, snap.Kernel)
+	rows := [][]string{}
+	for _, sparam := range snap.Params {
+		rows = append(rows, []string{sparam.Section, sparam.Key, sparam.Value})
+	}
+	printListTable(writer, []string{"Section", "Parameter", "Value"}, rows)
+}
+
+// SnapshotActionDiff prints the parameters with different values in two
+// snapshots or, if only one snapshot is given, in the snapshot and the
+// current system
+func SnapshotActionDiff(writer io.Writer, names []string, tuneApp *app.App) {
+	if len(names) == 0 || len(names) > 2 {
+		PrintHelpAndExit(writer, 1)
+	}
+	snap1, err := note.GetSnapshot(names[0])
+	if err != nil {
+		system.ErrorExit("%v", err)
+	}
+	var snap2 note.Snapshot
+	txtSnap2 := "the current system"
+	if len(names) == 2 {
+		snap2, err = note.GetSnapshot(names[1])
+		if err != nil {
+			system.ErrorExit("%v", err)
+		}
+		txtSnap2 = fmt.Sprintf("snapshot '%s'", names[1])
+	} else {
+		snap2 = note.CaptureSnapshot("current system", tuneApp.AllNotes)
+	}
+	diffs := note.DiffSnapshots(snap1, snap2)
+	if len(diffs) == 0 {
+		fmt.Fprintf(writer, "No differences found between snapshot '%s' and %s.\n", names[0], txtSnap2)
+		return
+	}
+	fmt.Fprintf(writer, "\nDifferences between snapshot '%s' and %s:\n\n", names[0], txtSnap2)
+	rows := [][]string{}
+	for _, diff := range diffs {
+		rows = append(rows, []string{diff.Section, diff.Key, valueOrDash(diff.Value1), valueOrDash(diff.Value2)})
+	}
+	printListTable(writer, []string{"Section", "Parameter", snap1.Name, snap2.Name}, rows)
+}
+
+// SnapshotActionRestore sets the parameters back to the values captured by
+// the snapshot
+func SnapshotActionRestore(writer io.Writer, name string, tuneApp *app.App) {
+	if name == "" {
+		PrintHelpAndExit(writer, 1)
+	}
+	snap, err := note.GetSnapshot(name)
+	if err != nil {
+		system.ErrorExit("%v", err)
+	}
+	if len(tuneApp.NoteApplyOrder) != 0 {
+		system.NoticeLog("There are still Notes applied. A later revert of these Notes will set their parameters back to the values saved during the apply of the Notes.")
+	}
+	restored, skipped, err := note.RestoreSnapshot(snap, tuneApp.AllNotes)
+	if err != nil {
+		system.ErrorExit("Failed to restore snapshot '%s': %v", name, err)
+	}
+	fmt.Fprintf(writer, "%d parameters have been successfully restored to the values of snapshot '%s'.\n", len(restored), name)
+	if len(skipped) != 0 {
+		fmt.Fprintf(writer, "\nThe following parameters differ from the snapshot, but can not be restored without the related Note definitions:\n\n")
+		rows := [][]string{}
+		for _, sparam := range skipped {
+			rows = append(rows, []string{sparam.Section, sparam.Key, sparam.Value})
+		}
+		printListTable(writer, []string{"Section", "Parameter", "Value"}, rows)
+	}
+}
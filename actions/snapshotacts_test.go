@@ -0,0 +1,85 @@
+package actions
+
+import (
+	"bytes"
+	"github.com/SUSE/saptune/system"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotAction(t *testing.T) {
+	defer os.RemoveAll(system.SaptuneSnapshotDir)
+	buffer := bytes.Buffer{}
+	SnapshotAction(&buffer, "list", []string{}, tApp)
+	txt := buffer.String()
+	checkOut(t, txt, "No snapshots available.\n")
+
+	buffer.Reset()
+	SnapshotAction(&buffer, "create", []string{"snaptest"}, tApp)
+	txt = buffer.String()
+	if !strings.HasPrefix(txt, "Snapshot 'snaptest' with ") || !strings.HasSuffix(txt, " parameters has been created successfully.\n") {
+		t.Errorf("wrong output of 'snapshot create': '%s'", txt)
+	}
+
+	buffer.Reset()
+	SnapshotAction(&buffer, "list", []string{}, tApp)
+	txt = buffer.String()
+	if !strings.Contains(txt, "All snapshots:") || !strings.Contains(txt, " snaptest ") {
+		t.Errorf("wrong output of 'snapshot list': '%s'", txt)
+	}
+
+	buffer.Reset()
+	SnapshotAction(&buffer, "show", []string{"snaptest"}, tApp)
+	txt = buffer.String()
+	if !strings.HasPrefix(txt, "\nSnapshot 'snaptest' created at ") {
+		t.Errorf("wrong output of 'snapshot show': '%s'", txt)
+	}
+
+	buffer.Reset()
+	SnapshotAction(&buffer, "diff", []string{"snaptest", "snaptest"}, tApp)
+	txt = buffer.String()
+	checkOut(t, txt, "No differences found between snapshot 'snaptest' and snapshot 'snaptest'.\n")
+
+	buffer.Reset()
+	SnapshotAction(&buffer, "restore", []string{"snaptest"}, tApp)
+	txt = buffer.String()
+	if !strings.Contains(txt, "parameters have been successfully restored to the values of snapshot 'snaptest'.\n") {
+		t.Errorf("wrong output of 'snapshot restore': '%s'", txt)
+	}
+
+	// test for PrintHelpAndExit
+	oldOSExit := system.OSExit
+	defer func() { system.OSExit = oldOSExit }()
+	system.OSExit = tstosExit
+	oldErrorExitOut := system.ErrorExitOut
+	defer func() { system.ErrorExitOut = oldErrorExitOut }()
+	system.ErrorExitOut = tstErrorExitOut
+
+	buffer.Reset()
+	errExitbuffer := bytes.Buffer{}
+	tstwriter = &errExitbuffer
+	SnapshotAction(&buffer, "unknown", []string{}, tApp)
+	txt = buffer.String()
+	checkOut(t, txt, PrintHelpAndExitMatchText)
+	if tstRetErrorExit != 1 {
+		t.Errorf("error exit should be '1' and NOT '%v'\n", tstRetErrorExit)
+	}
+	errExOut := errExitbuffer.String()
+	if errExOut != "" {
+		t.Errorf("wrong text returned by ErrorExit: '%v' instead of ''\n", errExOut)
+	}
+
+	// not existing snapshot
+	tstRetErrorExit = -1
+	buffer.Reset()
+	errExitbuffer.Reset()
+	SnapshotAction(&buffer, "show", []string{"unknown"}, tApp)
+	if tstRetErrorExit != 1 {
+		t.Errorf("error exit should be '1' and NOT '%v'\n", tstRetErrorExit)
+	}
+	errExOut = errExitbuffer.String()
+	if !strings.Contains(errExOut, "snapshot 'unknown' not found") {
+		t.Errorf("wrong text returned by ErrorExit: '%v'\n", errExOut)
+	}
+}
@@ -630,7 +630,12 @@ func getNoteInSol(tApp *app.App, noteName string) (string, string) {
 
 // applySolution will apply the given solution
 func applySolution(writer io.Writer, solName string, tuneApp *app.App) {
-	removedAdditionalNotes, err := tuneApp.TuneSolution(solName)
+	tuneSolution := tuneApp.TuneSolution
+	if system.IsFlagSet("atomic") {
+		// transactional apply with rollback
+		tuneSolution = tuneApp.TuneSolutionAtomic
+	}
+	removedAdditionalNotes, err := tuneSolution(solName)
 	if err != nil {
 		system.ErrorExit("Failed to tune for solution %s: %v", solName, err)
 	}
@@ -37,7 +37,7 @@ func PrintNoteFields(writer io.Writer, header string, noteComparisons map[string
 
 	var compliant string
 	var comment string
-	var footnote []string = make([]string, 16)
+	var footnote []string = make([]string, 18)
 
 	colorScheme := getColorScheme()
 	// sort output
@@ -588,3 +588,50 @@ func printVerifyRow(twist bool, writer io.Writer, rowElements map[string]string,
 		fmt.Fprintf(writer, rowElements["colFormat"], rowElements["note"], rowElements["parameter"], cols[0], cols[2], cols[1], rowElements["compliant"])
 	}
 }
+
+// valueOrDash returns the value to print in a list table, a dash for an
+// empty value
+func valueOrDash(val string) string {
+	if val == "" {
+		return "-"
+	}
+	return val
+}
+
+// printListTable prints the rows as simple table with the given header
+func printListTable(writer io.Writer, header []string, rows [][]string) {
+	colwidth := make([]int, len(header))
+	for col, head := range header {
+		colwidth[col] = len(head)
+	}
+	for _, row := range rows {
+		for col, val := range row {
+			if len(val) > colwidth[col] {
+				colwidth[col] = len(val)
+			}
+		}
+	}
+	format := ""
+	dashes := make([]string, 0, len(header))
+	for col, width := range colwidth {
+		if col == len(colwidth)-1 {
+			format = format + " %s\n"
+		} else {
+			format = format + " %-" + strconv.Itoa(width) + "s |"
+		}
+		dashes = append(dashes, strings.Repeat("-", width+2))
+	}
+	printRow := func(row []string) {
+		vals := make([]interface{}, 0, len(row))
+		for _, val := range row {
+			vals = append(vals, val)
+		}
+		fmt.Fprintf(writer, format, vals...)
+	}
+	printRow(header)
+	fmt.Fprintf(writer, "%s\n", strings.Join(dashes, "+"))
+	for _, row := range rows {
+		printRow(row)
+	}
+	fmt.Fprintf(writer, "\n")
+}
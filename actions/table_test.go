@@ -546,3 +546,33 @@ func TestColorPrint(t *testing.T) {
 		t.Errorf("got: %+v, expected: %+v\n", cCompl, compliant)
 	}
 }
+
+func TestSetHugepages(t *testing.T) {
+	footnote := make([]string, 17)
+	compliant, comment, footnote := setHugepages("nr_hugepages", "no ", "", "700 of 1024 pages allocated (node0:512 node1:188)", footnote)
+	if compliant != "no  [17]" || comment != " [17]" {
+		t.Errorf("got '%s', '%s'", compliant, comment)
+	}
+	if footnote[16] != "[17] not all requested hugepages are allocated, nr_hugepages: 700 of 1024 pages allocated (node0:512 node1:188). The free memory may be too fragmented, allocate the hugepages early during boot or reduce the requested number" {
+		t.Error(footnote[16])
+	}
+	compliant, _, _ = setHugepages("nr_hugepages", "yes", "", "", make([]string, 17))
+	if compliant != "yes" {
+		t.Error(compliant)
+	}
+}
+
+func TestSetMpathDiffs(t *testing.T) {
+	footnote := make([]string, 18)
+	compliant, comment, footnote := setMpathDiffs("IO_SCHEDULER_dm-0", "yes", "", note.MpathDiffsInfo+"sdc=mq-deadline", footnote)
+	if compliant != "yes [18]" || comment != " [18]" {
+		t.Errorf("got '%s', '%s'", compliant, comment)
+	}
+	if footnote[17] != "[18] paths of the multipath device differ: IO_SCHEDULER_dm-0: sdc=mq-deadline" {
+		t.Error(footnote[17])
+	}
+	compliant, _, _ = setMpathDiffs("IO_SCHEDULER_sda", "yes", "", "", make([]string, 18))
+	if compliant != "yes" {
+		t.Error(compliant)
+	}
+}
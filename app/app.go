@@ -174,6 +174,47 @@ func (app *App) RevertAll(permanent bool) error {
 	return fmt.Errorf("Failed to revert one or more SAP notes/solutions: %v", allErrs)
 }
 
+// configState holds a copy of the solutions and notes configuration to
+// restore it after a failed transactional apply
+type configState struct {
+	tuneForSolutions []string
+	tuneForNotes     []string
+	noteApplyOrder   []string
+}
+
+// saveConfigState returns a copy of the current solutions and notes
+// configuration
+func (app *App) saveConfigState() configState {
+	return configState{
+		tuneForSolutions: append([]string{}, app.TuneForSolutions...),
+		tuneForNotes:     append([]string{}, app.TuneForNotes...),
+		noteApplyOrder:   append([]string{}, app.NoteApplyOrder...),
+	}
+}
+
+// rollbackNotes reverts the notes tuned by a failed transactional apply in
+// reverse order using the saved states and restores the configuration
+// saved before the apply started
+func (app *App) rollbackNotes(noteIDs []string, cfg configState) error {
+	allErrs := make([]error, 0)
+	for i := len(noteIDs) - 1; i >= 0; i-- {
+		system.NoticeLog("Rolling back all parameters changed by note '%s'", noteIDs[i])
+		if err := app.RevertNote(noteIDs[i], true); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	app.TuneForSolutions = cfg.tuneForSolutions
+	app.TuneForNotes = cfg.tuneForNotes
+	app.NoteApplyOrder = cfg.noteApplyOrder
+	if err := app.SaveConfig(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Failed to roll back one or more SAP notes: %v", allErrs)
+}
+
 // VerifyAll inspect the system and verify all parameters against all enabled
 // notes/solutions.
 // The note comparison results will always contain all fields from all notes.
@@ -82,6 +82,35 @@ func (n2 SampleNote2) Apply() error {
 	return n2.Param.Apply("2")
 }
 
+// SampleNoteFail changes the parameter, but fails to apply the optimised
+// value afterwards, like a note, which is only partially applied
+type SampleNoteFail struct {
+	Param SampleParam
+}
+
+func (nf SampleNoteFail) Name() string {
+	return "sample note failing"
+}
+func (nf SampleNoteFail) Initialise() (note.Note, error) {
+	newParam, err := nf.Param.Inspect()
+	nf.Param = newParam.(SampleParam)
+	return nf, err
+}
+func (nf SampleNoteFail) Optimise() (note.Note, error) {
+	newParam, err := nf.Param.Optimise("F")
+	nf.Param = newParam.(SampleParam)
+	return nf, err
+}
+func (nf SampleNoteFail) Apply() error {
+	if err := nf.Param.Apply("F"); err != nil {
+		return err
+	}
+	if nf.Param.Data == "optimisedF" {
+		return fmt.Errorf("failed to apply parameter 'sample'")
+	}
+	return nil
+}
+
 var AllTestNotes = map[string]note.Note{"1001": SampleNote1{}, "1002": SampleNote2{}}
 var AllTestSolutions = map[string]solution.Solution{
 	"sol1":  {"1001"},
@@ -173,6 +173,27 @@ and then please double check your input`, id)
 // If the note is not yet covered by one of the enabled solutions,
 // the note number will be added into the list of additional notes.
 func (app *App) TuneNote(noteID string) error {
+	return app.tuneNote(noteID, false)
+}
+
+// TuneNoteAtomic apply tuning for a note like TuneNote, but transactional.
+// If one of the parameters could not be applied, all parameters already
+// changed by the note are rolled back using the saved states and the
+// configuration is restored.
+func (app *App) TuneNoteAtomic(noteID string) error {
+	cfg := app.saveConfigState()
+	if err := app.tuneNote(noteID, true); err != nil {
+		if rerr := app.rollbackNotes([]string{noteID}, cfg); rerr != nil {
+			return fmt.Errorf("%v\nRollback of the already changed parameters failed: %v", err, rerr)
+		}
+		return fmt.Errorf("%v\nAll parameters changed by the note have been rolled back, the system is back in its prior state", err)
+	}
+	return nil
+}
+
+// tuneNote apply tuning for a note. With 'atomic' the apply stops at the
+// first parameter, which could not be set
+func (app *App) tuneNote(noteID string, atomic bool) error {
 	savConf := false
 	aNote, err := app.GetNoteByID(noteID)
 	if err != nil {
@@ -224,6 +245,9 @@ func (app *App) TuneNote(noteID string) error {
 		return err
 	}
 	if len(valApplyList) != 0 {
+		if atomic {
+			valApplyList = append(valApplyList, "atomic")
+		}
 		optimised = optimised.(note.INISettings).SetValuesToApply(valApplyList)
 	}
 
@@ -272,7 +296,13 @@ func (app *App) RevertNote(noteID string, permanent bool) error {
 		//var noteRecovered note.Note = noteIface.(note.Note)
 		var noteRecovered = noteIface.(note.Note)
 		if reflect.TypeOf(noteRecovered).String() == "*note.INISettings" {
-			noteRecovered = noteRecovered.(*note.INISettings).SetValuesToApply([]string{"revert"})
+			revertVals := []string{"revert"}
+			if permanent {
+				// settings surviving a reboot (e.g. boot options)
+				// are only reverted during a permanent revert
+				revertVals = append(revertVals, "permanent")
+			}
+			noteRecovered = noteRecovered.(*note.INISettings).SetValuesToApply(revertVals)
 		}
 
 		if err := noteRecovered.Apply(); err != nil {
@@ -398,6 +428,120 @@ func (app *App) RefreshNote(noteID string) error {
 	return err
 }
 
+// TuneBlockDevice applies the [block] settings of all applied notes to a
+// block device, which was not available when the notes were applied
+// (e.g. a hot-plugged device)
+func (app *App) TuneBlockDevice(bdev string) error {
+	// refresh the block device information
+	validDev := false
+	for _, dev := range system.CollectBlockDeviceInfo() {
+		if dev == bdev {
+			validDev = true
+			break
+		}
+	}
+	if !validDev {
+		if mpathDev := system.GetMpathOfPath(bdev); mpathDev != "" {
+			// path added to an already tuned multipath device
+			if attrs := note.ApplyMpathPaths(mpathDev); len(attrs) != 0 {
+				system.NoticeLog("block device settings of multipath device '%s' applied to its path '%s'.", mpathDev, bdev)
+			}
+			return nil
+		}
+		system.NoticeLog("block device '%s' not available or not a valid device for block device tuning, nothing to do.", bdev)
+		return nil
+	}
+	for _, noteID := range app.NoteApplyOrder {
+		if _, ok := app.IsNoteApplied(noteID); !ok {
+			// enabled, but not (yet) applied
+			continue
+		}
+		aNote, err := app.GetNoteByID(noteID)
+		if err != nil {
+			return err
+		}
+		if reflect.TypeOf(aNote).String() != "note.INISettings" {
+			continue
+		}
+		keys, err := aNote.(note.INISettings).AddBlockDevice(bdev)
+		if err != nil {
+			system.ErrorLog("Failed to add block device '%s' to note %s - %v", bdev, noteID, err)
+			return err
+		}
+		if len(keys) == 0 {
+			system.DebugLog("note '%s' contains no block device settings for device '%s'", noteID, bdev)
+			continue
+		}
+		if err := app.tuneNoteParams(noteID, keys); err != nil {
+			return err
+		}
+		system.NoticeLog("block device settings of note '%s' applied to device '%s'.", noteID, bdev)
+	}
+	if len(system.GetMpathPaths(bdev)) != 0 {
+		// the paths of an already tuned multipath device changed
+		// (e.g. a path was added)
+		_ = note.ApplyMpathPaths(bdev)
+	}
+	return nil
+}
+
+// tuneNoteParams applies the parameters 'keys' of an already applied note
+// and adds their start values to the saved state of the note
+func (app *App) tuneNoteParams(noteID string, keys []string) error {
+	aNote := app.AllNotes[noteID]
+	_, _, valApplyList, err := app.VerifyNote(noteID)
+	if err != nil {
+		return err
+	}
+	currentState, err := aNote.Initialise()
+	if err != nil {
+		system.ErrorLog("Failed to examine system for the current status of note %s - %v", noteID, err)
+		return err
+	}
+	// add the start values of the new parameters to the saved state
+	savedState := note.INISettings{}
+	overwrite := false
+	if err := app.State.Retrieve(noteID, &savedState); err == nil {
+		if savedState.SysctlParams == nil {
+			savedState.SysctlParams = make(map[string]string)
+		}
+		for _, key := range keys {
+			savedState.SysctlParams[key] = currentState.(note.INISettings).SysctlParams[key]
+		}
+		overwrite = true
+	} else {
+		savedState = currentState.(note.INISettings)
+	}
+	if err = app.State.Store(noteID, savedState, overwrite); err != nil {
+		system.ErrorLog("Failed to save current state of note %s - %v", noteID, err)
+		return err
+	}
+
+	optimised, err := currentState.Optimise()
+	if err != nil {
+		system.ErrorLog("Failed to calculate optimised parameters for note %s - %v", noteID, err)
+		return err
+	}
+	// apply only the parameters of the new block device, which do not
+	// comply with the note
+	applyList := []string{}
+	for _, key := range keys {
+		for _, val := range valApplyList {
+			if val == key {
+				applyList = append(applyList, key)
+			}
+		}
+	}
+	if len(applyList) == 0 {
+		return nil
+	}
+	if err := optimised.(note.INISettings).SetValuesToApply(applyList).Apply(); err != nil {
+		system.ErrorLog("Failed to apply note %s - %v", noteID, err)
+		return err
+	}
+	return nil
+}
+
 // check, if note is already applied
 // if not call 'note apply' and exit
 func (app *App) checkNoteAppliedState(noteID string) (bool, error) {
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"github.com/SUSE/saptune/sap/note"
 	"github.com/SUSE/saptune/system"
 	"os"
 	"path"
@@ -266,6 +267,58 @@ func TestIsNoteApplied(t *testing.T) {
 	}
 }
 
+func TestTuneBlockDevice(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), AllTestNotes, AllTestSolutions)
+	// unknown block device
+	if err := tuneApp.TuneBlockDevice("sdzz"); err != nil {
+		t.Error(err)
+	}
+	// applied note without block device settings
+	if err := tuneApp.TuneNote("1001"); err != nil {
+		t.Fatal(err)
+	}
+	for _, bdev := range system.CollectBlockDeviceInfo() {
+		if err := tuneApp.TuneBlockDevice(bdev); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := tuneApp.RevertNote("1001", true); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTuneNoteAtomic(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "1009": SampleNoteFail{}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, AllTestSolutions)
+	if err := tuneApp.TuneNoteAtomic("1001"); err != nil {
+		t.Fatal(err)
+	}
+	VerifyConfig(t, tuneApp, []string{"1001"}, []string{})
+	VerifyFileContent(t, SampleParamFile, "optimised1", "1")
+
+	// failing note is rolled back
+	err := tuneApp.TuneNoteAtomic("1009")
+	if err == nil {
+		t.Fatal("expected an error, but got none")
+	}
+	if !strings.Contains(err.Error(), "failed to apply parameter 'sample'") || !strings.Contains(err.Error(), "the system is back in its prior state") {
+		t.Error(err)
+	}
+	VerifyConfig(t, tuneApp, []string{"1001"}, []string{})
+	VerifyFileContent(t, SampleParamFile, "optimised1", "2")
+	if _, ok := tuneApp.IsNoteApplied("1009"); ok {
+		t.Error("note '1009' should not be applied")
+	}
+	if err := tuneApp.RevertNote("1001", true); err != nil {
+		t.Error(err)
+	}
+	VerifyFileContent(t, SampleParamFile, "", "3")
+}
+
 func TestGetSortedAllNotes(t *testing.T) {
 	os.RemoveAll(SampleNoteDataDir)
 	defer os.RemoveAll(SampleNoteDataDir)
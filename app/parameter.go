@@ -70,11 +70,11 @@ func collectChangedParameterInfo(noteID, fileName string, comparisons map[string
 // supported for refresh
 func isSectionSupportedForRefresh(param, section string) bool {
 	switch section {
-	case note.INISectionVersion, note.INISectionRpm, note.INISectionGrub, note.INISectionFS, note.INISectionReminder:
+	case note.INISectionVersion, note.INISectionRpm, note.INISectionReminder:
 		// These parameters are only checked, but not applied.
 		// So nothing to do during refresh
 		return false
-	case note.INISectionCPU, note.INISectionMEM, note.INISectionService, note.INISectionBlock, note.INISectionLimits, note.INISectionLogin, note.INISectionPagecache:
+	case note.INISectionCPU, note.INISectionMEM, note.INISectionService, note.INISectionBlock, note.INISectionLimits, note.INISectionLogin, note.INISectionPagecache, note.INISectionGrub, note.INISectionFS, note.INISectionKmod, note.INISectionIRQ, note.INISectionHugepages, note.INISectionNet, note.INISectionCgroup:
 		// currently not supported for 'refresh'
 		system.InfoLog("parameters (%s) from section '%s' currently not supported and not evaluated for 'refresh' operation", param, section)
 		return false
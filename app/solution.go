@@ -74,7 +74,37 @@ and then please double check your input`, name)
 // of tuned solution names.
 // If the solution covers any of the additional notes, those notes will be removed.
 func (app *App) TuneSolution(solName string) (removedExplicitNotes []string, err error) {
+	removedExplicitNotes, _, err = app.tuneSolution(solName, false)
+	return
+}
+
+// TuneSolutionAtomic apply tuning for a solution like TuneSolution, but
+// transactional. If one of the parameters could not be applied, all notes
+// already applied for the solution are rolled back using the saved states
+// and the configuration is restored.
+func (app *App) TuneSolutionAtomic(solName string) (removedExplicitNotes []string, err error) {
+	cfg := app.saveConfigState()
+	removedExplicitNotes, tunedNotes, err := app.tuneSolution(solName, true)
+	if err != nil {
+		// remove the stored note list of the solution
+		_, _ = solution.GetActiveSolNoteInfo(solName, true)
+		if rerr := app.rollbackNotes(tunedNotes, cfg); rerr != nil {
+			err = fmt.Errorf("%v\nRollback of the already changed parameters failed: %v", err, rerr)
+		} else {
+			err = fmt.Errorf("%v\nAll parameters changed by the notes of the solution have been rolled back, the system is back in its prior state", err)
+		}
+		removedExplicitNotes = []string{}
+	}
+	return
+}
+
+// tuneSolution apply tuning for a solution and returns additionally the
+// notes tuned for the solution, even if tuning of the last one failed.
+// With 'atomic' the apply of a note stops at the first parameter, which
+// could not be set
+func (app *App) tuneSolution(solName string, atomic bool) (removedExplicitNotes, tunedNotes []string, err error) {
 	removedExplicitNotes = make([]string, 0)
+	tunedNotes = make([]string, 0)
 	sol, err := app.GetSolutionByName(solName)
 	if err != nil {
 		return
@@ -102,7 +132,8 @@ func (app *App) TuneSolution(solName string) (removedExplicitNotes []string, err
 		if _, ok := app.IsNoteApplied(noteID); ok {
 			continue
 		}
-		if err = app.TuneNote(noteID); err != nil {
+		tunedNotes = append(tunedNotes, noteID)
+		if err = app.tuneNote(noteID, atomic); err != nil {
 			return
 		}
 	}
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"github.com/SUSE/saptune/sap/note"
+	"github.com/SUSE/saptune/sap/solution"
 	"github.com/SUSE/saptune/system"
 	"os"
 	"path"
@@ -116,6 +118,57 @@ func TestOptimiseSolutionOnly(t *testing.T) {
 	VerifyConfig(t, tuneApp, []string{}, []string{})
 }
 
+func TestTuneSolutionAtomic(t *testing.T) {
+	os.RemoveAll(SampleNoteDataDir)
+	defer os.RemoveAll(SampleNoteDataDir)
+	allNotes := map[string]note.Note{"1001": SampleNote1{}, "1009": SampleNoteFail{}}
+	allSolutions := map[string]solution.Solution{"sol1": {"1001"}, "sol19": {"1001", "1009"}}
+	tuneApp := InitialiseApp(path.Join(SampleNoteDataDir, "conf"), path.Join(SampleNoteDataDir, "data"), allNotes, allSolutions)
+	if _, err := tuneApp.TuneSolutionAtomic("sol1"); err != nil {
+		t.Fatal(err)
+	}
+	VerifyConfig(t, tuneApp, []string{}, []string{"sol1"})
+	VerifyFileContent(t, SampleParamFile, "optimised1", "1")
+	if err := tuneApp.RevertSolution("sol1"); err != nil {
+		t.Fatal(err)
+	}
+	VerifyFileContent(t, SampleParamFile, "", "2")
+
+	// failing solution is rolled back, the explicitly enabled note
+	// stays enabled
+	if err := tuneApp.TuneNote("1001"); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := tuneApp.TuneSolutionAtomic("sol19")
+	if err == nil {
+		t.Fatal("expected an error, but got none")
+	}
+	if len(removed) != 0 {
+		t.Errorf("expected no removed notes, but got '%+v'", removed)
+	}
+	VerifyConfig(t, tuneApp, []string{"1001"}, []string{})
+	VerifyFileContent(t, SampleParamFile, "optimised1", "3")
+	if _, ok := tuneApp.IsNoteApplied("1009"); ok {
+		t.Error("note '1009' should not be applied")
+	}
+	if _, err := solution.GetActiveSolNoteInfo("sol19", false); err == nil {
+		t.Error("note list of solution 'sol19' should be removed")
+	}
+
+	// no note of the solution applied before
+	if err := tuneApp.RevertNote("1001", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tuneApp.TuneSolutionAtomic("sol19"); err == nil {
+		t.Fatal("expected an error, but got none")
+	}
+	VerifyConfig(t, tuneApp, []string{}, []string{})
+	VerifyFileContent(t, SampleParamFile, "", "4")
+	if _, ok := tuneApp.IsNoteApplied("1001"); ok {
+		t.Error("note '1001' should not be applied")
+	}
+}
+
 func TestOverlappingSolutions(t *testing.T) {
 	os.RemoveAll(SampleNoteDataDir)
 	defer os.RemoveAll(SampleNoteDataDir)
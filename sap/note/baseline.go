@@ -0,0 +1,173 @@
+package note
+
+import (
+	"encoding/json"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// persistent baseline of the parameter values
+// If 'PERSIST_BASELINE' is set to 'yes' in the saptune configuration file,
+// the first-ever start value of each parameter (the 'start' entry of the
+// parameter state file) is stored in a directory surviving a reboot.
+
+// BaselineNoteID is the pseudo note ID used to restore the baseline values
+// by the revert workflow of the notes
+const BaselineNoteID = "baseline"
+
+// ParameterBaseline stores the first-ever start value of a parameter and
+// the section of the parameter needed to restore the value
+type ParameterBaseline struct {
+	Section string
+	Value   string
+}
+
+var baselineDir = system.SaptuneBaselineDir
+
+// getPathToBaseline returns path to the baseline file of the parameter
+func getPathToBaseline(param string) string {
+	// parameter names may contain mount points
+	return path.Join(baselineDir, strings.ReplaceAll(param, "/", "%2F"))
+}
+
+// persistBaseline returns true, if 'PERSIST_BASELINE' is set to 'yes' in
+// the saptune configuration file
+func persistBaseline() bool {
+	sconf, err := txtparser.ParseSysconfigFile(system.SaptuneConfigFile(), false)
+	if err != nil {
+		return false
+	}
+	return sconf.GetString("PERSIST_BASELINE", "no") == "yes"
+}
+
+// storeBaselineValue stores the start value of the parameter state file as
+// baseline value of the parameter, if no baseline value exists
+func storeBaselineValue(section, param string) {
+	if _, err := os.Stat(getPathToBaseline(param)); err == nil {
+		// first-ever start value already available
+		return
+	}
+	if !persistBaseline() {
+		return
+	}
+	pEntries := GetSavedParameterNotes(param)
+	if len(pEntries.AllNotes) == 0 || pEntries.AllNotes[0].NoteID != "start" {
+		return
+	}
+	bval := ParameterBaseline{
+		Section: section,
+		Value:   pEntries.AllNotes[0].Value,
+	}
+	content, err := json.Marshal(bval)
+	if err == nil {
+		err = os.MkdirAll(baselineDir, 0755)
+	}
+	if err == nil {
+		system.DebugLog("Write baseline value '%s' of parameter '%s' to file '%s'", bval.Value, param, getPathToBaseline(param))
+		err = os.WriteFile(getPathToBaseline(param), content, 0644)
+	}
+	if err != nil {
+		system.WarningLog("Failed to store baseline value for parameter '%s' - %v", param, err)
+	}
+}
+
+// GetAllBaselineValues reads all stored baseline values
+func GetAllBaselineValues() map[string]ParameterBaseline {
+	bvals := make(map[string]ParameterBaseline)
+	dirContent, err := os.ReadDir(baselineDir)
+	if err != nil {
+		return bvals
+	}
+	for _, entry := range dirContent {
+		param := strings.ReplaceAll(entry.Name(), "%2F", "/")
+		content, err := os.ReadFile(getPathToBaseline(param))
+		if err != nil {
+			continue
+		}
+		bval := ParameterBaseline{}
+		if err := json.Unmarshal(content, &bval); err != nil {
+			system.WarningLog("wrong content in baseline file '%s' - %v", getPathToBaseline(param), err)
+			continue
+		}
+		bvals[param] = bval
+	}
+	return bvals
+}
+
+// RestoreBaseline sets all parameters with a baseline value back to this
+// value. The parameters need to be reverted by all notes before.
+// The parameter state files and the section information of the pseudo
+// note 'baseline' are prepared, so that the revert workflow of the notes
+// restores the baseline values
+func RestoreBaseline(permanent bool) error {
+	bvals := GetAllBaselineValues()
+	if len(bvals) == 0 {
+		system.NoticeLog("no baseline values available, nothing to restore.")
+		return nil
+	}
+	params := make([]string, 0, len(bvals))
+	for param := range bvals {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	ini := &txtparser.INIFile{
+		AllValues: make([]txtparser.INIEntry, 0, len(bvals)),
+		KeyValue:  make(map[string]map[string]txtparser.INIEntry),
+	}
+	vend := INISettings{
+		ID:             BaselineNoteID,
+		SysctlParams:   make(map[string]string),
+		OverrideParams: make(map[string]string),
+		Inform:         make(map[string]string),
+	}
+	for _, param := range params {
+		bval := bvals[param]
+		if param == "fl_states" {
+			// restored together with 'force_latency'
+			continue
+		}
+		if !IsLastNoteOfParameter(param) {
+			system.WarningLog("parameter '%s' is still tuned by an applied note, baseline value not restored.", param)
+			continue
+		}
+		addBaselineParamChain(param, bval.Value)
+		if param == "force_latency" {
+			addBaselineParamChain("fl_states", bvals["fl_states"].Value)
+		}
+		entry := txtparser.INIEntry{Section: bval.Section, Key: param, Value: bval.Value}
+		ini.AllValues = append(ini.AllValues, entry)
+		if ini.KeyValue[bval.Section] == nil {
+			ini.KeyValue[bval.Section] = make(map[string]txtparser.INIEntry)
+		}
+		ini.KeyValue[bval.Section][param] = entry
+		vend.SysctlParams[param] = bval.Value
+	}
+	if len(ini.AllValues) == 0 {
+		return nil
+	}
+	if err := txtparser.StoreSectionInfo(ini, "section", BaselineNoteID, true); err != nil {
+		return err
+	}
+	revertVals := []string{"revert"}
+	if permanent {
+		revertVals = append(revertVals, "permanent")
+	}
+	return vend.SetValuesToApply(revertVals).Apply()
+}
+
+// addBaselineParamChain creates the parameter state file of a parameter
+// with the baseline value as start value followed by the pseudo note
+// 'baseline', so that a revert of the pseudo note sets the baseline value
+func addBaselineParamChain(param, value string) {
+	pEntries := ParameterNotes{
+		AllNotes: []ParameterNoteEntry{{NoteID: "start", Value: value}, {NoteID: BaselineNoteID, Value: ""}},
+	}
+	if err := pEntries.StoreParameter(param, true); err != nil {
+		system.WarningLog("Failed to store baseline value for parameter file '%s' for parameter '%s'", GetPathToParameter(param), param)
+	}
+}
@@ -0,0 +1,114 @@
+package note
+
+import (
+	"encoding/json"
+	"github.com/SUSE/saptune/system"
+	"os"
+	"testing"
+)
+
+// writeBaselineFile writes a baseline file for testing
+func writeBaselineFile(t *testing.T, param, section, value string) {
+	t.Helper()
+	content, err := json.Marshal(ParameterBaseline{Section: section, Value: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(baselineDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(getPathToBaseline(param), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetPathToBaseline(t *testing.T) {
+	val := getPathToBaseline("nfsopt_hard@/hana/shared")
+	if val != "/var/lib/saptune/baseline/nfsopt_hard@%2Fhana%2Fshared" {
+		t.Errorf("baseline file name: %v.\n", val)
+	}
+}
+
+func TestBaselineValues(t *testing.T) {
+	oldBaselineDir := baselineDir
+	defer func() { baselineDir = oldBaselineDir }()
+	baselineDir = "/tmp/saptune_baseline"
+	defer os.RemoveAll(baselineDir)
+
+	if bvals := GetAllBaselineValues(); len(bvals) != 0 {
+		t.Errorf("expected no baseline values, but got '%+v'", bvals)
+	}
+
+	// an existing baseline value is never overwritten
+	param := "TEST_BASELINE_PARAM"
+	writeBaselineFile(t, param, "sysctl", "FirstValue")
+	CreateParameterStartValues(param, "SecondValue")
+	defer CleanUpParamFile(param)
+	storeBaselineValue("sysctl", param)
+	writeBaselineFile(t, "nfsopt_hard@/hana/shared", "filesystem", "hard")
+
+	bvals := GetAllBaselineValues()
+	if len(bvals) != 2 {
+		t.Errorf("expected 2 baseline values, but got '%+v'", bvals)
+	}
+	if bvals[param].Value != "FirstValue" || bvals[param].Section != "sysctl" {
+		t.Errorf("wrong baseline value '%+v'", bvals[param])
+	}
+	if bvals["nfsopt_hard@/hana/shared"].Value != "hard" {
+		t.Errorf("wrong baseline value '%+v'", bvals["nfsopt_hard@/hana/shared"])
+	}
+
+	// wrong content is skipped
+	if err := os.WriteFile(getPathToBaseline("wrong"), []byte("no json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if bvals := GetAllBaselineValues(); len(bvals) != 2 {
+		t.Errorf("expected 2 baseline values, but got '%+v'", bvals)
+	}
+}
+
+func TestRestoreBaseline(t *testing.T) {
+	oldBaselineDir := baselineDir
+	defer func() { baselineDir = oldBaselineDir }()
+	baselineDir = "/tmp/saptune_baseline"
+	defer os.RemoveAll(baselineDir)
+
+	// nothing to restore
+	if err := RestoreBaseline(false); err != nil {
+		t.Error(err)
+	}
+
+	// use the current value to not change the system
+	param := "vm.swappiness"
+	val, err := system.GetSysctlString(param)
+	if err != nil {
+		t.Skipf("sysctl parameter '%s' not available", param)
+	}
+	writeBaselineFile(t, param, "sysctl", val)
+
+	// parameter still tuned by a note, baseline value not restored
+	tuned := "TEST_BASELINE_TUNED"
+	writeBaselineFile(t, tuned, "sysctl", "BaseValue")
+	CreateParameterStartValues(tuned, "StartValue")
+	AddParameterNoteValues(tuned, "TunedValue", "4711", "add")
+	defer CleanUpParamFile(tuned)
+
+	if err := RestoreBaseline(false); err != nil {
+		t.Error(err)
+	}
+	if !IsLastNoteOfParameter(param) {
+		t.Errorf("parameter file of '%s' still available after restore", param)
+	}
+	if IsLastNoteOfParameter(tuned) {
+		t.Errorf("parameter file of '%s' removed, but should be untouched", tuned)
+	}
+	if pEntries := GetSavedParameterNotes(tuned); len(pEntries.AllNotes) != 2 || pEntries.AllNotes[1].NoteID != "4711" {
+		t.Errorf("wrong parameter state '%+v' for '%s'", pEntries, tuned)
+	}
+	if newVal, _ := system.GetSysctlString(param); newVal != val {
+		t.Errorf("expected '%s', but got '%s'", val, newVal)
+	}
+	if _, err := os.Stat("/run/saptune/sections/baseline.sections"); !os.IsNotExist(err) {
+		t.Errorf("section file of the pseudo note '%s' still available", BaselineNoteID)
+	}
+}
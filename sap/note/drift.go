@@ -0,0 +1,149 @@
+package note
+
+import (
+	"github.com/SUSE/saptune/sap/param"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// drift detection
+// The parameter state files contain the values saptune has set during the
+// apply of the Notes. The last entry of a parameter state file is the value
+// saptune has written last. If the current system value differs from this
+// value, the parameter was changed outside of saptune after the apply.
+
+// ParameterDrift is a parameter, whose current system value differs from
+// the value saptune has set last
+type ParameterDrift struct {
+	Section  string
+	Param    string
+	NoteID   string
+	Expected string
+	Actual   string
+	Source   string
+}
+
+// driftServiceSections contains the sections, whose parameters can be
+// changed by other tuning services like tuned or sapconf
+var driftServiceSections = map[string]bool{
+	INISectionSysctl: true,
+	INISectionSys:    true,
+	INISectionVM:     true,
+	INISectionBlock:  true,
+	INISectionCPU:    true,
+}
+
+// DriftedParameters compares the current system values of the parameters
+// tuned by the applied Notes with the values saptune has set last, as stored
+// in the parameter state files.
+// 'services' contains the other active tuning services (e.g. tuned), which
+// are reported as likely source of the change.
+// Returns the drifted parameters sorted by section and parameter name
+func DriftedParameters(noteIDs []string, notes map[string]Note, services []string) []ParameterDrift {
+	drifts := []ParameterDrift{}
+	noteEntries := getAppliedNoteEntries(noteIDs, notes)
+	params, err := ListParams()
+	if err != nil {
+		system.WarningLog("failed to read the parameter state files - %v", err)
+		return drifts
+	}
+	// needed to find sysctl config files defining the same parameter
+	txtparser.CollectGlobalSysctls()
+	// the block device information collected during the apply is no
+	// longer available, read the current values from the system
+	param.RefreshBlockDeviceInfo()
+	blckDevs := resetToFactoryBlockDevices()
+	for _, key := range params {
+		if key == "fl_states" {
+			// checked together with 'force_latency'
+			continue
+		}
+		pEntries := GetSavedParameterNotes(key)
+		if len(pEntries.AllNotes) < 2 {
+			// only start value available, nothing set by saptune
+			continue
+		}
+		last := pEntries.AllNotes[len(pEntries.AllNotes)-1]
+		entry, ok := noteEntries[last.NoteID][key]
+		if !ok {
+			system.DebugLog("DriftedParameters - parameter '%s' of Note '%s' not found in the applied Notes, skipping", key, last.NoteID)
+			continue
+		}
+		if entry.Section == INISectionGrub || last.Value == "" || last.Value == "NA" || last.Value == "PNA" {
+			// boot options are only active after a reboot and
+			// untouched or not supported parameters are
+			// never set by saptune
+			continue
+		}
+		confFile := ""
+		if vend, ok := notes[last.NoteID].(INISettings); ok {
+			confFile = vend.ConfFilePath
+		}
+		actual, _, ok := getSnapshotValue(entry, &blckDevs, confFile)
+		if !ok || actual == "NA" || actual == "PNA" {
+			continue
+		}
+		if cmpMapValue("SysctlParams", reflect.ValueOf(key), actual, last.Value).MatchExpectation {
+			continue
+		}
+		drifts = append(drifts, ParameterDrift{
+			Section:  entry.Section,
+			Param:    key,
+			NoteID:   last.NoteID,
+			Expected: last.Value,
+			Actual:   actual,
+			Source:   driftSource(entry.Section, key, services),
+		})
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Section != drifts[j].Section {
+			return drifts[i].Section < drifts[j].Section
+		}
+		return drifts[i].Param < drifts[j].Param
+	})
+	return drifts
+}
+
+// getAppliedNoteEntries returns the parameter entries of the applied Notes
+// per Note ID. The stored section information of the apply is used, if
+// available, otherwise the Note definition file
+func getAppliedNoteEntries(noteIDs []string, notes map[string]Note) map[string]map[string]txtparser.INIEntry {
+	noteEntries := make(map[string]map[string]txtparser.INIEntry)
+	for _, noteID := range noteIDs {
+		ini, err := txtparser.GetSectionInfo("rosi", noteID, false)
+		if err != nil || len(ini.AllValues) == 0 {
+			vend, ok := notes[noteID].(INISettings)
+			if !ok {
+				continue
+			}
+			if ini, err = txtparser.ParseINIFile(vend.ConfFilePath, false); err != nil {
+				system.WarningLog("Failed to read Note definition file '%s' - %v", vend.ConfFilePath, err)
+				continue
+			}
+			ini = txtparser.ExpandGlobKeys(ini)
+		}
+		noteEntries[noteID] = make(map[string]txtparser.INIEntry)
+		for _, entry := range ini.AllValues {
+			noteEntries[noteID][entry.Key] = entry
+		}
+	}
+	return noteEntries
+}
+
+// driftSource returns the likely source of the change of a parameter
+// sysctl config files defining the parameter and active tuning services
+func driftSource(section, key string, services []string) string {
+	sources := []string{}
+	if section == INISectionSysctl {
+		if info := system.ChkForSysctlDoubles(key); info != "" {
+			sources = append(sources, info)
+		}
+	}
+	if driftServiceSections[section] {
+		sources = append(sources, services...)
+	}
+	return strings.Join(sources, ", ")
+}
@@ -0,0 +1,118 @@
+package note
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestDriftSource(t *testing.T) {
+	services := []string{"tuned.service (profile 'throughput-performance')", "sapconf.service"}
+	if src := driftSource(INISectionBlock, "NRREQ_sda", services); src != "tuned.service (profile 'throughput-performance'), sapconf.service" {
+		t.Errorf("wrong source '%s'", src)
+	}
+	if src := driftSource(INISectionLimits, "LIMIT_@sapsys_soft_nofile", services); src != "" {
+		t.Errorf("wrong source '%s'", src)
+	}
+	if src := driftSource(INISectionSysctl, "vm.not_defined_anywhere", []string{}); src != "" {
+		t.Errorf("wrong source '%s'", src)
+	}
+}
+
+func TestDriftedParameters(t *testing.T) {
+	cleanUp()
+	defer cleanUp()
+	oldHistoryFile := historyFile
+	defer func() { historyFile = oldHistoryFile }()
+	historyFile = "/tmp/saptune_history/history.jsonl"
+	defer os.RemoveAll("/tmp/saptune_history")
+
+	val, err := system.GetSysctlString("vm.swappiness")
+	if err != nil {
+		t.Skip("sysctl parameter 'vm.swappiness' not available")
+	}
+	newVal := "61"
+	if val == newVal {
+		newVal = "59"
+	}
+	setUp(t)
+	// cleanUp removed the collected block device information
+	_ = system.CollectBlockDeviceInfo()
+	raKey := "READ_AHEAD_KB_" + tstDisk
+	raVal, err := system.GetSysString(path.Join("block", tstDisk, "queue", "read_ahead_kb"))
+	if err != nil {
+		t.Skipf("block device '%s' not available", tstDisk)
+	}
+	newRaVal := "4096"
+	if raVal == newRaVal {
+		newRaVal = "2048"
+	}
+	noteFile := "/tmp/saptune_drift_note"
+	defer os.Remove(noteFile)
+	content := fmt.Sprintf("[version]\nVERSION=1\n\n[sysctl]\nvm.swappiness=%s\n\n[block:blkpat=^%s$]\nREAD_AHEAD_KB=%s\n", newVal, tstDisk, newRaVal)
+	if err := os.WriteFile(noteFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vend := INISettings{ConfFilePath: noteFile, ID: "drift"}
+	notes := map[string]Note{"drift": vend}
+	services := []string{"tuned.service (profile 'test')"}
+
+	// nothing applied
+	if drifts := DriftedParameters([]string{}, notes, services); len(drifts) != 0 {
+		t.Errorf("expected no drift, but got '%+v'", drifts)
+	}
+
+	initialised, err := vend.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimised, err := initialised.Optimise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimisedINI := optimised.(INISettings)
+	if err := optimisedINI.SetValuesToApply([]string{"vm.swappiness", raKey}).Apply(); err != nil {
+		t.Error(err)
+	}
+	defer func() { _ = optimisedINI.SetValuesToApply([]string{"revert"}).Apply() }()
+
+	if drifts := DriftedParameters([]string{"drift"}, notes, services); len(drifts) != 0 {
+		t.Errorf("expected no drift, but got '%+v'", drifts)
+	}
+	// changed outside of saptune
+	if err := system.SetSysctlString("vm.swappiness", val); err != nil {
+		t.Fatal(err)
+	}
+	drifts := DriftedParameters([]string{"drift"}, notes, services)
+	if len(drifts) != 1 {
+		t.Fatalf("expected 1 drifted parameter, but got '%+v'", drifts)
+	}
+	if drifts[0].Param != "vm.swappiness" || drifts[0].Section != "sysctl" || drifts[0].NoteID != "drift" || drifts[0].Expected != newVal || drifts[0].Actual != val {
+		t.Errorf("wrong drifted parameter '%+v'", drifts[0])
+	}
+	if drifts[0].Source != "tuned.service (profile 'test')" {
+		t.Errorf("wrong source '%s'", drifts[0].Source)
+	}
+	// block device values are read from the system, even if the block
+	// device information of the apply is no longer available
+	system.CleanUpRun()
+	if err := system.SetSysString(path.Join("block", tstDisk, "queue", "read_ahead_kb"), raVal); err != nil {
+		t.Fatal(err)
+	}
+	drifts = DriftedParameters([]string{"drift"}, notes, services)
+	if len(drifts) != 2 {
+		t.Fatalf("expected 2 drifted parameters, but got '%+v'", drifts)
+	}
+	if drifts[0].Param != raKey || drifts[0].Section != "block" || drifts[0].Expected != newRaVal || drifts[0].Actual != raVal {
+		t.Errorf("wrong drifted parameter '%+v'", drifts[0])
+	}
+	if drifts[1].Param != "vm.swappiness" {
+		t.Errorf("wrong drifted parameter '%+v'", drifts[1])
+	}
+	// parameters of Notes not applied are skipped
+	if drifts := DriftedParameters([]string{"other"}, notes, services); len(drifts) != 0 {
+		t.Errorf("expected no drift, but got '%+v'", drifts)
+	}
+}
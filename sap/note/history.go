@@ -0,0 +1,146 @@
+package note
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// change history of the parameters
+// Every parameter value changed during the apply or revert of a Note is
+// appended as a single JSON line to the history file. The file is never
+// rewritten, so it can be used as journal of all changes made by saptune.
+
+// HistoryEntry is a single parameter change
+type HistoryEntry struct {
+	Timestamp string `json:"timestamp"`
+	CmdLine   string `json:"command line"`
+	Action    string `json:"action"`
+	NoteID    string `json:"Note ID"`
+	Section   string `json:"section"`
+	Param     string `json:"parameter"`
+	OldValue  string `json:"old value"`
+	NewValue  string `json:"new value"`
+}
+
+// historyTimeFormat is the format of the timestamp of the history entries
+const historyTimeFormat = "2006-01-02 15:04:05"
+
+// supported formats of the time used to filter the history entries
+var historySinceFormats = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+var historyFile = system.SaptuneHistoryFile
+
+// newHistoryEntry returns a history entry for a parameter changed by the
+// Note, if the new value differs from the old one.
+// Returns false, if nothing was changed
+func newHistoryEntry(action, noteID, section, key, oldVal, newVal string) (HistoryEntry, bool) {
+	if newVal == "" || newVal == "NA" || newVal == "PNA" {
+		// untouched or not supported parameter, nothing changed
+		return HistoryEntry{}, false
+	}
+	if strings.Join(strings.Fields(oldVal), " ") == strings.Join(strings.Fields(newVal), " ") {
+		return HistoryEntry{}, false
+	}
+	return HistoryEntry{
+		Timestamp: time.Now().Format(historyTimeFormat),
+		CmdLine:   strings.Join(os.Args, " "),
+		Action:    action,
+		NoteID:    noteID,
+		Section:   section,
+		Param:     key,
+		OldValue:  oldVal,
+		NewValue:  newVal,
+	}, true
+}
+
+// writeHistory appends the entries to the history file
+func writeHistory(entries []HistoryEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	if err := os.MkdirAll(path.Dir(historyFile), 0755); err != nil {
+		system.WarningLog("failed to create directory for history file '%s' - %v", historyFile, err)
+		return
+	}
+	hfile, err := os.OpenFile(historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		system.WarningLog("failed to open history file '%s' - %v", historyFile, err)
+		return
+	}
+	defer hfile.Close()
+	for _, entry := range entries {
+		content, err := json.Marshal(entry)
+		if err != nil {
+			system.WarningLog("failed to write history entry '%+v' - %v", entry, err)
+			continue
+		}
+		if _, err := hfile.Write(append(content, '\n')); err != nil {
+			system.WarningLog("failed to write history file '%s' - %v", historyFile, err)
+			return
+		}
+	}
+}
+
+// ParseHistorySince returns the time, which is used to filter the history
+// entries. Supported formats are 'YYYY-MM-DD', 'YYYY-MM-DD hh:mm' and
+// 'YYYY-MM-DD hh:mm:ss'
+func ParseHistorySince(since string) (time.Time, error) {
+	for _, format := range historySinceFormats {
+		if stime, err := time.ParseInLocation(format, since, time.Local); err == nil {
+			return stime, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("wrong time '%s'. Supported formats are 'YYYY-MM-DD', 'YYYY-MM-DD hh:mm' and 'YYYY-MM-DD hh:mm:ss'", since)
+}
+
+// GetHistory returns the history entries in the order they were written.
+// The entries can be filtered by parameter name, Note ID and a point in
+// time ('since'). Empty filter values match all entries
+func GetHistory(key, noteID, since string) ([]HistoryEntry, error) {
+	entries := []HistoryEntry{}
+	stime := time.Time{}
+	if since != "" {
+		var err error
+		if stime, err = ParseHistorySince(since); err != nil {
+			return entries, err
+		}
+	}
+	hfile, err := os.Open(historyFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// no parameter changed till now
+			return entries, nil
+		}
+		return entries, err
+	}
+	defer hfile.Close()
+	scanner := bufio.NewScanner(hfile)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry := HistoryEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			system.DebugLog("skip wrong entry '%s' in history file '%s' - %v", scanner.Text(), historyFile, err)
+			continue
+		}
+		if key != "" && entry.Param != key {
+			continue
+		}
+		if noteID != "" && entry.NoteID != noteID {
+			continue
+		}
+		if since != "" {
+			etime, err := time.ParseInLocation(historyTimeFormat, entry.Timestamp, time.Local)
+			if err != nil || etime.Before(stime) {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
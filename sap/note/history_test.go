@@ -0,0 +1,161 @@
+package note
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestParseHistorySince(t *testing.T) {
+	for _, since := range []string{"2024-01-31", "2024-01-31 10:15", "2024-01-31 10:15:30", "2024-01-31T10:15:30"} {
+		stime, err := ParseHistorySince(since)
+		if err != nil {
+			t.Error(err)
+		}
+		if stime.Format("2006-01-02") != "2024-01-31" {
+			t.Errorf("wrong time '%v' for '%s'", stime, since)
+		}
+	}
+	if _, err := ParseHistorySince("31.01.2024"); err == nil {
+		t.Error("expected an error for a wrong time format")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	oldHistoryFile := historyFile
+	defer func() { historyFile = oldHistoryFile }()
+	historyFile = "/tmp/saptune_history/history.jsonl"
+	defer os.RemoveAll("/tmp/saptune_history")
+
+	if entries, err := GetHistory("", "", ""); err != nil || len(entries) != 0 {
+		t.Errorf("expected no history entries, but got '%+v' - %v", entries, err)
+	}
+	if _, ok := newHistoryEntry("apply", "4711", "sysctl", "vm.swappiness", "60", "60"); ok {
+		t.Error("unchanged value added to the history")
+	}
+	if _, ok := newHistoryEntry("apply", "4711", "sysctl", "vm.not_available", "", "PNA"); ok {
+		t.Error("not supported parameter added to the history")
+	}
+	entry1, ok := newHistoryEntry("apply", "4711", "sysctl", "vm.swappiness", "60", "10")
+	if !ok || entry1.OldValue != "60" || entry1.NewValue != "10" || entry1.CmdLine == "" {
+		t.Errorf("wrong history entry '%+v'", entry1)
+	}
+	entry2, _ := newHistoryEntry("apply", "0815", "block", "IO_SCHEDULER_sda", "mq-deadline", "none")
+	entry3, _ := newHistoryEntry("revert", "4711", "sysctl", "vm.swappiness", "10", "60")
+	entry3.Timestamp = "2000-01-01 00:00:00"
+	writeHistory([]HistoryEntry{entry1, entry2})
+	writeHistory([]HistoryEntry{entry3})
+	// wrong content is skipped
+	hfile, err := os.OpenFile(historyFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(hfile, "no json\n")
+	hfile.Close()
+
+	entries, err := GetHistory("", "", "")
+	if err != nil || len(entries) != 3 {
+		t.Errorf("expected 3 history entries, but got '%+v' - %v", entries, err)
+	}
+	entries, _ = GetHistory("vm.swappiness", "", "")
+	if len(entries) != 2 || entries[0].Action != "apply" || entries[1].Action != "revert" {
+		t.Errorf("wrong history entries '%+v'", entries)
+	}
+	entries, _ = GetHistory("", "0815", "")
+	if len(entries) != 1 || entries[0].Param != "IO_SCHEDULER_sda" {
+		t.Errorf("wrong history entries '%+v'", entries)
+	}
+	entries, _ = GetHistory("vm.swappiness", "4711", time.Now().Format("2006-01-02"))
+	if len(entries) != 1 || entries[0].NewValue != "10" {
+		t.Errorf("wrong history entries '%+v'", entries)
+	}
+	if _, err := GetHistory("", "", "yesterday"); err == nil {
+		t.Error("expected an error for a wrong time format")
+	}
+}
+
+func TestApplyHistory(t *testing.T) {
+	cleanUp()
+	defer cleanUp()
+	oldHistoryFile := historyFile
+	defer func() { historyFile = oldHistoryFile }()
+	historyFile = "/tmp/saptune_history/history.jsonl"
+	defer os.RemoveAll("/tmp/saptune_history")
+
+	val, err := system.GetSysctlString("vm.swappiness")
+	if err != nil {
+		t.Skip("sysctl parameter 'vm.swappiness' not available")
+	}
+	newVal := "61"
+	if val == newVal {
+		newVal = "59"
+	}
+	setUp(t)
+	// cleanUp removed the collected block device information
+	_ = system.CollectBlockDeviceInfo()
+	raKey := "READ_AHEAD_KB_" + tstDisk
+	raVal, err := system.GetSysString(path.Join("block", tstDisk, "queue", "read_ahead_kb"))
+	if err != nil {
+		t.Skipf("block device '%s' not available", tstDisk)
+	}
+	newRaVal := "4096"
+	if raVal == newRaVal {
+		newRaVal = "2048"
+	}
+	noteFile := "/tmp/saptune_history_note"
+	defer os.Remove(noteFile)
+	content := fmt.Sprintf("[version]\nVERSION=1\n\n[sysctl]\nvm.swappiness=%s\n\n[block:blkpat=^%s$]\nREAD_AHEAD_KB=%s\n", newVal, tstDisk, newRaVal)
+	if err := os.WriteFile(noteFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vend := INISettings{ConfFilePath: noteFile, ID: "history"}
+	initialised, err := vend.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimised, err := initialised.Optimise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimisedINI := optimised.(INISettings)
+	if err := optimisedINI.SetValuesToApply([]string{"vm.swappiness", raKey}).Apply(); err != nil {
+		t.Error(err)
+	}
+	if err := optimisedINI.SetValuesToApply([]string{"revert"}).Apply(); err != nil {
+		t.Error(err)
+	}
+	if cur, _ := system.GetSysctlString("vm.swappiness"); cur != val {
+		t.Errorf("expected '%s', but got '%s'", val, cur)
+	}
+
+	if cur, _ := system.GetSysString(path.Join("block", tstDisk, "queue", "read_ahead_kb")); cur != raVal {
+		t.Errorf("expected '%s', but got '%s'", raVal, cur)
+	}
+
+	entries, err := GetHistory("vm.swappiness", "history", "")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 history entries, but got '%+v' - %v", entries, err)
+	}
+	if entries[0].Action != "apply" || entries[0].Param != "vm.swappiness" || entries[0].OldValue != val || entries[0].NewValue != newVal {
+		t.Errorf("wrong history entry '%+v'", entries[0])
+	}
+	if entries[1].Action != "revert" || entries[1].OldValue != newVal || entries[1].NewValue != val {
+		t.Errorf("wrong history entry '%+v'", entries[1])
+	}
+
+	// the old value of a block device parameter is the value before the
+	// apply and not the new value prepared for the apply
+	entries, err = GetHistory(raKey, "history", "")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 history entries, but got '%+v' - %v", entries, err)
+	}
+	if entries[0].Action != "apply" || entries[0].Section != "block" || entries[0].OldValue != raVal || entries[0].NewValue != newRaVal {
+		t.Errorf("wrong history entry '%+v'", entries[0])
+	}
+	if entries[1].Action != "revert" || entries[1].OldValue != newRaVal || entries[1].NewValue != raVal {
+		t.Errorf("wrong history entry '%+v'", entries[1])
+	}
+}
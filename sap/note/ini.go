@@ -1,6 +1,7 @@
 package note
 
 import (
+	"fmt"
 	"github.com/SUSE/saptune/sap"
 	"github.com/SUSE/saptune/sap/param"
 	"github.com/SUSE/saptune/system"
@@ -27,6 +28,11 @@ const (
 	INISectionPagecache = "pagecache"
 	INISectionRpm       = "rpm"
 	INISectionGrub      = "grub"
+	INISectionKmod      = "kernelmodule"
+	INISectionIRQ       = "irq"
+	INISectionHugepages = "hugepages"
+	INISectionNet       = "net"
+	INISectionCgroup    = "cgroup"
 	INISectionReminder  = "reminder"
 
 	// LoginConfDir is the path to systemd's logind configuration directory under /etc.
@@ -44,6 +50,10 @@ var isLimitSoft = regexp.MustCompile(`LIMIT_.*_soft_memlock`)
 var isLimitHard = regexp.MustCompile(`LIMIT_.*_hard_memlock`)
 var flstates = ""
 
+// hostFacts are the variables available in the expressions of the note
+// definition files, collected only once per saptune call
+var hostFacts map[string]uint64
+
 // Tuning options composed by a third party vendor.
 
 // INISettings defines tuning options composed by a third party vendor.
@@ -60,11 +70,29 @@ type INISettings struct {
 // Initialise a BlockDeviceQueue
 func resetToFactoryBlockDevices() param.BlockDeviceQueue {
 	return param.BlockDeviceQueue{
-		BlockDeviceSchedulers:   param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)},
-		BlockDeviceNrRequests:   param.BlockDeviceNrRequests{NrRequests: make(map[string]int)},
-		BlockDeviceReadAheadKB:  param.BlockDeviceReadAheadKB{ReadAheadKB: make(map[string]int)},
-		BlockDeviceMaxSectorsKB: param.BlockDeviceMaxSectorsKB{MaxSectorsKB: make(map[string]int)},
+		BlockDeviceSchedulers:      param.BlockDeviceSchedulers{SchedulerChoice: make(map[string]string)},
+		BlockDeviceNrRequests:      param.BlockDeviceNrRequests{NrRequests: make(map[string]int)},
+		BlockDeviceReadAheadKB:     param.BlockDeviceReadAheadKB{ReadAheadKB: make(map[string]int)},
+		BlockDeviceMaxSectorsKB:    param.BlockDeviceMaxSectorsKB{MaxSectorsKB: make(map[string]int)},
+		BlockDeviceRqAffinity:      param.BlockDeviceRqAffinity{RqAffinity: make(map[string]int)},
+		BlockDeviceNomerges:        param.BlockDeviceNomerges{Nomerges: make(map[string]int)},
+		BlockDeviceWbtLatUsec:      param.BlockDeviceWbtLatUsec{WbtLatUsec: make(map[string]int)},
+		BlockDeviceAddRandom:       param.BlockDeviceAddRandom{AddRandom: make(map[string]int)},
+		BlockDeviceIOSchedTunables: param.BlockDeviceIOSchedTunables{Tunables: make(map[string]map[string]string)},
+	}
+}
+
+// currentBlockDevices returns an empty block device queue, which retrieves
+// the current block device values from the system, if there are [block]
+// parameters. 'blck' already contains the new values after 'Optimise'
+func currentBlockDevices(params []txtparser.INIEntry) param.BlockDeviceQueue {
+	for _, entry := range params {
+		if entry.Section == INISectionBlock {
+			param.RefreshBlockDeviceInfo()
+			break
+		}
 	}
+	return resetToFactoryBlockDevices()
 }
 
 // Name returns the name of the related SAP Note or en empty string
@@ -84,6 +112,7 @@ func (vend INISettings) Initialise() (Note, error) {
 		if err != nil {
 			return vend, err
 		}
+		ini = txtparser.ExpandGlobKeys(ini)
 		// write section data to section runtime file
 		err = txtparser.StoreSectionInfo(ini, "run", vend.ID, true)
 		if err != nil {
@@ -116,7 +145,10 @@ func (vend INISettings) Initialise() (Note, error) {
 			vend.SysctlParams[param.Key], vend.Inform[param.Key] = GetVMVal(param.Key)
 		case INISectionFS:
 			vend.SysctlParams[param.Key], vend.Inform[param.Key] = GetFSVal(param.Key, param.Value)
-			continue
+			if param.Value == "" {
+				// untouched
+				continue
+			}
 		case INISectionBlock:
 			vend.SysctlParams[param.Key], vend.Inform[param.Key], _ = GetBlkVal(param.Key, &blck)
 		case INISectionLimits:
@@ -134,7 +166,36 @@ func (vend INISettings) Initialise() (Note, error) {
 			continue
 		case INISectionGrub:
 			vend.SysctlParams[param.Key] = GetGrubVal(param.Key)
-			continue
+		case INISectionKmod:
+			vend.SysctlParams[param.Key] = GetKernelModuleVal(param.Key)
+			if param.Value == "" {
+				// untouched
+				continue
+			}
+		case INISectionIRQ:
+			vend.SysctlParams[param.Key] = GetIRQVal(param.Key)
+			if param.Value == "" {
+				// untouched
+				continue
+			}
+		case INISectionHugepages:
+			vend.SysctlParams[param.Key] = GetHugepagesVal(param.Key)
+			if param.Value == "" {
+				// untouched
+				continue
+			}
+		case INISectionNet:
+			vend.SysctlParams[param.Key] = GetNetVal(param.Key)
+			if param.Value == "" {
+				// untouched
+				continue
+			}
+		case INISectionCgroup:
+			vend.SysctlParams[param.Key] = GetCgroupVal(param.Key)
+			if param.Value == "" {
+				// untouched
+				continue
+			}
 		case INISectionReminder:
 			vend.SysctlParams[param.Key] = param.Value
 			continue
@@ -154,7 +215,7 @@ func (vend INISettings) Initialise() (Note, error) {
 			continue
 		}
 		// create parameter saved state file, if NOT in 'verify'
-		vend.createParamSavedStates(param.Key, flstates)
+		vend.createParamSavedStates(param.Section, param.Key, flstates)
 	}
 	return vend, nil
 }
@@ -173,6 +234,7 @@ func (vend INISettings) Optimise() (Note, error) {
 		if err != nil {
 			return vend, err
 		}
+		ini = txtparser.ExpandGlobKeys(ini)
 		// write section data to section runtime file
 		err = txtparser.StoreSectionInfo(ini, "run", vend.ID, true)
 		if err != nil {
@@ -190,6 +252,15 @@ func (vend INISettings) Optimise() (Note, error) {
 		if next {
 			continue
 		}
+		// evaluate computed values like '${MemTotalBytes} * 0.8'
+		param.Value = evalParamValue(param.Section, param.Key, param.Value)
+		// evaluate the range, in-set and regex operators
+		if txtparser.IsComplianceOperator(param.Operator) {
+			param.Value, next = vend.compliantValue(param.Section, param.Key, param.Operator, param.Value)
+			if next {
+				continue
+			}
+		}
 
 		switch param.Section {
 		case INISectionSysctl:
@@ -205,7 +276,6 @@ func (vend INISettings) Optimise() (Note, error) {
 			vend.SysctlParams[param.Key] = OptVMVal(param.Key, param.Value)
 		case INISectionFS:
 			vend.SysctlParams[param.Key] = OptFSVal(param.Key, param.Value)
-			continue
 		case INISectionBlock:
 			vend.SysctlParams[param.Key], vend.Inform[param.Key] = OptBlkVal(param.Key, param.Value, &blck, blckOK)
 			vend.Inform[param.Key] = vend.chkDoubles(param.Key, vend.Inform[param.Key])
@@ -231,7 +301,16 @@ func (vend INISettings) Optimise() (Note, error) {
 			continue
 		case INISectionGrub:
 			vend.SysctlParams[param.Key] = OptGrubVal(param.Key, param.Value)
-			continue
+		case INISectionKmod:
+			vend.SysctlParams[param.Key] = OptKernelModuleVal(param.Key, param.Value)
+		case INISectionIRQ:
+			vend.SysctlParams[param.Key] = OptIRQVal(param.Key, param.Value)
+		case INISectionHugepages:
+			vend.SysctlParams[param.Key], vend.Inform[param.Key] = OptHugepagesVal(param.Key, vend.SysctlParams[param.Key], param.Value)
+		case INISectionNet:
+			vend.SysctlParams[param.Key] = OptNetVal(param.Key, param.Value)
+		case INISectionCgroup:
+			vend.SysctlParams[param.Key] = OptCgroupVal(param.Key, param.Value)
 		case INISectionReminder:
 			vend.SysctlParams[param.Key] = param.Value
 			continue
@@ -271,10 +350,17 @@ func (vend INISettings) Optimise() (Note, error) {
 
 // Apply sets the new parameter values in the system or
 // revert the system to the former parameter values
+// If 'atomic' is part of the values to apply, the apply stops at the first
+// parameter, which could not be set, and returns the related error
+// All changed parameters are appended to the change history
 func (vend INISettings) Apply() error {
 	var err error
 	errs := make([]error, 0)
 	revertValues := false
+	permanent := false
+	atomic := false
+	bootCfgChanged := false
+	unitCfgChanged := false
 	pvendID := vend.ID
 
 	if len(vend.ValuesToApply) == 0 {
@@ -284,6 +370,27 @@ func (vend INISettings) Apply() error {
 	if _, ok := vend.ValuesToApply["revert"]; ok {
 		revertValues = true
 	}
+	if _, ok := vend.ValuesToApply["permanent"]; ok {
+		permanent = true
+	}
+	if _, ok := vend.ValuesToApply["atomic"]; ok && !revertValues {
+		atomic = true
+	}
+	// transactional apply - stop at the first failure, the caller
+	// rolls back the already changed parameters using the saved states
+	atomicErr := func(format string, args ...interface{}) error {
+		if !atomic || len(errs) == 0 || errs[len(errs)-1] == nil {
+			return nil
+		}
+		return fmt.Errorf(format+" - %v", append(args, errs[len(errs)-1])...)
+	}
+	// collect the changed parameters for the change history
+	action := "apply"
+	if revertValues {
+		action = "revert"
+	}
+	history := []HistoryEntry{}
+	defer func() { writeHistory(history) }()
 
 	ini, err = txtparser.GetSectionInfo("sns", vend.ID, revertValues)
 	if err != nil {
@@ -292,6 +399,7 @@ func (vend INISettings) Apply() error {
 		if err != nil {
 			return err
 		}
+		ini = txtparser.ExpandGlobKeys(ini)
 	}
 	// for refresh to support deleted parameter
 	// edge case - last note in parameter file, so apply is needed
@@ -300,12 +408,14 @@ func (vend INISettings) Apply() error {
 	if err == nil {
 		ini.AllValues = append(ini.AllValues, del.AllValues...)
 	}
+	// current block device values for the change history
+	oldBlck := currentBlockDevices(ini.AllValues)
 
 	for _, param := range ini.AllValues {
 		// handle note 1805750
 		param.Key, param.Value = vend.handleID1805750(param.Key, param.Value)
 		switch param.Section {
-		case INISectionVersion, INISectionRpm, INISectionGrub, INISectionFS, INISectionReminder:
+		case INISectionVersion, INISectionRpm, INISectionReminder:
 			// These parameters are only checked, but not applied.
 			// So nothing to do during apply and no need for revert
 			continue
@@ -315,10 +425,18 @@ func (vend INISettings) Apply() error {
 			continue
 		}
 
+		if strings.HasPrefix(vend.SysctlParams[param.Key], txtparser.OperatorRegex) {
+			// no compliant value derivable from the regular
+			// expression, nothing applied, so nothing to revert
+			system.WarningLog("value of parameter '%s' does not match the regular expression '%s' and no compliant value can be derived. Please adjust the value manually", param.Key, strings.TrimPrefix(vend.SysctlParams[param.Key], txtparser.OperatorRegex))
+			continue
+		}
 		if revertValues && vend.SysctlParams[param.Key] != "PNA" {
 			// revert parameter value
 			pvendID, flstates = vend.setRevertParamValues(param.Key)
 		}
+		// current value for the change history
+		oldVal, _, record := getSnapshotValue(param, &oldBlck, vend.ConfFilePath)
 
 		switch param.Section {
 		case INISectionSysctl:
@@ -335,6 +453,8 @@ func (vend INISettings) Apply() error {
 			errs = append(errs, SetSysVal(param.Key, vend.SysctlParams[param.Key]))
 		case INISectionVM:
 			errs = append(errs, SetVMVal(param.Key, vend.SysctlParams[param.Key]))
+		case INISectionFS:
+			errs = append(errs, SetFSVal(param.Key, vend.SysctlParams[param.Key], revertValues, permanent))
 		case INISectionBlock:
 			errs = append(errs, SetBlkVal(param.Key, vend.SysctlParams[param.Key], &blck, revertValues))
 		case INISectionLimits:
@@ -347,6 +467,28 @@ func (vend INISettings) Apply() error {
 			errs = append(errs, SetMemVal(param.Key, vend.SysctlParams[param.Key]))
 		case INISectionCPU:
 			errs = append(errs, SetCPUVal(param.Key, vend.SysctlParams[param.Key], vend.ID, flstates, vend.OverrideParams[param.Key], revertValues))
+		case INISectionGrub:
+			changed := false
+			if revertValues {
+				changed, err = RevertGrubVal(param.Key, vend.SysctlParams[param.Key], permanent)
+			} else {
+				changed, err = SetGrubVal(param.Key, vend.SysctlParams[param.Key])
+			}
+			bootCfgChanged = bootCfgChanged || changed
+			errs = append(errs, err)
+		case INISectionKmod:
+			errs = append(errs, SetKernelModuleVal(param.Key, vend.SysctlParams[param.Key], revertValues))
+		case INISectionIRQ:
+			errs = append(errs, SetIRQVal(param.Key, vend.SysctlParams[param.Key]))
+		case INISectionHugepages:
+			errs = append(errs, SetHugepagesVal(param.Key, vend.SysctlParams[param.Key]))
+		case INISectionNet:
+			errs = append(errs, SetNetVal(param.Key, vend.SysctlParams[param.Key]))
+		case INISectionCgroup:
+			var changed bool
+			changed, err = SetCgroupVal(param.Key, vend.SysctlParams[param.Key], revertValues)
+			unitCfgChanged = unitCfgChanged || changed
+			errs = append(errs, err)
 		case INISectionPagecache:
 			if revertValues {
 				switch param.Key {
@@ -361,6 +503,28 @@ func (vend INISettings) Apply() error {
 			system.WarningLog("3rdPartyTuningOption %s: skip unknown section %s", vend.ConfFilePath, param.Section)
 			continue
 		}
+		if entry, ok := newHistoryEntry(action, vend.ID, param.Section, param.Key, oldVal, vend.SysctlParams[param.Key]); ok && record && errs[len(errs)-1] == nil {
+			history = append(history, entry)
+		}
+		if err := atomicErr("failed to apply parameter '%s' of section [%s]", param.Key, param.Section); err != nil {
+			return err
+		}
+	}
+	if bootCfgChanged {
+		// regenerate boot configuration only once for all changed
+		// boot options
+		errs = append(errs, system.UpdateBootConfig())
+		if err := atomicErr("failed to update the boot configuration"); err != nil {
+			return err
+		}
+	}
+	if unitCfgChanged {
+		// reload the systemd configuration only once for all
+		// changed unit drop-in files
+		errs = append(errs, system.SystemctlDaemonReload())
+		if err := atomicErr("failed to reload the systemd configuration"); err != nil {
+			return err
+		}
 	}
 	err = sap.PrintErrors(errs)
 	return err
@@ -375,6 +539,52 @@ func (vend INISettings) SetValuesToApply(values []string) Note {
 	return vend
 }
 
+// AddBlockDevice adds the [block] settings of the Note for a block device,
+// which was not available when the Note was applied (e.g. a hot-plugged
+// device), to the stored section information of the Note.
+// Returns the keys of the added parameters
+func (vend INISettings) AddBlockDevice(bdev string) ([]string, error) {
+	keys := []string{}
+	// the block device info is collected again while parsing the Note
+	newIni, err := txtparser.ParseINIFile(vend.ConfFilePath, false)
+	if err != nil {
+		return keys, err
+	}
+	// without stored section information the next Initialise will
+	// use the complete Note definition including the new block device
+	ini, err := txtparser.GetSectionInfo("sns", vend.ID, false)
+	stored := err == nil
+	for _, param := range newIni.AllValues {
+		if param.Section != INISectionBlock || !strings.HasSuffix(param.Key, "_"+bdev) {
+			continue
+		}
+		if _, ok := ini.KeyValue[INISectionBlock][param.Key]; ok {
+			// already handled
+			continue
+		}
+		keys = append(keys, param.Key)
+		if !stored {
+			continue
+		}
+		if ini.KeyValue[INISectionBlock] == nil {
+			ini.KeyValue[INISectionBlock] = make(map[string]txtparser.INIEntry)
+		}
+		ini.AllValues = append(ini.AllValues, param)
+		ini.KeyValue[INISectionBlock][param.Key] = param
+	}
+	if len(keys) == 0 || !stored {
+		return keys, nil
+	}
+	// refresh the section runtime file of an override file, as it
+	// does not contain the entries of the new block device
+	ovFile := path.Join(txtparser.OverrideTuningSheets, vend.ID)
+	if ow, oerr := txtparser.ParseINIFile(ovFile, false); oerr == nil {
+		ow = txtparser.ExpandGlobKeys(ow)
+		_ = txtparser.StoreSectionInfo(ow, "ovw", vend.ID, true)
+	}
+	return keys, txtparser.StoreSectionInfo(ini, "run", vend.ID, true)
+}
+
 // getCounterPart gets the counterpart parameters of the vm.dirty parameters
 func (vend INISettings) getCounterPart(key string, revert bool) (string, string) {
 	// for the vm.dirty parameters take the counterpart
@@ -424,7 +634,7 @@ func (vend INISettings) setRevertParamValues(key string) (string, string) {
 }
 
 // createParamSavedStates creates the parameter saved state file
-func (vend INISettings) createParamSavedStates(key, flstates string) {
+func (vend INISettings) createParamSavedStates(section, key, flstates string) {
 	// Do not write parameter values to the saved state file during
 	// a pure 'verify' action
 	// Do not check for 'empty' value as the start/system value can be
@@ -440,9 +650,22 @@ func (vend INISettings) createParamSavedStates(key, flstates string) {
 				system.WriteBackupValue(start, "/var/lib/saptune/working/.tmbackup")
 			}
 		}
+		if strings.HasPrefix(key, "grub:") {
+			// the start value of a boot option is the value from
+			// the boot loader configuration, not from the running
+			// kernel
+			start = GetGrubStartVal(key)
+		}
+		if system.IsFSOption.MatchString(key) {
+			// the start value of a mount option is the state
+			// of each mounted file system
+			start = GetFSStartVal(key)
+		}
 		CreateParameterStartValues(key, start)
+		storeBaselineValue(section, key)
 		if key == "force_latency" {
 			CreateParameterStartValues("fl_states", flstates)
+			storeBaselineValue(section, "fl_states")
 		}
 	}
 }
@@ -547,6 +770,87 @@ func (vend INISettings) useOverrides(key, scheds, val string) (bool, string, str
 	return nxt, scheds, val
 }
 
+// evalParamValue evaluates the expressions in the parameter values
+// (e.g. '${MemTotalBytes} * 0.8') and returns the computed value.
+// Multi value parameters are evaluated field by field, if the value can
+// not be evaluated as a whole. In section [sysctl] values with a binary
+// size unit (e.g. '4G') are converted to numbers too.
+// A wrong expression results in an empty value, so the parameter is left
+// untouched.
+func evalParamValue(section, key, value string) string {
+	if section == INISectionReminder || section == INISectionVersion || section == INISectionRpm {
+		return value
+	}
+	isComputed := func(val string) bool {
+		return txtparser.IsExpression(val) || (section == INISectionSysctl && txtparser.IsUnitSize.MatchString(val))
+	}
+	if !isComputed(value) {
+		return value
+	}
+	if hostFacts == nil {
+		hostFacts = system.HostFacts()
+	}
+	val, err := txtparser.EvalExpression(value, hostFacts)
+	if err != nil && len(strings.Fields(value)) > 1 {
+		// multi value parameter
+		fields := strings.Fields(value)
+		for i, field := range fields {
+			if !isComputed(field) {
+				continue
+			}
+			if fields[i], err = txtparser.EvalExpression(field, hostFacts); err != nil {
+				break
+			}
+		}
+		val = strings.Join(fields, " ")
+	}
+	if err != nil {
+		system.WarningLog("wrong expression '%s' for '%s' - %v. Skipping...", value, key, err)
+		return ""
+	}
+	system.InfoLog("value '%s' of parameter '%s' computed to '%s'", value, key, val)
+	return val
+}
+
+// compliantValue returns the value nearest to the current value of the
+// parameter, which complies with the range ('><'), in-set ('=|') or regex
+// ('=~') operator. Supported for the sections [sysctl], [sys], [block] and
+// [cpu].
+// If no compliant value can be derived from a regular expression, the
+// expected value is set to the regular expression prefixed with the
+// operator, so the parameter is reported as non-compliant, but not applied.
+// Returns true, if the further handling of the parameter should be skipped
+func (vend INISettings) compliantValue(section, key string, operator txtparser.Operator, value string) (string, bool) {
+	switch section {
+	case INISectionSysctl, INISectionSys, INISectionBlock, INISectionCPU:
+	default:
+		system.WarningLog("operator '%s' is not supported in section [%s]. Skipping parameter '%s'", operator, section, key)
+		return "", false
+	}
+	if value == "" || vend.SysctlParams[key] == "PNA" {
+		return value, false
+	}
+	current := vend.SysctlParams[key]
+	if section == INISectionCPU {
+		current = commonCPUVal(current)
+	}
+	val, ok, err := txtparser.CalculateCompliantValue(operator, current, value)
+	if err != nil {
+		system.WarningLog("wrong value '%s' for parameter '%s' - %v. Skipping...", value, key, err)
+		return "", false
+	}
+	if !ok {
+		vend.SysctlParams[key] = txtparser.OperatorRegex + value
+		return "", true
+	}
+	if section == INISectionBlock && operator == txtparser.OperatorInSet && system.IsSched.MatchString(key) && val != current {
+		// let the block section choose the first scheduler of the
+		// set, which is supported by the device
+		val = strings.Join(txtparser.SplitInSetValue(value), ", ")
+	}
+	return val, false
+}
+
 // chkDoubles checks for double defined parameters
 // till now for /sys parameter settings
 // like KSM, THP and /sys/block/*/queue
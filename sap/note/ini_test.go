@@ -3,6 +3,7 @@ package note
 import (
 	"fmt"
 	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
 	"os"
 	"path"
 	"runtime"
@@ -18,6 +19,8 @@ func cleanUp() {
 	var saptuneSectionDir = "/run/saptune/sections"
 	os.RemoveAll(saptuneSectionDir)
 	defer os.RemoveAll(saptuneSectionDir)
+	// grub backup file created by Initialise for the [grub] section
+	os.Remove(system.GrubBackupFile)
 }
 
 func TestVendorSettings(t *testing.T) {
@@ -89,6 +92,7 @@ func TestNoConfig(t *testing.T) {
 
 func TestAllSettings(t *testing.T) {
 	cleanUp()
+	defer cleanUp()
 	testString := []string{"vm.nr_hugepages", "THP", "KSM", "systemd:sysstat"}
 	if runtime.GOARCH == "ppc64le" {
 		testString = []string{"KSM", "systemd:sysstat"}
@@ -292,6 +296,7 @@ func TestAllSettings(t *testing.T) {
 
 func TestOverrideAllSettings(t *testing.T) {
 	cleanUp()
+	defer cleanUp()
 	testString := []string{"vm.nr_hugepages", "THP", "KSM", "systemd:sysstat"}
 	if runtime.GOARCH == "ppc64le" {
 		testString = []string{"KSM", "systemd:sysstat"}
@@ -462,3 +467,153 @@ func TestPageCacheSettings(t *testing.T) {
 	}
 	cleanUp()
 }
+
+func TestEvalParamValue(t *testing.T) {
+	oldFacts := hostFacts
+	defer func() { hostFacts = oldFacts }()
+	hostFacts = map[string]uint64{"MemTotalBytes": 1000, "CPUs": 8}
+
+	tests := []struct {
+		section, value, exp string
+	}{
+		{INISectionSysctl, "${MemTotalBytes} * 0.8", "800"},
+		{INISectionSysctl, "4G", "4294967296"},
+		{INISectionSysctl, "4096 87380 6291456", "4096 87380 6291456"},
+		{INISectionSysctl, "4096 ${CPUs}*2 4K", "4096 16 4096"},
+		{INISectionSysctl, "${Unknown} * 2", ""},
+		{INISectionSys, "4G", "4G"},
+		{INISectionSys, "min(${CPUs}, 4)", "4"},
+		{INISectionGrub, "1G", "1G"},
+		{INISectionReminder, "use ${CPUs}", "use ${CPUs}"},
+	}
+	for _, tst := range tests {
+		if val := evalParamValue(tst.section, "tstkey", tst.value); val != tst.exp {
+			t.Errorf("[%s] '%s': expected '%s', got '%s'", tst.section, tst.value, tst.exp, val)
+		}
+	}
+}
+
+func TestCompliantValue(t *testing.T) {
+	vend := INISettings{
+		SysctlParams: map[string]string{"vm.swappiness": "80", "vm.dirty_ratio": "20", "energy_perf_bias": "cpu0:6 cpu1:6", "sys:kernel.mm.ksm.run": "PNA"},
+	}
+	if val, next := vend.compliantValue(INISectionSysctl, "vm.swappiness", txtparser.OperatorRange, "10..60"); val != "60" || next {
+		t.Error(val, next)
+	}
+	if val, next := vend.compliantValue(INISectionSysctl, "vm.dirty_ratio", txtparser.OperatorInSet, "10|20"); val != "20" || next {
+		t.Error(val, next)
+	}
+	if val, next := vend.compliantValue(INISectionCPU, "energy_perf_bias", txtparser.OperatorRange, "0..4"); val != "4" || next {
+		t.Error(val, next)
+	}
+	if val, next := vend.compliantValue(INISectionSys, "sys:kernel.mm.ksm.run", txtparser.OperatorRange, "0..1"); val != "0..1" || next {
+		t.Error(val, next)
+	}
+	// unsupported section
+	if val, next := vend.compliantValue(INISectionVM, "THP", txtparser.OperatorInSet, "never|madvise"); val != "" || next {
+		t.Error(val, next)
+	}
+	// wrong value
+	if val, next := vend.compliantValue(INISectionSysctl, "vm.swappiness", txtparser.OperatorRange, "10"); val != "" || next {
+		t.Error(val, next)
+	}
+	// no compliant value derivable from a regular expression
+	if val, next := vend.compliantValue(INISectionSysctl, "vm.swappiness", txtparser.OperatorRegex, "^[1-6]0$"); val != "" || !next {
+		t.Error(val, next)
+	}
+	if vend.SysctlParams["vm.swappiness"] != "=~^[1-6]0$" {
+		t.Error(vend.SysctlParams["vm.swappiness"])
+	}
+}
+
+func TestAddBlockDevice(t *testing.T) {
+	cleanUp()
+	defer cleanUp()
+	setUp(t)
+	noteFile := "/tmp/saptune_hotplug_note"
+	defer os.Remove(noteFile)
+	if err := os.WriteFile(noteFile, []byte("[version]\nVERSION=1\n\n[vm]\nTHP=never\n\n[block]\nNRREQ=1024\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vend := INISettings{ConfFilePath: noteFile, ID: "hotplug"}
+	key := "NRREQ_" + tstDisk
+
+	// no stored section information
+	keys, err := vend.AddBlockDevice(tstDisk)
+	if err != nil || len(keys) != 1 || keys[0] != key {
+		t.Errorf("wrong keys '%+v' - '%v'", keys, err)
+	}
+	if _, err := txtparser.GetSectionInfo("sns", vend.ID, false); err == nil {
+		t.Error("section information should not be written")
+	}
+
+	// stored section information without the block device
+	ini := txtparser.ParseINI("[vm]\nTHP=never\n")
+	if err := txtparser.StoreSectionInfo(ini, "run", vend.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	keys, err = vend.AddBlockDevice(tstDisk)
+	if err != nil || len(keys) != 1 || keys[0] != key {
+		t.Errorf("wrong keys '%+v' - '%v'", keys, err)
+	}
+	ini, err = txtparser.GetSectionInfo("sns", vend.ID, false)
+	if err != nil {
+		t.Error(err)
+	}
+	if ini.KeyValue[INISectionBlock][key].Value != "1024" || len(ini.AllValues) != 2 {
+		t.Errorf("block device not added - '%+v'", ini)
+	}
+	// already available
+	keys, _ = vend.AddBlockDevice(tstDisk)
+	if len(keys) != 0 {
+		t.Errorf("wrong keys '%+v'", keys)
+	}
+	// unknown block device
+	keys, _ = vend.AddBlockDevice("sdzz")
+	if len(keys) != 0 {
+		t.Errorf("wrong keys '%+v'", keys)
+	}
+}
+
+func TestAtomicApply(t *testing.T) {
+	cleanUp()
+	defer cleanUp()
+	noteFile := "/tmp/saptune_atomic_note"
+	defer os.Remove(noteFile)
+	// use the current value of vm.dirty_ratio to not change the system
+	val, err := system.GetSysctlString("vm.dirty_ratio")
+	if err != nil {
+		t.Skip("sysctl parameter 'vm.dirty_ratio' not available")
+	}
+	content := fmt.Sprintf("[version]\nVERSION=1\n\n[sysctl]\nvm.dirty_ratio=%s\nvm.swappiness=invalid\n", val)
+	if err := os.WriteFile(noteFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vend := INISettings{ConfFilePath: noteFile, ID: "atomic"}
+	initialised, err := vend.Initialise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimised, err := initialised.Optimise()
+	if err != nil {
+		t.Fatal(err)
+	}
+	optimisedINI := optimised.(INISettings)
+
+	// partially applied, no error reported
+	if err := optimisedINI.SetValuesToApply([]string{"vm.dirty_ratio", "vm.swappiness"}).Apply(); err != nil {
+		t.Error(err)
+	}
+	// transactional apply stops at the failing parameter
+	err = optimisedINI.SetValuesToApply([]string{"vm.dirty_ratio", "vm.swappiness", "atomic"}).Apply()
+	if err == nil {
+		t.Fatal("expected an error, but got none")
+	}
+	if !strings.Contains(err.Error(), "failed to apply parameter 'vm.swappiness' of section [sysctl]") {
+		t.Error(err)
+	}
+	// 'atomic' is ignored during revert
+	if err := optimisedINI.SetValuesToApply([]string{"revert", "atomic"}).Apply(); err != nil {
+		t.Error(err)
+	}
+}
@@ -208,6 +208,13 @@ func CompareNoteFields(actualNote, expectedNote Note) (allMatch bool, comparison
 				expectedValue := expectedMap.MapIndex(key).Interface()
 				ckey := fmt.Sprintf("%s[%s]", fieldName, key.String())
 				comparisons[ckey] = cmpMapValue(fieldName, key, actualValue, expectedValue)
+				if fieldName == "SysctlParams" && comparisons[ckey].MatchExpectation && hasMpathDiffs(refActualNote, key) {
+					// paths of a multipath device differ from
+					// the multipath device
+					fieldComparison := comparisons[ckey]
+					fieldComparison.MatchExpectation = false
+					comparisons[ckey] = fieldComparison
+				}
 				if !comparisons[ckey].MatchExpectation && comparisons[ckey].ReflectFieldName == "SysctlParams" {
 					valApplyList = append(valApplyList, comparisons[ckey].ReflectMapKey)
 				} else if key.String() == "force_latency" && comparisons[ckey].ReflectFieldName == "SysctlParams" {
@@ -226,7 +233,7 @@ func CompareNoteFields(actualNote, expectedNote Note) (allMatch bool, comparison
 					// if this should change in the future use
 					// !strings.Contains(key.String(), "grub")
 					// instead of !isInternalGrub(key.String())
-					if actualValue.(string) != "all:none" && !isInternalGrub(key.String()) && !(system.IsXFSOption.MatchString(key.String()) && actualValue.(string) == "NA") && actualValue.(string) != "PNA" && key.String() != "VSZ_TMPFS_PERCENT" {
+					if actualValue.(string) != "all:none" && !isInternalGrub(key.String()) && !(system.IsFSOption.MatchString(key.String()) && actualValue.(string) == "NA") && actualValue.(string) != "PNA" && key.String() != "VSZ_TMPFS_PERCENT" {
 						allMatch = false
 					}
 				}
@@ -246,6 +253,17 @@ func CompareNoteFields(actualNote, expectedNote Note) (allMatch bool, comparison
 	return
 }
 
+// hasMpathDiffs checks, if the inform map of the note reports paths of a
+// multipath device, which differ from the multipath device
+func hasMpathDiffs(refNote reflect.Value, key reflect.Value) bool {
+	inform := refNote.FieldByName("Inform")
+	if !inform.IsValid() || inform.Kind() != reflect.Map {
+		return false
+	}
+	info := inform.MapIndex(key)
+	return info.IsValid() && strings.HasPrefix(info.String(), MpathDiffsInfo)
+}
+
 // isInternalGrub - checks, if a grub setting found in the note definition
 // is a saptune integrated grub parameter or a customer specific parameter
 func isInternalGrub(val string) bool {
@@ -300,12 +318,26 @@ func cmpMapValue(fieldName string, key reflect.Value, actVal, expVal interface{}
 		op = "<="
 	}
 	actualValueJS, expectedValueJS, match := CompareJSValue(actVal, expVal, op)
+	if !match && fieldName == "SysctlParams" {
+		// multi value parameters - compare field by field, so that
+		// different field separators do not matter
+		match = cmpFields(actualValueJS, expectedValueJS)
+	}
+	if !match && isPerCPUKey(key.String()) {
+		// per CPU values - 'all:<value>' is equal to the same
+		// value for all online CPUs
+		match = cmpCPUVal(actVal.(string), expVal.(string))
+	}
 	if strings.Split(key.String(), ":")[0] == "rpm" {
 		match = system.CmpRpmVers(actVal.(string), expVal.(string))
 	}
 	if strings.Split(key.String(), ":")[0] == "systemd" {
 		match = system.CmpServiceStates(actVal.(string), expVal.(string))
 	}
+	if strings.HasPrefix(key.String(), "kmod:") && !strings.Contains(key.String(), ".") {
+		// kernel module state, not a module parameter
+		match = system.CmpModuleStates(actVal.(string), expVal.(string))
+	}
 	if expVal == "" {
 		// if the expected value is empty, the parameter value will
 		// be untouched
@@ -332,6 +364,22 @@ func cmpMapValue(fieldName string, key reflect.Value, actVal, expVal interface{}
 	return fieldComparison
 }
 
+// cmpFields compares the fields of multi value parameters
+// (e.g. '32000	1024000000	500	32000' of kernel.sem)
+func cmpFields(actVal, expVal string) bool {
+	actFields := strings.Fields(actVal)
+	expFields := strings.Fields(expVal)
+	if len(actFields) < 2 || len(actFields) != len(expFields) {
+		return false
+	}
+	for k, field := range actFields {
+		if field != expFields[k] {
+			return false
+		}
+	}
+	return true
+}
+
 // cmpFieldValue compares ordinary field value
 func cmpFieldValue(fNo int, fieldName string, actNote, expNote reflect.Value) FieldComparison {
 	actualValue := actNote.Field(fNo).Interface()
@@ -127,6 +127,33 @@ func TestCmpMapValue(t *testing.T) {
 	if comparisons != expectedComparison {
 		t.Error(comparisons, expectedComparison)
 	}
+
+	// nfs mount options, which can not be changed by a remount, are
+	// checked nevertheless
+	key = reflect.ValueOf("nfs4opt_vers=4.1")
+	if comparisons = cmpMapValue("SysctlParams", key, "-vers=4.1", OptFSVal("nfs4opt_vers=4.1", "vers=4.1")); comparisons.MatchExpectation {
+		t.Error(comparisons)
+	}
+	if comparisons = cmpMapValue("SysctlParams", key, "+vers=4.1", OptFSVal("nfs4opt_vers=4.1", "vers=4.1")); !comparisons.MatchExpectation {
+		t.Error(comparisons)
+	}
+
+	// a blacklisted kernel module satisfies 'unload', but not 'load'
+	key = reflect.ValueOf("kmod:floppy")
+	if comparisons = cmpMapValue("SysctlParams", key, "blacklist", "unload"); !comparisons.MatchExpectation {
+		t.Error(comparisons)
+	}
+	if comparisons = cmpMapValue("SysctlParams", key, "unload", "blacklist"); comparisons.MatchExpectation {
+		t.Error(comparisons)
+	}
+	if comparisons = cmpMapValue("SysctlParams", key, "blacklist", "load"); comparisons.MatchExpectation {
+		t.Error(comparisons)
+	}
+	// module parameters are compared as they are
+	key = reflect.ValueOf("kmod:floppy.unload")
+	if comparisons = cmpMapValue("SysctlParams", key, "blacklist", "unload"); comparisons.MatchExpectation {
+		t.Error(comparisons)
+	}
 }
 
 func TestCmpFieldValue(t *testing.T) {
@@ -414,3 +441,35 @@ func TestCompareJSValu(t *testing.T) {
 		t.Fatalf("compare '%+v' and '%+v', return '%s' and '%s', match: '%+v'\n", v1i, v2i, r1, r2, match)
 	}
 }
+
+func TestCmpFields(t *testing.T) {
+	if !cmpFields("32000\t1024000000\t500\t32000", "32000 1024000000 500 32000") {
+		t.Error("fields should match")
+	}
+	if cmpFields("32000\t1024000000\t500\t32000", "32000 1024000000 500 128") {
+		t.Error("fields should not match")
+	}
+	if cmpFields("32000\t1024000000", "32000 1024000000 500") {
+		t.Error("different number of fields should not match")
+	}
+	if cmpFields("32000", "32000 ") {
+		t.Error("single values are not compared field by field")
+	}
+}
+
+func TestHasMpathDiffs(t *testing.T) {
+	vend := INISettings{Inform: map[string]string{"IO_SCHEDULER_dm-0": MpathDiffsInfo + "sdc=mq-deadline", "NRREQ_dm-0": ""}}
+	refNote := reflect.ValueOf(vend)
+	if !hasMpathDiffs(refNote, reflect.ValueOf("IO_SCHEDULER_dm-0")) {
+		t.Error("expected 'true', got 'false'")
+	}
+	for _, key := range []string{"NRREQ_dm-0", "IO_SCHEDULER_sda"} {
+		if hasMpathDiffs(refNote, reflect.ValueOf(key)) {
+			t.Errorf("'%s': expected 'false', got 'true'", key)
+		}
+	}
+	// note without inform map
+	if hasMpathDiffs(reflect.ValueOf(struct{ ID string }{ID: "1"}), reflect.ValueOf("IO_SCHEDULER_dm-0")) {
+		t.Error("expected 'false', got 'true'")
+	}
+}
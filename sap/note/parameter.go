@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 )
 
 // ParameterNoteEntry stores the parameter values set by a Note
@@ -24,7 +25,8 @@ type ParameterNotes struct {
 
 // GetPathToParameter returns path to the serialised parameter state file.
 func GetPathToParameter(param string) string {
-	return path.Join(system.SaptuneParameterStateDir, param)
+	// parameter names may contain mount points
+	return path.Join(system.SaptuneParameterStateDir, strings.ReplaceAll(param, "/", "%2F"))
 }
 
 // ListParams lists all stored parameter states. Return parameter names
@@ -41,7 +43,7 @@ func ListParams() (ret []string, err error) {
 	}
 	ret = make([]string, 0, len(dirContent))
 	for _, pname := range dirContent {
-		ret = append(ret, pname.Name())
+		ret = append(ret, strings.ReplaceAll(pname.Name(), "%2F", "/"))
 	}
 	return
 }
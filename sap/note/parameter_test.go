@@ -22,6 +22,10 @@ func TestGetPathToParameter(t *testing.T) {
 	if val != "/run/saptune/parameter/FILENAME4TEST" {
 		t.Errorf("parameter file name: %v.\n", val)
 	}
+	val = GetPathToParameter("nfsopt_hard@/hana/shared")
+	if val != "/run/saptune/parameter/nfsopt_hard@%2Fhana%2Fshared" {
+		t.Errorf("parameter file name: %v.\n", val)
+	}
 }
 
 func TestGetSavedParameterNotes(t *testing.T) {
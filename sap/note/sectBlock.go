@@ -11,6 +11,10 @@ import (
 
 // section [block]
 
+// MpathDiffsInfo is the prefix of the info, which lists the paths of a
+// multipath device differing from the multipath device
+const MpathDiffsInfo = "mpathDiffs:"
+
 // GetBlkVal initialise the block device structure with the current
 // system settings
 func GetBlkVal(key string, cur *param.BlockDeviceQueue) (string, string, error) {
@@ -26,6 +30,7 @@ func GetBlkVal(key string, cur *param.BlockDeviceQueue) (string, string, error)
 		newQueue := newIOQ.(param.BlockDeviceSchedulers).SchedulerChoice
 		retVal = newQueue[strings.TrimPrefix(key, "IO_SCHEDULER_")]
 		cur.BlockDeviceSchedulers = newIOQ.(param.BlockDeviceSchedulers)
+		info = chkMpathPaths(strings.TrimPrefix(key, "IO_SCHEDULER_"), "scheduler")
 	case system.IsNrreq.MatchString(key):
 		newNrR, err := cur.BlockDeviceNrRequests.Inspect()
 		if err != nil {
@@ -34,6 +39,7 @@ func GetBlkVal(key string, cur *param.BlockDeviceQueue) (string, string, error)
 		newReq := newNrR.(param.BlockDeviceNrRequests).NrRequests
 		retVal = strconv.Itoa(newReq[strings.TrimPrefix(key, "NRREQ_")])
 		cur.BlockDeviceNrRequests = newNrR.(param.BlockDeviceNrRequests)
+		info = chkMpathPaths(strings.TrimPrefix(key, "NRREQ_"), "nr_requests")
 	case system.IsRahead.MatchString(key):
 		newRahead, err := cur.BlockDeviceReadAheadKB.Inspect()
 		if err != nil {
@@ -42,6 +48,7 @@ func GetBlkVal(key string, cur *param.BlockDeviceQueue) (string, string, error)
 		newRah := newRahead.(param.BlockDeviceReadAheadKB).ReadAheadKB
 		retVal = strconv.Itoa(newRah[strings.TrimPrefix(key, "READ_AHEAD_KB_")])
 		cur.BlockDeviceReadAheadKB = newRahead.(param.BlockDeviceReadAheadKB)
+		info = chkMpathPaths(strings.TrimPrefix(key, "READ_AHEAD_KB_"), "read_ahead_kb")
 	case system.IsMsect.MatchString(key):
 		newMsect, err := cur.BlockDeviceMaxSectorsKB.Inspect()
 		if err != nil {
@@ -50,10 +57,140 @@ func GetBlkVal(key string, cur *param.BlockDeviceQueue) (string, string, error)
 		newMse := newMsect.(param.BlockDeviceMaxSectorsKB).MaxSectorsKB
 		retVal = strconv.Itoa(newMse[strings.TrimPrefix(key, "MAX_SECTORS_KB_")])
 		cur.BlockDeviceMaxSectorsKB = newMsect.(param.BlockDeviceMaxSectorsKB)
+		info = chkMpathPaths(strings.TrimPrefix(key, "MAX_SECTORS_KB_"), "max_sectors_kb")
+	case system.IsRqAffinity.MatchString(key):
+		newRqa, err := cur.BlockDeviceRqAffinity.Inspect()
+		if err != nil {
+			return "", info, err
+		}
+		cur.BlockDeviceRqAffinity = newRqa.(param.BlockDeviceRqAffinity)
+		retVal = queueIntVal(cur.BlockDeviceRqAffinity.RqAffinity, strings.TrimPrefix(key, "RQ_AFFINITY_"))
+		info = chkMpathPaths(strings.TrimPrefix(key, "RQ_AFFINITY_"), "rq_affinity")
+	case system.IsNomerges.MatchString(key):
+		newNom, err := cur.BlockDeviceNomerges.Inspect()
+		if err != nil {
+			return "", info, err
+		}
+		cur.BlockDeviceNomerges = newNom.(param.BlockDeviceNomerges)
+		retVal = queueIntVal(cur.BlockDeviceNomerges.Nomerges, strings.TrimPrefix(key, "NOMERGES_"))
+		info = chkMpathPaths(strings.TrimPrefix(key, "NOMERGES_"), "nomerges")
+	case system.IsWbtLat.MatchString(key):
+		newWbt, err := cur.BlockDeviceWbtLatUsec.Inspect()
+		if err != nil {
+			return "", info, err
+		}
+		cur.BlockDeviceWbtLatUsec = newWbt.(param.BlockDeviceWbtLatUsec)
+		retVal = queueIntVal(cur.BlockDeviceWbtLatUsec.WbtLatUsec, strings.TrimPrefix(key, "WBT_LAT_USEC_"))
+		info = chkMpathPaths(strings.TrimPrefix(key, "WBT_LAT_USEC_"), "wbt_lat_usec")
+	case system.IsAddRandom.MatchString(key):
+		newAdr, err := cur.BlockDeviceAddRandom.Inspect()
+		if err != nil {
+			return "", info, err
+		}
+		cur.BlockDeviceAddRandom = newAdr.(param.BlockDeviceAddRandom)
+		retVal = queueIntVal(cur.BlockDeviceAddRandom.AddRandom, strings.TrimPrefix(key, "ADD_RANDOM_"))
+		info = chkMpathPaths(strings.TrimPrefix(key, "ADD_RANDOM_"), "add_random")
+	case system.IsIOSched.MatchString(key):
+		newIOS, err := cur.BlockDeviceIOSchedTunables.Inspect()
+		if err != nil {
+			return "", info, err
+		}
+		cur.BlockDeviceIOSchedTunables = newIOS.(param.BlockDeviceIOSchedTunables)
+		tunable, bdev := system.SplitIOSchedKey(key)
+		val, ok := cur.BlockDeviceIOSchedTunables.Tunables[bdev][tunable]
+		if !ok {
+			// tunable not available for the current scheduler
+			val = "NA"
+		}
+		retVal = val
+		info = chkMpathPaths(bdev, path.Join("iosched", tunable))
 	}
 	return retVal, info, nil
 }
 
+// queueIntVal returns the value of an integer queue attribute of a block
+// device or 'PNA', if the attribute is not available for the device
+func queueIntVal(vals map[string]int, bdev string) string {
+	ival, ok := vals[bdev]
+	if !ok {
+		return "PNA"
+	}
+	return strconv.Itoa(ival)
+}
+
+// chkMpathPaths checks, if the paths of a multipath device differ from the
+// multipath device regarding the queue attribute. The differing paths are
+// returned as info, so that verify can flag them
+func chkMpathPaths(bdev, attr string) string {
+	diffs := system.GetMpathPathDiffs(bdev, attr)
+	if len(diffs) == 0 {
+		return ""
+	}
+	system.InfoLog("paths of multipath device '%s' differ regarding '%s': %s", bdev, attr, strings.Join(diffs, " "))
+	return MpathDiffsInfo + strings.Join(diffs, " ")
+}
+
+// blkQueueAttr returns the block device and the queue attribute of a
+// parameter of section [block] or empty strings for an unknown parameter
+func blkQueueAttr(key string) (string, string) {
+	switch {
+	case system.IsSched.MatchString(key):
+		return strings.TrimPrefix(key, "IO_SCHEDULER_"), "scheduler"
+	case system.IsNrreq.MatchString(key):
+		return strings.TrimPrefix(key, "NRREQ_"), "nr_requests"
+	case system.IsRahead.MatchString(key):
+		return strings.TrimPrefix(key, "READ_AHEAD_KB_"), "read_ahead_kb"
+	case system.IsMsect.MatchString(key):
+		return strings.TrimPrefix(key, "MAX_SECTORS_KB_"), "max_sectors_kb"
+	case system.IsRqAffinity.MatchString(key):
+		return strings.TrimPrefix(key, "RQ_AFFINITY_"), "rq_affinity"
+	case system.IsNomerges.MatchString(key):
+		return strings.TrimPrefix(key, "NOMERGES_"), "nomerges"
+	case system.IsWbtLat.MatchString(key):
+		return strings.TrimPrefix(key, "WBT_LAT_USEC_"), "wbt_lat_usec"
+	case system.IsAddRandom.MatchString(key):
+		return strings.TrimPrefix(key, "ADD_RANDOM_"), "add_random"
+	case system.IsIOSched.MatchString(key):
+		tunable, bdev := system.SplitIOSchedKey(key)
+		return bdev, path.Join("iosched", tunable)
+	}
+	return "", ""
+}
+
+// ApplyMpathPaths applies the [block] parameters, which saptune has set for
+// the multipath device, to all paths of the multipath device (e.g. a path
+// added to an already tuned multipath device).
+// Returns the queue attributes set for the paths
+func ApplyMpathPaths(mpathDev string) []string {
+	attrs := []string{}
+	if len(system.GetMpathPaths(mpathDev)) == 0 {
+		return attrs
+	}
+	params, err := ListParams()
+	if err != nil {
+		system.WarningLog("failed to read the parameter state files - %v", err)
+		return attrs
+	}
+	for _, key := range params {
+		bdev, attr := blkQueueAttr(key)
+		if bdev != mpathDev || attr == "" {
+			continue
+		}
+		if len(GetSavedParameterNotes(key).AllNotes) < 2 {
+			// only start value available, not set by saptune
+			continue
+		}
+		if attr == "scheduler" {
+			// the I/O scheduler tunables depend on the scheduler
+			attrs = append([]string{attr}, attrs...)
+		} else {
+			attrs = append(attrs, attr)
+		}
+	}
+	param.SyncMpathPaths(mpathDev, attrs)
+	return attrs
+}
+
 // OptBlkVal optimises the block device structure with the settings
 // from the configuration file
 func OptBlkVal(key, cfgval string, cur *param.BlockDeviceQueue, bOK map[string][]string) (string, string) {
@@ -108,11 +245,61 @@ func OptBlkVal(key, cfgval string, cur *param.BlockDeviceQueue, bOK map[string][
 		ival, sval, info = chkMaxHWsector(key, sval)
 		opt, _ := cur.BlockDeviceMaxSectorsKB.Optimise(ival)
 		cur.BlockDeviceMaxSectorsKB = opt.(param.BlockDeviceMaxSectorsKB)
+	case system.IsRqAffinity.MatchString(key):
+		if sval = optQueueVal(key, "RQ_AFFINITY_", "rq_affinity", sval); sval != "" {
+			opt, _ := cur.BlockDeviceRqAffinity.Optimise(strings.TrimPrefix(key, "RQ_AFFINITY_") + " " + sval)
+			cur.BlockDeviceRqAffinity = opt.(param.BlockDeviceRqAffinity)
+		}
+	case system.IsNomerges.MatchString(key):
+		if sval = optQueueVal(key, "NOMERGES_", "nomerges", sval); sval != "" {
+			opt, _ := cur.BlockDeviceNomerges.Optimise(strings.TrimPrefix(key, "NOMERGES_") + " " + sval)
+			cur.BlockDeviceNomerges = opt.(param.BlockDeviceNomerges)
+		}
+	case system.IsWbtLat.MatchString(key):
+		if sval == "-1" {
+			// '-1' resets to the default of the device, which is
+			// reported instead of '-1' afterwards
+			if def, ok := param.WbtLatDefault(strings.TrimPrefix(key, "WBT_LAT_USEC_")); ok {
+				sval = strconv.Itoa(def)
+			}
+		}
+		if sval = optQueueVal(key, "WBT_LAT_USEC_", "wbt_lat_usec", sval); sval != "" {
+			opt, _ := cur.BlockDeviceWbtLatUsec.Optimise(strings.TrimPrefix(key, "WBT_LAT_USEC_") + " " + sval)
+			cur.BlockDeviceWbtLatUsec = opt.(param.BlockDeviceWbtLatUsec)
+		}
+	case system.IsAddRandom.MatchString(key):
+		if sval = optQueueVal(key, "ADD_RANDOM_", "add_random", sval); sval != "" {
+			opt, _ := cur.BlockDeviceAddRandom.Optimise(strings.TrimPrefix(key, "ADD_RANDOM_") + " " + sval)
+			cur.BlockDeviceAddRandom = opt.(param.BlockDeviceAddRandom)
+		}
+	case system.IsIOSched.MatchString(key):
+		tunable, bdev := system.SplitIOSchedKey(key)
+		// the tunables depend on the scheduler, which will be used
+		// for the device
+		if !param.IsValidIOSchedTunable(bdev, cur.BlockDeviceSchedulers.SchedulerChoice[bdev], tunable) {
+			info = "NA"
+		} else {
+			opt, _ := cur.BlockDeviceIOSchedTunables.Optimise(bdev + " " + tunable + " " + sval)
+			cur.BlockDeviceIOSchedTunables = opt.(param.BlockDeviceIOSchedTunables)
+		}
 	}
 	return sval, info
 }
 
+// optQueueVal checks the expected value of an integer queue attribute.
+// An invalid value results in an empty value, so the parameter is left
+// untouched
+func optQueueVal(key, prefix, attr, val string) string {
+	if !param.IsValidQueueValue(attr, val) {
+		system.WarningLog("value '%s' for '%s' of device '%s' is not valid, parameter will be left untouched.", val, attr, strings.TrimPrefix(key, prefix))
+		return ""
+	}
+	return val
+}
+
 // SetBlkVal applies the settings to the system
+// The revert of the last Note using the parameter restores the start values
+// of the paths of a multipath device
 func SetBlkVal(key, value string, cur *param.BlockDeviceQueue, revert bool) error {
 	var err error
 
@@ -152,10 +339,72 @@ func SetBlkVal(key, value string, cur *param.BlockDeviceQueue, revert bool) erro
 		if err != nil {
 			return err
 		}
+	case system.IsRqAffinity.MatchString(key), system.IsNomerges.MatchString(key), system.IsWbtLat.MatchString(key), system.IsAddRandom.MatchString(key):
+		err = setQueueIntVal(key, value, cur, revert)
+	case system.IsIOSched.MatchString(key):
+		if value == "" || value == "NA" || value == "PNA" {
+			// tunable was not available or is untouched
+			return nil
+		}
+		tunable, bdev := system.SplitIOSchedKey(key)
+		if revert {
+			if cur.BlockDeviceIOSchedTunables.Tunables[bdev] == nil {
+				cur.BlockDeviceIOSchedTunables.Tunables[bdev] = make(map[string]string)
+			}
+			cur.BlockDeviceIOSchedTunables.Tunables[bdev][tunable] = value
+		}
+		err = cur.BlockDeviceIOSchedTunables.Apply(bdev + " " + tunable)
+	}
+	if revert && IsLastNoteOfParameter(key) {
+		// no more notes using this parameter, so restore the start
+		// values of the paths of a multipath device, which can
+		// differ from the start value of the multipath device
+		if bdev, attr := blkQueueAttr(key); attr != "" {
+			if rerr := system.RestoreMpathPathStart(bdev, attr); rerr != nil {
+				err = rerr
+			}
+		}
 	}
 	return err
 }
 
+// setQueueIntVal applies the value of an integer queue attribute
+// (rq_affinity, nomerges, wbt_lat_usec and add_random) to the system
+func setQueueIntVal(key, value string, cur *param.BlockDeviceQueue, revert bool) error {
+	if value == "" || value == "PNA" {
+		// attribute not available or untouched
+		return nil
+	}
+	ival, _ := strconv.Atoi(value)
+	switch {
+	case system.IsRqAffinity.MatchString(key):
+		bdev := strings.TrimPrefix(key, "RQ_AFFINITY_")
+		if revert {
+			cur.BlockDeviceRqAffinity.RqAffinity[bdev] = ival
+		}
+		return cur.BlockDeviceRqAffinity.Apply(bdev)
+	case system.IsNomerges.MatchString(key):
+		bdev := strings.TrimPrefix(key, "NOMERGES_")
+		if revert {
+			cur.BlockDeviceNomerges.Nomerges[bdev] = ival
+		}
+		return cur.BlockDeviceNomerges.Apply(bdev)
+	case system.IsWbtLat.MatchString(key):
+		bdev := strings.TrimPrefix(key, "WBT_LAT_USEC_")
+		if revert {
+			cur.BlockDeviceWbtLatUsec.WbtLatUsec[bdev] = ival
+		}
+		return cur.BlockDeviceWbtLatUsec.Apply(bdev)
+	case system.IsAddRandom.MatchString(key):
+		bdev := strings.TrimPrefix(key, "ADD_RANDOM_")
+		if revert {
+			cur.BlockDeviceAddRandom.AddRandom[bdev] = ival
+		}
+		return cur.BlockDeviceAddRandom.Apply(bdev)
+	}
+	return nil
+}
+
 // chkMaxHWsector checks, if the given value for max_sector_kb exceeds
 // max_hw_sector_kb
 func chkMaxHWsector(key, val string) (int, string, string) {
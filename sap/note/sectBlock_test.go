@@ -3,6 +3,7 @@ package note
 import (
 	"github.com/SUSE/saptune/sap/param"
 	"github.com/SUSE/saptune/system"
+	"strconv"
 	"testing"
 )
 
@@ -142,3 +143,102 @@ func TestChkMaxHWsector(t *testing.T) {
 		t.Errorf("expected info as 'limited', but got '%s' - '%+v' - '%+v'\n", info, ival, sval)
 	}
 }
+
+func TestChkMpathPaths(t *testing.T) {
+	// no multipath device
+	if info := chkMpathPaths("sda", "scheduler"); info != "" {
+		t.Errorf("expected empty info, got '%s'", info)
+	}
+}
+
+func TestOptBlkValQueueAttrs(t *testing.T) {
+	blckOK := make(map[string][]string)
+	tblck := resetToFactoryBlockDevices()
+	tests := []struct {
+		key, val, exp string
+	}{
+		{"RQ_AFFINITY_sdz", "2", "2"},
+		{"RQ_AFFINITY_sdz", "3", ""},
+		{"NOMERGES_sdz", "1", "1"},
+		{"NOMERGES_sdz", "-1", ""},
+		{"WBT_LAT_USEC_sdz", "-1", "-1"},
+		{"WBT_LAT_USEC_sdz", "abc", ""},
+		{"ADD_RANDOM_sdz", "0", "0"},
+		{"ADD_RANDOM_sdz", "2", ""},
+	}
+	for _, tst := range tests {
+		if val, info := OptBlkVal(tst.key, tst.val, &tblck, blckOK); val != tst.exp || info != "" {
+			t.Errorf("'%s=%s': expected '%s', got '%s', '%s'", tst.key, tst.val, tst.exp, val, info)
+		}
+	}
+	if tblck.BlockDeviceRqAffinity.RqAffinity["sdz"] != 2 || tblck.BlockDeviceNomerges.Nomerges["sdz"] != 1 || tblck.BlockDeviceWbtLatUsec.WbtLatUsec["sdz"] != -1 || tblck.BlockDeviceAddRandom.AddRandom["sdz"] != 0 {
+		t.Errorf("wrong optimised values - '%+v'", tblck)
+	}
+
+	// '-1' is replaced by the default of the device, as the kernel never
+	// reports '-1'
+	setUp(t)
+	if def, ok := param.WbtLatDefault(tstDisk); ok {
+		if val, _ := OptBlkVal("WBT_LAT_USEC_"+tstDisk, "-1", &tblck, blckOK); val != strconv.Itoa(def) || tblck.BlockDeviceWbtLatUsec.WbtLatUsec[tstDisk] != def {
+			t.Errorf("expected '%d', got '%s' - '%+v'", def, val, tblck.BlockDeviceWbtLatUsec)
+		}
+	}
+
+	// I/O scheduler tunables depend on the scheduler used for the device
+	tblck.BlockDeviceSchedulers.SchedulerChoice["sdz"] = "mq-deadline"
+	if val, info := OptBlkVal("IOSCHED.read_expire_sdz", "250", &tblck, blckOK); val != "250" || info != "" {
+		t.Errorf("got '%s', '%s'", val, info)
+	}
+	if tblck.BlockDeviceIOSchedTunables.Tunables["sdz"]["read_expire"] != "250" {
+		t.Errorf("wrong optimised values - '%+v'", tblck.BlockDeviceIOSchedTunables)
+	}
+	if val, info := OptBlkVal("IOSCHED.slice_idle_sdz", "0", &tblck, blckOK); val != "0" || info != "NA" {
+		t.Errorf("got '%s', '%s'", val, info)
+	}
+	if _, ok := tblck.BlockDeviceIOSchedTunables.Tunables["sdz"]["slice_idle"]; ok {
+		t.Errorf("unexpected tunable 'slice_idle' - '%+v'", tblck.BlockDeviceIOSchedTunables)
+	}
+
+	// not available or untouched values are not applied
+	for _, key := range []string{"RQ_AFFINITY_sdz", "IOSCHED.read_expire_sdz"} {
+		for _, val := range []string{"", "PNA"} {
+			if err := SetBlkVal(key, val, &tblck, true); err != nil {
+				t.Errorf("'%s=%s': %v", key, val, err)
+			}
+		}
+	}
+}
+
+func TestQueueIntVal(t *testing.T) {
+	vals := map[string]int{"sda": 2}
+	if val := queueIntVal(vals, "sda"); val != "2" {
+		t.Errorf("expected '2', got '%s'", val)
+	}
+	if val := queueIntVal(vals, "sdb"); val != "PNA" {
+		t.Errorf("expected 'PNA', got '%s'", val)
+	}
+}
+
+func TestBlkQueueAttr(t *testing.T) {
+	tests := map[string][]string{
+		"IO_SCHEDULER_dm-0":       {"dm-0", "scheduler"},
+		"NRREQ_sda":               {"sda", "nr_requests"},
+		"READ_AHEAD_KB_sda":       {"sda", "read_ahead_kb"},
+		"MAX_SECTORS_KB_sda":      {"sda", "max_sectors_kb"},
+		"RQ_AFFINITY_sda":         {"sda", "rq_affinity"},
+		"NOMERGES_sda":            {"sda", "nomerges"},
+		"WBT_LAT_USEC_sda":        {"sda", "wbt_lat_usec"},
+		"ADD_RANDOM_sda":          {"sda", "add_random"},
+		"IOSCHED.read_expire_sda": {"sda", "iosched/read_expire"},
+		"vm.swappiness":           {"", ""},
+	}
+	for key, exp := range tests {
+		if bdev, attr := blkQueueAttr(key); bdev != exp[0] || attr != exp[1] {
+			t.Errorf("'%s': expected '%v', got '%s', '%s'", key, exp, bdev, attr)
+		}
+	}
+	// no multipath device, nothing to apply
+	if attrs := ApplyMpathPaths(tstDisk); len(attrs) != 0 {
+		t.Errorf("expected no attributes, got '%v'", attrs)
+	}
+}
@@ -22,15 +22,23 @@ func GetCPUVal(key string) (string, string, string) {
 			info = "hasDiffs"
 		}
 	case "energy_perf_bias":
-		// cpupower -c all info  -b
+		// read directly from /sys/devices/system/cpu/cpu*/power
 		val = system.GetPerfBias()
 	case "governor":
 		// cpupower -c all frequency-info -p
 		//or better
 		// cat /sys/devices/system/cpu/cpu0/cpufreq/scaling_governor
 		newGov := system.GetGovernor()
-		for k, v := range newGov {
-			val = val + fmt.Sprintf("%s:%s ", k, v)
+		if gov, ok := newGov["all"]; ok {
+			val = "all:" + gov
+		} else {
+			val = formatCPUVal(newGov, system.OnlineCPUs())
+		}
+	case "energy_performance_preference", "scaling_min_freq", "scaling_max_freq":
+		// read directly from /sys/devices/system/cpu/cpu*/cpufreq
+		val = formatCPUVal(system.GetCPUFreqAttr(key), system.OnlineCPUs())
+		if val == "" {
+			val = "all:none"
 		}
 	}
 	val = strings.TrimSpace(val)
@@ -40,39 +48,63 @@ func GetCPUVal(key string) (string, string, string) {
 	return val, flsVal, info
 }
 
+// isPerCPUKey checks, if the parameter of section [cpu] has per CPU values
+func isPerCPUKey(key string) bool {
+	switch key {
+	case "energy_perf_bias", "governor", "energy_performance_preference", "scaling_min_freq", "scaling_max_freq":
+		return true
+	}
+	return false
+}
+
+// isFreqLimitKey checks, if the parameter of section [cpu] is a frequency
+// limit, which supports the values 'min' and 'max' for the hardware limits
+// of the CPU
+func isFreqLimitKey(key string) bool {
+	return key == "scaling_min_freq" || key == "scaling_max_freq"
+}
+
+// commonCPUVal returns the value shared by all CPUs from a per CPU value
+// like 'cpu0:performance cpu1:performance' or 'all:6' or an empty string,
+// if the CPUs have different values
+func commonCPUVal(val string) string {
+	common := ""
+	for i, entry := range strings.Fields(val) {
+		fields := strings.Split(entry, ":")
+		cval := fields[len(fields)-1]
+		if i > 0 && cval != common {
+			return ""
+		}
+		common = cval
+	}
+	return common
+}
+
 // OptCPUVal optimises the cpu performance structure with the settings
 // from the configuration file
+// For the parameters with per CPU values (e.g. 'governor') the configured
+// value can be a single value for all CPUs (e.g. 'performance') or a list of
+// per CPU values with CPU lists and ranges (e.g.
+// 'cpu0-15:performance cpu16-31:powersave').
+// CPUs not covered by a per CPU value keep their current value
 func OptCPUVal(key, actval, cfgval string) string {
-	//ANGI TODO - check cfgval is not a single value like 'performance' but
-	// cpu0:performance cpu2:powersave
 	sval := strings.ToLower(cfgval)
 	rval := ""
-	val := "0"
-	switch key {
-	case "force_latency":
+	switch {
+	case key == "force_latency":
 		rval = sval
-	case "energy_perf_bias":
-		//performance - 0, normal - 6, powersave - 15
-		switch sval {
-		case "performance":
-			val = "0"
-		case "normal":
-			val = "6"
-		case "powersave":
-			val = "15"
-		default:
-			system.WarningLog("wrong selection for energy_perf_bias. Now set to 'performance'")
-			val = "0"
-		}
-		//ANGI TODO - if actval 'all:6', but cfgval 'cpu0:performance cpu1:normal cpu2:powersave'
-		// - does not work now - check length of both?
-		// same for governor
-		for _, entry := range strings.Fields(actval) {
-			fields := strings.Split(entry, ":")
-			rval = rval + fmt.Sprintf("%s:%s ", fields[0], val)
+	case isPerCPUKey(key):
+		if isFreqLimitKey(key) && (sval == "min" || sval == "max") && actval != "all:none" {
+			// hardware limits may differ between the CPUs
+			sval = "all:" + sval
+		}
+		if strings.Contains(sval, ":") {
+			return optPerCPUVal(key, actval, sval)
+		}
+		val := sval
+		if key == "energy_perf_bias" {
+			val = perfBiasVal(sval)
 		}
-	case "governor":
-		val = sval
 		for _, entry := range strings.Fields(actval) {
 			fields := strings.Split(entry, ":")
 			rval = rval + fmt.Sprintf("%s:%s ", fields[0], val)
@@ -81,6 +113,142 @@ func OptCPUVal(key, actval, cfgval string) string {
 	return strings.TrimSpace(rval)
 }
 
+// perfBiasVal returns the numeric energy_perf_bias value of a configured
+// value
+func perfBiasVal(val string) string {
+	//performance - 0, normal - 6, powersave - 15
+	switch val {
+	case "performance":
+		return "0"
+	case "normal":
+		return "6"
+	case "powersave":
+		return "15"
+	case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14", "15":
+		// numeric value, e.g. from a range operator
+		return val
+	}
+	system.WarningLog("wrong selection for energy_perf_bias. Now set to 'performance'")
+	return "0"
+}
+
+// optPerCPUVal returns the expected per CPU value of the online CPUs from
+// the current value and the configured per CPU values
+func optPerCPUVal(key, actval, cfgval string) string {
+	online := system.OnlineCPUs()
+	vals, _ := perCPUVal(actval, online)
+	cfgVals, err := perCPUVal(cfgval, online)
+	if err != nil {
+		system.WarningLog("%s: %v", key, err)
+	}
+	pbVals := make(map[string]string)
+	for cpu, val := range cfgVals {
+		switch {
+		case key == "energy_perf_bias":
+			if _, ok := pbVals[val]; !ok {
+				pbVals[val] = perfBiasVal(val)
+			}
+			val = pbVals[val]
+		case isFreqLimitKey(key) && (val == "min" || val == "max"):
+			if limit := system.CPUFreqLimit(cpu, val); limit != "" {
+				val = limit
+			}
+		}
+		vals[cpu] = val
+	}
+	return formatCPUVal(vals, online)
+}
+
+// perCPUVal expands a per CPU value like 'all:6', 'cpu0:6 cpu1:15' or
+// 'cpu0-15:performance cpu16,18:powersave' to a map of CPU names and values.
+// 'all' covers all online CPUs. Entries with wrong syntax and CPUs, which
+// are not online, are skipped and reported in the returned error
+func perCPUVal(val string, online []string) (map[string]string, error) {
+	vals := make(map[string]string)
+	isOnline := make(map[string]bool)
+	for _, cpu := range online {
+		isOnline[cpu] = true
+	}
+	errs := []string{}
+	for _, entry := range strings.Fields(val) {
+		fields := strings.Split(entry, ":")
+		if len(fields) != 2 || fields[1] == "" {
+			errs = append(errs, fmt.Sprintf("wrong per CPU value '%s'", entry))
+			continue
+		}
+		if fields[0] == "all" {
+			for _, cpu := range online {
+				vals[cpu] = fields[1]
+			}
+			continue
+		}
+		cpus, err := system.ParseCPUList(strings.TrimPrefix(fields[0], "cpu"))
+		if !strings.HasPrefix(fields[0], "cpu") || err != nil {
+			errs = append(errs, fmt.Sprintf("wrong CPU list '%s'", fields[0]))
+			continue
+		}
+		offline := []int{}
+		for _, cpu := range cpus {
+			cpuName := fmt.Sprintf("cpu%d", cpu)
+			if !isOnline[cpuName] {
+				offline = append(offline, cpu)
+				continue
+			}
+			vals[cpuName] = fields[1]
+		}
+		if len(offline) != 0 {
+			errs = append(errs, fmt.Sprintf("CPUs '%s' not online, skipping", system.FormatCPUList(offline)))
+		}
+	}
+	if len(errs) != 0 {
+		return vals, fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return vals, nil
+}
+
+// formatCPUVal returns the per CPU value of the CPUs in the order of 'cpus'
+// ('cpu0:performance cpu1:powersave') or 'all:<value>', if all CPUs share
+// the same value
+func formatCPUVal(vals map[string]string, cpus []string) string {
+	rval := ""
+	common := ""
+	all := len(cpus) != 0
+	for i, cpu := range cpus {
+		val, ok := vals[cpu]
+		if !ok {
+			all = false
+			continue
+		}
+		if i == 0 {
+			common = val
+		} else if val != common {
+			all = false
+		}
+		rval = rval + fmt.Sprintf("%s:%s ", cpu, val)
+	}
+	if all {
+		return "all:" + common
+	}
+	return strings.TrimSpace(rval)
+}
+
+// cmpCPUVal compares the per CPU values of the online CPUs, so that
+// 'all:performance' matches 'cpu0:performance cpu1:performance'
+func cmpCPUVal(actval, expval string) bool {
+	online := system.OnlineCPUs()
+	actVals, aerr := perCPUVal(actval, online)
+	expVals, eerr := perCPUVal(expval, online)
+	if aerr != nil || eerr != nil || len(expVals) == 0 {
+		return false
+	}
+	for cpu, val := range expVals {
+		if actVals[cpu] != val {
+			return false
+		}
+	}
+	return true
+}
+
 // SetCPUVal applies the settings to the system
 func SetCPUVal(key, value, noteID, savedStates, oval string, revert bool) error {
 	var err error
@@ -102,6 +270,8 @@ func SetCPUVal(key, value, noteID, savedStates, oval string, revert bool) error
 		err = system.SetPerfBias(value)
 	case "governor":
 		err = system.SetGovernor(value)
+	case "energy_performance_preference", "scaling_min_freq", "scaling_max_freq":
+		err = system.SetCPUFreqAttr(key, value)
 	}
 
 	return err
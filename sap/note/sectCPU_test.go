@@ -1,6 +1,7 @@
 package note
 
 import (
+	"github.com/SUSE/saptune/system"
 	"testing"
 )
 
@@ -17,6 +18,12 @@ func TestGetCPUVal(t *testing.T) {
 	if val != "all:none" && val != "" {
 		t.Logf("governor supported: '%s'\n", val)
 	}
+	for _, key := range []string{"energy_performance_preference", "scaling_min_freq", "scaling_max_freq"} {
+		val, _, info := GetCPUVal(key)
+		if val == "" || (val == "all:none" && info != "notSupported") {
+			t.Errorf("%s: wrong value '%s' or info '%s'", key, val, info)
+		}
+	}
 }
 
 func TestOptCPUVal(t *testing.T) {
@@ -46,16 +53,26 @@ func TestOptCPUVal(t *testing.T) {
 		t.Error(val)
 	}
 
-	/* future feature
-	val = OptCPUVal("energy_perf_bias", "cpu0:6 cpu1:6 cpu2:6", "cpu0:performance cpu1:normal cpu2:powersave")
-	if val != "cpu0:0 cpu1:6 cpu2:15" {
-		t.Error(val)
+	// per CPU values
+	online := system.OnlineCPUs()
+	expVal := "all:0"
+	if len(online) > 1 {
+		vals := map[string]string{}
+		for _, cpu := range online {
+			vals[cpu] = "6"
+		}
+		vals["cpu0"] = "0"
+		expVal = formatCPUVal(vals, online)
+	}
+	val = OptCPUVal("energy_perf_bias", "all:6", "cpu0:performance")
+	if val != expVal {
+		t.Errorf("expected '%s', got '%s'", expVal, val)
 	}
-	val = OptCPUVal("energy_perf_bias", "all:6", "cpu0:performance cpu1:normal cpu2:powersave")
-	if val != "cpu0:performance cpu1:normal cpu2:powersave" {
+	// offline CPUs are skipped
+	val = OptCPUVal("energy_perf_bias", "all:6", "cpu0:normal cpu100000:powersave")
+	if val != "all:6" {
 		t.Error(val)
 	}
-	*/
 
 	val = OptCPUVal("governor", "all:powersave", "performance")
 	if val != "all:performance" {
@@ -65,16 +82,105 @@ func TestOptCPUVal(t *testing.T) {
 	if val != "cpu0:performance cpu1:performance cpu2:performance" {
 		t.Error(val)
 	}
-	/* future feature
-	val = OptCPUVal("governor", "cpu0:powersave cpu1:performance cpu2:powersave", "cpu0:performance cpu1:powersave cpu2:performance")
-	if val != "cpu0:performance cpu1:powersave cpu2:performance" {
+	val = OptCPUVal("governor", "all:powersave", "all:Performance")
+	if val != "all:performance" {
+		t.Error(val)
+	}
+
+	val = OptCPUVal("energy_performance_preference", "all:balance_performance", "performance")
+	if val != "all:performance" {
+		t.Error(val)
+	}
+	val = OptCPUVal("scaling_max_freq", "cpu0:2000000 cpu1:3000000", "2500000")
+	if val != "cpu0:2500000 cpu1:2500000" {
 		t.Error(val)
 	}
-	val = OptCPUVal("energy_perf_bias", "all:powersave", "cpu0:performance cpu1:powersave cpu2:performance")
-	if val != "cpu0:performance cpu1:powersave cpu2:performance" {
+	val = OptCPUVal("scaling_max_freq", "all:none", "max")
+	if val != "all:max" {
 		t.Error(val)
 	}
-	*/
 }
 
 //SetCPUVal
+
+func TestCommonCPUVal(t *testing.T) {
+	if val := commonCPUVal("cpu0:performance cpu1:performance"); val != "performance" {
+		t.Error(val)
+	}
+	if val := commonCPUVal("all:6"); val != "6" {
+		t.Error(val)
+	}
+	if val := commonCPUVal("cpu0:performance cpu1:powersave"); val != "" {
+		t.Error(val)
+	}
+	if val := commonCPUVal("70"); val != "70" {
+		t.Error(val)
+	}
+	if val := OptCPUVal("energy_perf_bias", "all:15", "4"); val != "all:4" {
+		t.Error(val)
+	}
+}
+
+func TestIsPerCPUKey(t *testing.T) {
+	for _, key := range []string{"energy_perf_bias", "governor", "energy_performance_preference", "scaling_min_freq", "scaling_max_freq"} {
+		if !isPerCPUKey(key) {
+			t.Errorf("'%s' should be a per CPU parameter", key)
+		}
+	}
+	if isPerCPUKey("force_latency") {
+		t.Error("'force_latency' is not a per CPU parameter")
+	}
+	if !isFreqLimitKey("scaling_min_freq") || isFreqLimitKey("governor") {
+		t.Error("wrong frequency limit parameter")
+	}
+}
+
+func TestPerCPUVal(t *testing.T) {
+	online := []string{"cpu0", "cpu1", "cpu2", "cpu3", "cpu5"}
+	vals, err := perCPUVal("cpu0-1,5:performance cpu2:powersave", online)
+	if err != nil {
+		t.Error(err)
+	}
+	if val := formatCPUVal(vals, online); val != "cpu0:performance cpu1:performance cpu2:powersave cpu5:performance" {
+		t.Error(val)
+	}
+	vals, err = perCPUVal("all:powersave cpu3-5:performance", online)
+	if err == nil || err.Error() != "CPUs '4' not online, skipping" {
+		t.Error(err)
+	}
+	if val := formatCPUVal(vals, online); val != "cpu0:powersave cpu1:powersave cpu2:powersave cpu3:performance cpu5:performance" {
+		t.Error(val)
+	}
+	vals, err = perCPUVal("all:6", online)
+	if err != nil {
+		t.Error(err)
+	}
+	if val := formatCPUVal(vals, online); val != "all:6" {
+		t.Error(val)
+	}
+	// wrong syntax
+	vals, err = perCPUVal("cpu0:6 6 cpuX:5 node1:4 cpu1:", online)
+	if err == nil || len(vals) != 1 {
+		t.Error(vals, err)
+	}
+}
+
+func TestCmpCPUVal(t *testing.T) {
+	online := system.OnlineCPUs()
+	if len(online) == 0 {
+		t.Skip("no online CPUs found")
+	}
+	perCPU := ""
+	for _, cpu := range online {
+		perCPU = perCPU + cpu + ":performance "
+	}
+	if !cmpCPUVal("all:performance", perCPU) || !cmpCPUVal(perCPU, "all:performance") {
+		t.Errorf("'all:performance' and '%s' should match", perCPU)
+	}
+	if cmpCPUVal("all:powersave", "cpu0:performance") {
+		t.Error("should not match")
+	}
+	if cmpCPUVal("all:none", "performance") {
+		t.Error("should not match")
+	}
+}
@@ -0,0 +1,71 @@
+package note
+
+import (
+	"github.com/SUSE/saptune/system"
+	"strings"
+)
+
+// section [cgroup]
+// Manipulate the resource control of systemd units by drop-in files
+
+// splitCgroupKey returns the property and the unit from a key of the
+// section [cgroup]
+// '<property>@<unit>' (e.g. 'CPUWeight@sap.slice')
+func splitCgroupKey(key string) (string, string) {
+	keyFields := strings.SplitN(key, "@", 2)
+	if len(keyFields) != 2 {
+		return keyFields[0], system.DefaultCgroupUnit
+	}
+	return keyFields[0], keyFields[1]
+}
+
+// GetCgroupVal initialise the cgroup structure with the current system
+// settings
+func GetCgroupVal(key string) string {
+	property, unit := splitCgroupKey(key)
+	if !system.IsCgroupProperty.MatchString(property) {
+		return "NA"
+	}
+	return system.GetCgroupProperty(unit, property)
+}
+
+// OptCgroupVal optimises the cgroup structure with the settings from the
+// configuration file
+func OptCgroupVal(key, cfgval string) string {
+	if cfgval == "" {
+		// untouched
+		return cfgval
+	}
+	property, _ := splitCgroupKey(key)
+	if !system.IsCgroupProperty.MatchString(property) {
+		system.WarningLog("unsupported resource control property '%s'. Skipping...", property)
+		return ""
+	}
+	val, err := system.NormCgroupValue(property, cfgval)
+	if err != nil {
+		system.WarningLog("wrong value '%s' for '%s' - %v. Skipping...", cfgval, key, err)
+		return ""
+	}
+	return val
+}
+
+// SetCgroupVal applies the settings to the system
+// returns true, if a drop-in file was changed and a daemon-reload is needed
+func SetCgroupVal(key, value string, revert bool) (bool, error) {
+	if value == "" {
+		// untouched
+		return false, nil
+	}
+	property, unit := splitCgroupKey(key)
+	if revert && IsLastNoteOfParameter(key) {
+		// revert - remove drop-in file
+		return system.RemoveCgroupDropIn(unit, property)
+	}
+	if value == "NA" {
+		return false, nil
+	}
+	// revert with value from another former applied note
+	// or
+	// apply
+	return system.SetCgroupDropIn(unit, property, value)
+}
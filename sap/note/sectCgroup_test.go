@@ -0,0 +1,47 @@
+package note
+
+import (
+	"testing"
+)
+
+func TestSplitCgroupKey(t *testing.T) {
+	property, unit := splitCgroupKey("MemoryLow@sapinit@HA1.service")
+	if property != "MemoryLow" || unit != "sapinit@HA1.service" {
+		t.Errorf("got '%s', '%s'", property, unit)
+	}
+	property, unit = splitCgroupKey("CPUWeight")
+	if property != "CPUWeight" || unit != "sap.slice" {
+		t.Errorf("got '%s', '%s'", property, unit)
+	}
+}
+
+func TestOptCgroupVal(t *testing.T) {
+	if val := OptCgroupVal("MemoryLow@sap.slice", "1G"); val != "1073741824" {
+		t.Error(val)
+	}
+	if val := OptCgroupVal("CPUWeight@sap.slice", "20000"); val != "" {
+		t.Error(val)
+	}
+	if val := OptCgroupVal("TasksMax@sap.slice", "100"); val != "" {
+		t.Error(val)
+	}
+	if val := OptCgroupVal("CPUWeight@sap.slice", ""); val != "" {
+		t.Error(val)
+	}
+}
+
+func TestCgroupVal(t *testing.T) {
+	if val := GetCgroupVal("TasksMax@sap.slice"); val != "NA" {
+		t.Error(val)
+	}
+	// untouched
+	changed, err := SetCgroupVal("CPUWeight@sap.slice", "", false)
+	if changed || err != nil {
+		t.Error(changed, err)
+	}
+	// revert of a not set property without drop-in file
+	changed, err = SetCgroupVal("CPUWeight@saptune_test.slice", "NA", true)
+	if changed || err != nil {
+		t.Error(changed, err)
+	}
+}
@@ -2,6 +2,7 @@ package note
 
 import (
 	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
 	"strings"
 )
 
@@ -13,12 +14,13 @@ func GetFSVal(key, cfgval string) (string, string) {
 	val := ""
 	info := ""
 	switch {
-	case system.IsXFSOption.MatchString(key):
+	case system.IsFSOption.MatchString(key):
 		// cfgval empty, prefix -, prefix +
 		if cfgval == "" {
 			// empty
 			return val, info
 		}
+		fstype, _, mntPts := system.ParseFSOptionKey(key)
 		// no prefix or prefix +
 		mustExist := true
 		if strings.HasPrefix(cfgval, "-") {
@@ -27,20 +29,20 @@ func GetFSVal(key, cfgval string) (string, string) {
 		}
 		opt := strings.TrimLeft(cfgval, "+-")
 		// Find out mount options
-		mountOk, mountNok := system.GetMountOpts(mustExist, "xfs", opt)
+		mountOk, mountNok := system.GetMountOptsOfMounts(mustExist, fstype, opt, mntPts)
 		if mustExist {
 			val = "+" + opt
 			if len(mountNok) != 0 {
 				// we have mount points missing the option
 				val = "-" + opt
-				info = "'" + opt + "' for FS type 'xfs' not explicit set on: " + strings.Join(mountNok, ", ")
+				info = "'" + opt + "' for FS type '" + fstype + "' not explicit set on: " + strings.Join(mountNok, ", ")
 			}
 		} else {
 			val = "-" + opt
 			if len(mountOk) != 0 {
 				// we have mount points containing the option
 				val = "+" + opt
-				info = "'" + opt + "' for FS type 'xfs' still explicit set on: " + strings.Join(mountOk, ", ")
+				info = "'" + opt + "' for FS type '" + fstype + "' still explicit set on: " + strings.Join(mountOk, ", ")
 			}
 		}
 		if len(mountOk) == 0 && len(mountNok) == 0 {
@@ -50,17 +52,152 @@ func GetFSVal(key, cfgval string) (string, string) {
 	return val, info
 }
 
+// GetFSStartVal returns the current state of the mount option for all
+// mounted file systems of the requested type as list of '+<mount point>'
+// (option set) and '-<mount point>' (option not set) entries or 'NA', if no
+// file system of the requested type is mounted.
+// Used as start value in the parameter saved state file to revert the
+// runtime changes
+func GetFSStartVal(key string) string {
+	fstype, opt, mntPts := system.ParseFSOptionKey(key)
+	states := []string{}
+	for _, mount := range system.ParseProcMounts().Filter(fstype, mntPts) {
+		if mount.HasOption(opt) {
+			states = append(states, "+"+mount.MountPoint)
+		} else {
+			states = append(states, "-"+mount.MountPoint)
+		}
+	}
+	if len(states) == 0 {
+		return "NA"
+	}
+	return strings.Join(states, " ")
+}
+
+// isFSStartVal checks, if the value is a start value from GetFSStartVal
+func isFSStartVal(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, state := range strings.Fields(value) {
+		if !strings.HasPrefix(state, "+/") && !strings.HasPrefix(state, "-/") {
+			return false
+		}
+	}
+	return true
+}
+
+// nfsNoRemountOpts contains the nfs mount options without a value, which
+// can not be changed by a remount (transport protocol and NFS version)
+var nfsNoRemountOpts = map[string]bool{"tcp": true, "udp": true, "rdma": true, "v2": true, "v3": true, "v4": true, "v4.0": true, "v4.1": true, "v4.2": true}
+
+// IsCheckOnlyFSOption returns true, if the mount option of the key can not
+// be changed by a remount. This applies to the nfs mount options with a
+// value (e.g. 'vers=4.1', 'rsize=1048576' or 'timeo=600') and to the
+// transport protocol and NFS version options.
+// These options are only checked, but NOT set.
+func IsCheckOnlyFSOption(key string) bool {
+	if !system.IsFSOption.MatchString(key) {
+		return false
+	}
+	fstype, opt, _ := system.ParseFSOptionKey(key)
+	return (fstype == "nfs" || fstype == "nfs4") && (strings.Contains(opt, "=") || nfsNoRemountOpts[opt])
+}
+
 // OptFSVal returns the value from the configuration file
 func OptFSVal(key, cfgval string) string {
-	// nothing to do, only checking for 'verify'
 	if cfgval != "" && !strings.HasPrefix(cfgval, "-") && !strings.HasPrefix(cfgval, "+") {
 		cfgval = "+" + cfgval
 	}
 	return cfgval
 }
 
-// SetFSVal nothing to do, only checking for 'verify'
-func SetFSVal(key, value string) error {
-	// nothing to do, only checking for 'verify'
-	return nil
+// SetFSVal applies the mount option to all mounted file systems of the
+// requested type (or only to the mount points from the key) by remounting
+// them, if the option is not already set (prefix '+') or not already removed
+// (prefix '-').
+// If 'PERSIST_FS_OPTIONS' is set to 'yes' in the saptune configuration file
+// the mount option is changed in /etc/fstab too.
+// During revert a start value (see GetFSStartVal) restores the former
+// runtime state. The changes in /etc/fstab are only reverted during a
+// permanent revert, if no other Note still needs the mount option.
+// Mount options, which can not be changed by a remount (see
+// IsCheckOnlyFSOption), are neither remounted nor changed in /etc/fstab.
+func SetFSVal(key, value string, revert, permanent bool) error {
+	var err error
+	if !system.IsFSOption.MatchString(key) {
+		// 'untouched'
+		return nil
+	}
+	fstype, opt, mntPts := system.ParseFSOptionKey(key)
+	if IsCheckOnlyFSOption(key) {
+		if !revert && value != "" && value != "NA" {
+			system.WarningLog("mount option '%s' of file system type '%s' can not be changed by a remount, the option is only checked, but NOT set.", opt, fstype)
+		}
+		return nil
+	}
+	switch {
+	case value == "" || value == "NA":
+		// untouched or no file system available
+	case isFSStartVal(value):
+		err = revertMountOpts(fstype, opt, value)
+	default:
+		set := !strings.HasPrefix(value, "-")
+		err = setMountOpts(fstype, opt, set, mntPts)
+		if err == nil && persistFSOptions() && (!revert || permanent) {
+			err = system.SetFstabOption(fstype, opt, set, mntPts)
+		}
+	}
+	if revert && permanent && IsLastNoteOfParameter(key) {
+		// no more notes using this mount option, so restore the
+		// former content of /etc/fstab
+		if rerr := system.RestoreFstabOption(fstype, opt); rerr != nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// setMountOpts adds or removes a mount option from all mounted file systems
+// of the requested type (or only from the listed mount points)
+func setMountOpts(fstype, opt string, set bool, mntPts []string) error {
+	var err error
+	for _, mount := range system.ParseProcMounts().Filter(fstype, mntPts) {
+		if mount.HasOption(opt) == set {
+			continue
+		}
+		if rerr := system.RemountOption(mount.MountPoint, opt, set); rerr != nil {
+			err = system.ErrorLog("%v", rerr)
+		}
+	}
+	return err
+}
+
+// revertMountOpts restores the state of a mount option on the mounted file
+// systems as recorded in the start value
+func revertMountOpts(fstype, opt, startVal string) error {
+	var err error
+	mounts := system.ParseProcMounts()
+	for _, state := range strings.Fields(startVal) {
+		set := strings.HasPrefix(state, "+")
+		mount, ok := mounts.GetByMountPoint(state[1:])
+		if !ok || !system.IsFSType(fstype, mount.Type) || mount.HasOption(opt) == set {
+			// no longer mounted or nothing to do
+			continue
+		}
+		if rerr := system.RemountOption(mount.MountPoint, opt, set); rerr != nil {
+			err = system.ErrorLog("%v", rerr)
+		}
+	}
+	return err
+}
+
+// persistFSOptions returns true, if 'PERSIST_FS_OPTIONS' is set to 'yes' in
+// the saptune configuration file
+func persistFSOptions() bool {
+	sconf, err := txtparser.ParseSysconfigFile(system.SaptuneConfigFile(), false)
+	if err != nil {
+		return false
+	}
+	return sconf.GetString("PERSIST_FS_OPTIONS", "no") == "yes"
 }
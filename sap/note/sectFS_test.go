@@ -0,0 +1,78 @@
+package note
+
+import (
+	"testing"
+)
+
+func TestOptFSVal(t *testing.T) {
+	if val := OptFSVal("xfsopt_nobarrier", "nobarrier"); val != "+nobarrier" {
+		t.Error(val)
+	}
+	if val := OptFSVal("xfsopt_nobarrier", "-nobarrier"); val != "-nobarrier" {
+		t.Error(val)
+	}
+	if val := OptFSVal("xfsopt_*", ""); val != "" {
+		t.Error(val)
+	}
+	// nfs mount options, which can not be changed by a remount, keep
+	// the value as expected value for the check
+	if val := OptFSVal("nfs4opt_vers=4.1", "vers=4.1"); val != "+vers=4.1" {
+		t.Error(val)
+	}
+	if val := OptFSVal("nfsopt_hard", "hard"); val != "+hard" {
+		t.Error(val)
+	}
+	if val := OptFSVal("nfs4opt_noatime@/hana/shared", "-noatime"); val != "-noatime" {
+		t.Error(val)
+	}
+	// options with a value of other file systems
+	if val := OptFSVal("xfsopt_logbsize=256k", "logbsize=256k"); val != "+logbsize=256k" {
+		t.Error(val)
+	}
+}
+
+func TestIsCheckOnlyFSOption(t *testing.T) {
+	for _, key := range []string{"nfsopt_vers=4.1", "nfs4opt_rsize=1048576@/hana/shared", "nfsopt_proto=tcp", "nfsopt_udp", "nfs4opt_v3", "nfsopt_timeo=600"} {
+		if !IsCheckOnlyFSOption(key) {
+			t.Errorf("'%s' should be a check-only option", key)
+		}
+	}
+	for _, key := range []string{"nfsopt_hard", "nfs4opt_noatime@/hana/shared", "xfsopt_logbsize=256k", "xfsopt_nobarrier", "THP"} {
+		if IsCheckOnlyFSOption(key) {
+			t.Errorf("'%s' should not be a check-only option", key)
+		}
+	}
+}
+
+func TestIsFSStartVal(t *testing.T) {
+	if !isFSStartVal("+/hana/data -/hana/log") {
+		t.Error("'+/hana/data -/hana/log' should be a start value")
+	}
+	if isFSStartVal("+noatime") || isFSStartVal("-noatime") || isFSStartVal("") {
+		t.Error("note values should not be start values")
+	}
+}
+
+func TestSetFSVal(t *testing.T) {
+	if err := SetFSVal("xfsopt_*", "", false, false); err != nil {
+		t.Error(err)
+	}
+	if err := SetFSVal("xfsopt_nobarrier", "NA", false, false); err != nil {
+		t.Error(err)
+	}
+	// check-only options are never remounted
+	if err := SetFSVal("nfs4opt_vers=4.1", "+vers=4.1", false, false); err != nil {
+		t.Error(err)
+	}
+	// start value with not mounted file systems, nothing to revert
+	if err := SetFSVal("xfsopt_nobarrier", "+/saptune_not_mounted -/saptune_not_mounted2", true, false); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetFSStartVal(t *testing.T) {
+	val := GetFSStartVal("xfsopt_nobarrier")
+	if val != "NA" && !isFSStartVal(val) {
+		t.Error(val)
+	}
+}
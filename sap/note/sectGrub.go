@@ -14,14 +14,48 @@ func GetGrubVal(key string) string {
 	return val
 }
 
+// GetGrubStartVal returns the value of the boot option as configured in the
+// boot loader before saptune changed it for the first time.
+// Used as start value in the parameter saved state file, because the value
+// from the running kernel may differ from the boot loader configuration
+func GetGrubStartVal(key string) string {
+	keyFields := strings.Split(key, ":")
+	return system.GetGrubBackupValue(keyFields[1])
+}
+
 // OptGrubVal returns the value from the configuration file
 func OptGrubVal(key, cfgval string) string {
-	// nothing to do, only checking for 'verify'
 	return cfgval
 }
 
-// SetGrubVal nothing to do, only checking for 'verify'
-func SetGrubVal(value string) error {
-	// nothing to do, only checking for 'verify'
-	return nil
+// SetGrubVal writes the boot option to the boot loader configuration
+// returns true, if the boot loader configuration was changed and needs to
+// be regenerated
+func SetGrubVal(key, value string) (bool, error) {
+	keyFields := strings.Split(key, ":")
+	if value == "" {
+		// untouched
+		return false, nil
+	}
+	return system.SetBootCmdlineOption(keyFields[1], value)
+}
+
+// RevertGrubVal writes the former boot option value back to the boot loader
+// configuration.
+// The value in the boot loader configuration is only reverted during a
+// permanent revert (e.g. 'saptune note revert'), because the saptune service
+// reverts all notes during system shutdown and the new boot option has to
+// survive the reboot.
+func RevertGrubVal(key, value string, permanent bool) (bool, error) {
+	if !permanent {
+		return false, nil
+	}
+	changed, err := SetGrubVal(key, value)
+	if IsLastNoteOfParameter(key) {
+		// no more notes using this boot option, so forget the
+		// backup value
+		keyFields := strings.Split(key, ":")
+		system.RemoveGrubBackupValue(keyFields[1])
+	}
+	return changed, err
 }
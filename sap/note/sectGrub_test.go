@@ -1,6 +1,9 @@
 package note
 
 import (
+	"github.com/SUSE/saptune/system"
+	"os"
+	"path"
 	"testing"
 )
 
@@ -23,8 +26,68 @@ func TestOptGrubVal(t *testing.T) {
 }
 
 func TestSetGrubVal(t *testing.T) {
-	val := SetGrubVal("NO_OPT")
-	if val != nil {
-		t.Error(val)
+	// never touch the boot loader configuration of the test system
+	tmpDir := "/tmp/saptune_test_grub"
+	grubFile := path.Join(tmpDir, "default_grub")
+	oldGrubFile := system.GrubDefaultFile
+	oldBLSDir := system.BLSEntriesDir
+	oldCmdlineFile := system.KernelCmdlineFile
+	oldPendingFile := system.GrubPendingFile
+	oldBackupFile := system.GrubBackupFile
+	defer func() {
+		system.GrubDefaultFile = oldGrubFile
+		system.BLSEntriesDir = oldBLSDir
+		system.KernelCmdlineFile = oldCmdlineFile
+		system.GrubPendingFile = oldPendingFile
+		system.GrubBackupFile = oldBackupFile
+	}()
+	system.GrubDefaultFile = grubFile
+	system.BLSEntriesDir = path.Join(tmpDir, "entries")
+	system.KernelCmdlineFile = path.Join(tmpDir, "cmdline")
+	system.GrubPendingFile = path.Join(tmpDir, ".grubpending")
+	system.GrubBackupFile = path.Join(tmpDir, ".grubbackup")
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := os.WriteFile(grubFile, []byte("GRUB_TIMEOUT=8\nGRUB_CMDLINE_LINUX_DEFAULT=\"splash=silent quiet\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// untouched
+	changed, err := SetGrubVal("grub:processor.max_cstate", "")
+	if changed || err != nil {
+		t.Error(changed, err)
+	}
+	changed, err = SetGrubVal("grub:processor.max_cstate", "1")
+	if !changed || err != nil {
+		t.Error(changed, err)
+	}
+	if val := system.GetBootCmdlineOption("processor.max_cstate"); val != "1" {
+		t.Errorf("expected '1', got '%s'", val)
+	}
+	// nothing to change
+	changed, err = SetGrubVal("grub:processor.max_cstate", "1")
+	if changed || err != nil {
+		t.Error(changed, err)
+	}
+	// non permanent revert leaves boot loader configuration untouched
+	changed, err = RevertGrubVal("grub:processor.max_cstate", "NA", false)
+	if changed || err != nil {
+		t.Error(changed, err)
+	}
+	if val := system.GetBootCmdlineOption("processor.max_cstate"); val != "1" {
+		t.Errorf("expected '1', got '%s'", val)
+	}
+	changed, err = RevertGrubVal("grub:processor.max_cstate", "NA", true)
+	if !changed || err != nil {
+		t.Error(changed, err)
+	}
+	if val := system.GetBootCmdlineOption("processor.max_cstate"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	// boot loader configuration matches the running kernel again
+	if pending := system.BootOptionsPending(); len(pending) != 0 {
+		t.Errorf("no pending boot options expected, got '%+v'", pending)
 	}
 }
@@ -0,0 +1,89 @@
+package note
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"strconv"
+	"strings"
+)
+
+// section [hugepages]
+// Manipulate the static hugepage pools in /sys/kernel/mm/hugepages and
+// /sys/devices/system/node/node*/hugepages
+
+// splitHugepagesKey returns the pool parameter, the NUMA node and the
+// page size from a key of the section [hugepages]
+// 'nr_hugepages', 'nr_hugepages.node1', 'nr_hugepages.1048576kB' or
+// 'nr_hugepages.node1.1048576kB'
+// an empty node means the global pool, an empty size the default page size
+func splitHugepagesKey(key string) (string, string, string) {
+	node := ""
+	size := ""
+	keyFields := strings.Split(key, ".")
+	for _, field := range keyFields[1:] {
+		switch {
+		case strings.HasPrefix(field, "node"):
+			node = field
+		case system.IsHugepageSize.MatchString(field):
+			size = field
+		default:
+			system.WarningLog("unknown field '%s' in hugepages parameter '%s'", field, key)
+		}
+	}
+	return keyFields[0], node, size
+}
+
+// hugepageSize returns the page size or the default page size of the system
+func hugepageSize(size string) string {
+	if size == "" {
+		return system.DefaultHugepageSize()
+	}
+	return size
+}
+
+// GetHugepagesVal initialise the hugepages structure with the current
+// system settings
+func GetHugepagesVal(key string) string {
+	param, node, size := splitHugepagesKey(key)
+	return system.GetHugepages(node, hugepageSize(size), param)
+}
+
+// OptHugepagesVal optimises the hugepages structure with the settings from
+// the configuration file
+// returns info about not allocated hugepages, if the value was already
+// applied, but the kernel was not able to allocate all requested pages
+// (e.g. because of memory fragmentation)
+func OptHugepagesVal(key, actval, cfgval string) (string, string) {
+	info := ""
+	if cfgval == "" {
+		// untouched
+		return cfgval, info
+	}
+	reqPages, err := strconv.Atoi(strings.TrimSpace(cfgval))
+	if err != nil || reqPages < 0 {
+		system.WarningLog("wrong number of hugepages '%s' for '%s'. Skipping...", cfgval, key)
+		return "", info
+	}
+	param, node, size := splitHugepagesKey(key)
+	actPages, err := strconv.Atoi(actval)
+	if err == nil && param == "nr_hugepages" && actPages < reqPages && !IsLastNoteOfParameter(key) {
+		// value already applied, but not all pages allocated
+		size = hugepageSize(size)
+		if node == "" {
+			info = fmt.Sprintf("%d of %d pages allocated (%s)", actPages, reqPages, system.HugepagesPerNode(size))
+		} else {
+			info = fmt.Sprintf("%d of %d pages allocated on %s", actPages, reqPages, node)
+		}
+	}
+	return strconv.Itoa(reqPages), info
+}
+
+// SetHugepagesVal applies the settings to the system
+func SetHugepagesVal(key, value string) error {
+	if value == "" {
+		// untouched
+		return nil
+	}
+	param, node, size := splitHugepagesKey(key)
+	return system.SetHugepages(node, hugepageSize(size), param, value)
+}
@@ -0,0 +1,54 @@
+package note
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSplitHugepagesKey(t *testing.T) {
+	param, node, size := splitHugepagesKey("nr_hugepages")
+	if param != "nr_hugepages" || node != "" || size != "" {
+		t.Errorf("got '%s', '%s', '%s'", param, node, size)
+	}
+	param, node, size = splitHugepagesKey("nr_hugepages.node1.1048576kB")
+	if param != "nr_hugepages" || node != "node1" || size != "1048576kB" {
+		t.Errorf("got '%s', '%s', '%s'", param, node, size)
+	}
+	param, node, size = splitHugepagesKey("nr_overcommit_hugepages.2048kB")
+	if param != "nr_overcommit_hugepages" || node != "" || size != "2048kB" {
+		t.Errorf("got '%s', '%s', '%s'", param, node, size)
+	}
+}
+
+func TestOptHugepagesVal(t *testing.T) {
+	val, info := OptHugepagesVal("nr_hugepages", "0", "")
+	if val != "" || info != "" {
+		t.Errorf("got '%s', '%s'", val, info)
+	}
+	val, info = OptHugepagesVal("nr_hugepages", "0", "many")
+	if val != "" || info != "" {
+		t.Errorf("got '%s', '%s'", val, info)
+	}
+	// no parameter state file, so not yet applied
+	val, info = OptHugepagesVal("nr_hugepages.node0", "100", " 1024")
+	if val != "1024" || info != "" {
+		t.Errorf("got '%s', '%s'", val, info)
+	}
+	CreateParameterStartValues("nr_hugepages.node0", "0")
+	defer os.Remove(GetPathToParameter("nr_hugepages.node0"))
+	val, info = OptHugepagesVal("nr_hugepages.node0", "100", "1024")
+	if val != "1024" || info != "100 of 1024 pages allocated on node0" {
+		t.Errorf("got '%s', '%s'", val, info)
+	}
+	val, info = OptHugepagesVal("nr_hugepages.node0", "1024", "1024")
+	if val != "1024" || info != "" {
+		t.Errorf("got '%s', '%s'", val, info)
+	}
+}
+
+func TestGetHugepagesVal(t *testing.T) {
+	val := GetHugepagesVal("nr_hugepages.node0.3kB")
+	if val != "NA" {
+		t.Error(val)
+	}
+}
@@ -0,0 +1,100 @@
+package note
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"strings"
+)
+
+// section [irq]
+
+// splitIRQKey returns the interrupt name pattern and the scope pattern
+// (from the section tag 'irqpat') from a key of the section [irq]
+// 'irq:<pattern>' or 'irq:<pattern>@<scope>'
+func splitIRQKey(key string) (string, string) {
+	irqKey := key
+	keyFields := strings.SplitN(key, ":", 2)
+	if len(keyFields) == 2 {
+		// keyFields[0] = irq
+		irqKey = keyFields[1]
+	}
+	patFields := strings.SplitN(irqKey, "@", 2)
+	if len(patFields) == 2 {
+		return patFields[0], patFields[1]
+	}
+	return patFields[0], ""
+}
+
+// GetIRQVal initialise the irq structure with the current system settings
+// For an interrupt name pattern the affinity of all matching interrupts is
+// returned as '<irq>:<cpulist> <irq>:<cpulist>...' or 'NA', if no interrupt
+// matches
+func GetIRQVal(key string) string {
+	pattern, scope := splitIRQKey(key)
+	if pattern == system.IRQBalanceBannedCPUs {
+		return system.GetIRQBalanceBannedCPUs()
+	}
+	val := ""
+	for _, irq := range system.GetIRQsByPattern(pattern, scope) {
+		val = val + fmt.Sprintf("%s:%s ", irq, system.GetIRQAffinity(irq))
+	}
+	if val == "" {
+		return "NA"
+	}
+	return strings.TrimSpace(val)
+}
+
+// OptIRQVal optimises the irq structure with the settings from the
+// configuration file
+func OptIRQVal(key, cfgval string) string {
+	if cfgval == "" {
+		// untouched
+		return cfgval
+	}
+	pattern, scope := splitIRQKey(key)
+	cpus, err := system.ParseCPUList(cfgval)
+	if err != nil {
+		system.WarningLog("wrong CPU list '%s' for '%s'. Skipping... - %v", cfgval, pattern, err)
+		return ""
+	}
+	cpuList := system.FormatCPUList(cpus)
+	if pattern == system.IRQBalanceBannedCPUs {
+		return cpuList
+	}
+	val := ""
+	for _, irq := range system.GetIRQsByPattern(pattern, scope) {
+		val = val + fmt.Sprintf("%s:%s ", irq, cpuList)
+	}
+	if val == "" {
+		return "NA"
+	}
+	return strings.TrimSpace(val)
+}
+
+// SetIRQVal applies the settings to the system
+func SetIRQVal(key, value string) error {
+	if value == "" {
+		// untouched
+		return nil
+	}
+	pattern, _ := splitIRQKey(key)
+	if pattern == system.IRQBalanceBannedCPUs {
+		return system.SetIRQBalanceBannedCPUs(value)
+	}
+	// 'NA' - no matching interrupt
+	// a failure does not stop the setting of the remaining interrupts
+	errs := make([]error, 0)
+	for _, entry := range strings.Fields(value) {
+		fields := strings.SplitN(entry, ":", 2)
+		if len(fields) != 2 || fields[1] == "NA" {
+			continue
+		}
+		if err := system.SetIRQAffinity(fields[0], fields[1]); err != nil {
+			errs = append(errs, fmt.Errorf("interrupt '%s' - %v", fields[0], err))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to set the CPU affinity of one or more interrupts: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package note
+
+import (
+	"testing"
+)
+
+func TestSplitIRQKey(t *testing.T) {
+	pattern, scope := splitIRQKey("irq:mlx5_comp@pci:0000:3b")
+	if pattern != "mlx5_comp" || scope != "pci:0000:3b" {
+		t.Errorf("got '%s', '%s'", pattern, scope)
+	}
+	pattern, scope = splitIRQKey("irq:nvme")
+	if pattern != "nvme" || scope != "" {
+		t.Errorf("got '%s', '%s'", pattern, scope)
+	}
+}
+
+func TestGetIRQVal(t *testing.T) {
+	val := GetIRQVal("irq:saptune_unknown_irq")
+	if val != "NA" {
+		t.Error(val)
+	}
+}
+
+func TestOptIRQVal(t *testing.T) {
+	if val := OptIRQVal("irq:IRQBALANCE_BANNED_CPULIST", "3,0-2"); val != "0-3" {
+		t.Error(val)
+	}
+	if val := OptIRQVal("irq:IRQBALANCE_BANNED_CPULIST", "first"); val != "" {
+		t.Error(val)
+	}
+	if val := OptIRQVal("irq:saptune_unknown_irq", ""); val != "" {
+		t.Error(val)
+	}
+	if val := OptIRQVal("irq:saptune_unknown_irq", "0-3"); val != "NA" {
+		t.Error(val)
+	}
+}
+
+func TestSetIRQVal(t *testing.T) {
+	// untouched
+	if err := SetIRQVal("irq:nvme", ""); err != nil {
+		t.Error(err)
+	}
+	// no matching interrupt
+	if err := SetIRQVal("irq:saptune_unknown_irq", "NA"); err != nil {
+		t.Error(err)
+	}
+}
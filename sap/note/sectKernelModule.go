@@ -0,0 +1,77 @@
+package note
+
+import (
+	"github.com/SUSE/saptune/system"
+	"strings"
+)
+
+// section [kernelmodule]
+
+// splitKmodKey returns the module name and the module parameter name
+// from a key of the section [kernelmodule]
+// 'kmod:<module>' or 'kmod:<module>.<parameter>'
+func splitKmodKey(key string) (string, string) {
+	kmodKey := key
+	keyFields := strings.SplitN(key, ":", 2)
+	if len(keyFields) == 2 {
+		// keyFields[0] = kmod
+		kmodKey = keyFields[1]
+	}
+	modFields := strings.SplitN(kmodKey, ".", 2)
+	if len(modFields) == 2 {
+		return modFields[0], modFields[1]
+	}
+	return modFields[0], ""
+}
+
+// GetKernelModuleVal initialise the kernel module structure with the current
+// system settings
+func GetKernelModuleVal(key string) string {
+	module, param := splitKmodKey(key)
+	if param == "" {
+		return system.GetModuleState(module)
+	}
+	return system.GetModuleParam(module, param)
+}
+
+// OptKernelModuleVal optimises the kernel module structure with the settings
+// from the configuration file
+func OptKernelModuleVal(key, cfgval string) string {
+	module, param := splitKmodKey(key)
+	if param != "" || cfgval == "" {
+		return cfgval
+	}
+	sval := strings.ToLower(strings.TrimSpace(cfgval))
+	if sval != "load" && sval != "unload" && sval != "blacklist" {
+		system.WarningLog("wrong kernel module state '%s' for '%s'. Skipping...\n", cfgval, module)
+		return ""
+	}
+	return sval
+}
+
+// SetKernelModuleVal applies the settings to the system
+func SetKernelModuleVal(key, value string, revert bool) error {
+	if value == "" {
+		// untouched
+		return nil
+	}
+	module, param := splitKmodKey(key)
+	if revert && IsLastNoteOfParameter(key) {
+		// revert - remove the saptune settings from the drop-in
+		// files and restore the start value
+		if param == "" {
+			return system.ResetModuleState(module, value)
+		}
+		return system.ResetModuleParam(module, param, value)
+	}
+	// revert with value from another former applied note
+	// or
+	// apply
+	if param == "" {
+		return system.SetModuleState(module, value)
+	}
+	if value == "NA" {
+		return nil
+	}
+	return system.SetModuleParam(module, param, value)
+}
@@ -0,0 +1,53 @@
+package note
+
+import (
+	"testing"
+)
+
+func TestSplitKmodKey(t *testing.T) {
+	module, param := splitKmodKey("kmod:nvme_core.io_timeout")
+	if module != "nvme_core" || param != "io_timeout" {
+		t.Errorf("got '%s', '%s'", module, param)
+	}
+	module, param = splitKmodKey("kmod:floppy")
+	if module != "floppy" || param != "" {
+		t.Errorf("got '%s', '%s'", module, param)
+	}
+}
+
+func TestGetKernelModuleVal(t *testing.T) {
+	val := GetKernelModuleVal("kmod:saptune_unknown_module")
+	if val != "unload" && val != "blacklist" {
+		t.Error(val)
+	}
+	val = GetKernelModuleVal("kmod:saptune_unknown_module.param")
+	if val != "NA" {
+		t.Error(val)
+	}
+}
+
+func TestOptKernelModuleVal(t *testing.T) {
+	if val := OptKernelModuleVal("kmod:floppy", " Blacklist"); val != "blacklist" {
+		t.Error(val)
+	}
+	if val := OptKernelModuleVal("kmod:floppy", "start"); val != "" {
+		t.Error(val)
+	}
+	if val := OptKernelModuleVal("kmod:floppy", ""); val != "" {
+		t.Error(val)
+	}
+	if val := OptKernelModuleVal("kmod:nvme_core.io_timeout", "4294967295"); val != "4294967295" {
+		t.Error(val)
+	}
+}
+
+func TestSetKernelModuleVal(t *testing.T) {
+	// untouched
+	if err := SetKernelModuleVal("kmod:floppy", "", false); err != nil {
+		t.Error(err)
+	}
+	// parameter of a module, which is not available
+	if err := SetKernelModuleVal("kmod:nvme_core.io_timeout", "NA", false); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,69 @@
+package note
+
+import (
+	"github.com/SUSE/saptune/system"
+	"strconv"
+	"strings"
+)
+
+// section [net]
+// Manipulate network interface settings like MTU, ring sizes, channels
+// and offloads
+
+// splitNetKey returns the parameter name and the network interface from a
+// key of the section [net]
+// '<parameter>@<interface>' (e.g. 'ring_rx@eth0')
+func splitNetKey(key string) (string, string) {
+	keyFields := strings.SplitN(key, "@", 2)
+	if len(keyFields) != 2 {
+		return keyFields[0], ""
+	}
+	return keyFields[0], keyFields[1]
+}
+
+// GetNetVal initialise the network structure with the current system
+// settings
+func GetNetVal(key string) string {
+	param, iface := splitNetKey(key)
+	if iface == "" || !system.IsNetParam.MatchString(param) {
+		return "NA"
+	}
+	return system.GetNetParam(iface, param)
+}
+
+// OptNetVal optimises the network structure with the settings from the
+// configuration file
+func OptNetVal(key, cfgval string) string {
+	if cfgval == "" {
+		// untouched
+		return cfgval
+	}
+	param, _ := splitNetKey(key)
+	if !system.IsNetParam.MatchString(param) {
+		system.WarningLog("unsupported network parameter '%s'. Skipping...", param)
+		return ""
+	}
+	val := strings.ToLower(strings.TrimSpace(cfgval))
+	if strings.HasPrefix(param, "offload_") {
+		if val != "on" && val != "off" {
+			system.WarningLog("wrong value '%s' for '%s', only 'on' or 'off' supported. Skipping...", cfgval, key)
+			return ""
+		}
+		return val
+	}
+	if _, err := strconv.ParseUint(val, 10, 32); err != nil {
+		system.WarningLog("wrong value '%s' for '%s', needs to be a number. Skipping...", cfgval, key)
+		return ""
+	}
+	return val
+}
+
+// SetNetVal applies the settings to the system
+func SetNetVal(key, value string) error {
+	if value == "" {
+		// untouched
+		return nil
+	}
+	param, iface := splitNetKey(key)
+	return system.SetNetParam(iface, param, value)
+}
@@ -0,0 +1,57 @@
+package note
+
+import (
+	"testing"
+)
+
+func TestSplitNetKey(t *testing.T) {
+	param, iface := splitNetKey("ring_rx-jumbo@eth0.100")
+	if param != "ring_rx-jumbo" || iface != "eth0.100" {
+		t.Errorf("got '%s', '%s'", param, iface)
+	}
+	param, iface = splitNetKey("MTU")
+	if param != "MTU" || iface != "" {
+		t.Errorf("got '%s', '%s'", param, iface)
+	}
+}
+
+func TestGetNetVal(t *testing.T) {
+	if val := GetNetVal("MTU@saptune_unknown_if"); val != "NA" {
+		t.Error(val)
+	}
+	if val := GetNetVal("speed@eth0"); val != "NA" {
+		t.Error(val)
+	}
+}
+
+func TestOptNetVal(t *testing.T) {
+	if val := OptNetVal("ring_rx@eth0", "4096"); val != "4096" {
+		t.Error(val)
+	}
+	if val := OptNetVal("ring_rx@eth0", "max"); val != "" {
+		t.Error(val)
+	}
+	if val := OptNetVal("offload_gro@eth0", "OFF"); val != "off" {
+		t.Error(val)
+	}
+	if val := OptNetVal("offload_gro@eth0", "1"); val != "" {
+		t.Error(val)
+	}
+	if val := OptNetVal("speed@eth0", "1000"); val != "" {
+		t.Error(val)
+	}
+	if val := OptNetVal("MTU@eth0", ""); val != "" {
+		t.Error(val)
+	}
+}
+
+func TestSetNetVal(t *testing.T) {
+	// untouched
+	if err := SetNetVal("MTU@eth0", ""); err != nil {
+		t.Error(err)
+	}
+	// not available
+	if err := SetNetVal("MTU@saptune_unknown_if", "NA"); err != nil {
+		t.Error(err)
+	}
+}
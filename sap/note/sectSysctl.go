@@ -3,13 +3,65 @@ package note
 import (
 	"github.com/SUSE/saptune/system"
 	"github.com/SUSE/saptune/txtparser"
+	"regexp"
 	"strings"
 )
 
 // section [sysctl]
 
+// isFieldOperator matches a field of a multi value sysctl parameter with
+// its own operator (e.g. '>=1250') or the placeholder '*' for 'keep the
+// current value'
+var isFieldOperator = regexp.MustCompile(`^([<>]=?|=)(.+)$|^\*$`)
+
+// hasFieldOperators returns true, if at least one field of the value from
+// the config file uses a per-field operator or the placeholder '*'
+// (e.g. 'kernel.sem = >=1250 * * >=1024')
+func hasFieldOperators(fields []string) bool {
+	for _, field := range fields {
+		if isFieldOperator.MatchString(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// optSysctlFields optimises the fields of a multi value sysctl parameter
+// with per-field operators. Each field is evaluated separately against the
+// current value of the field, so a field, which already satisfies its
+// operator, is not changed
+func optSysctlFields(key string, allFieldsC, allFieldsE []string) string {
+	if len(allFieldsC) != len(allFieldsE) {
+		system.WarningLog("wrong number of fields given in the config file for parameter '%s'\n", key)
+		return ""
+	}
+	allFieldsS := []string{}
+	for k, fieldE := range allFieldsE {
+		fieldOp := isFieldOperator.FindStringSubmatch(fieldE)
+		switch {
+		case fieldOp == nil || fieldOp[1] == "=":
+			if fieldOp != nil {
+				fieldE = fieldOp[2]
+			}
+		case fieldOp[0] == "*":
+			// keep the current value
+			fieldE = allFieldsC[k]
+		default:
+			optimisedValue, err := txtparser.CalculateOptimumValue(txtparser.Operator(fieldOp[1]), allFieldsC[k], fieldOp[2])
+			if err != nil {
+				system.WarningLog("wrong value '%s' in field %d of parameter '%s'. Skipping...", fieldE, k+1, key)
+				return ""
+			}
+			fieldE = optimisedValue
+		}
+		allFieldsS = append(allFieldsS, fieldE)
+	}
+	return strings.Join(allFieldsS, "\t")
+}
+
 // OptSysctlVal optimises a sysctl parameter value
 // use exactly the value from the config file. No calculation any more
+// except for multi value parameters with per-field operators
 func OptSysctlVal(operator txtparser.Operator, key, actval, cfgval string) string {
 	if actval == "PNA" || actval == "" {
 		// sysctl parameter not available in system
@@ -24,6 +76,10 @@ func OptSysctlVal(operator txtparser.Operator, key, actval, cfgval string) strin
 	allFieldsE := strings.Fields(cfgval)
 	allFieldsS := ""
 
+	if hasFieldOperators(allFieldsE) {
+		return optSysctlFields(key, allFieldsC, allFieldsE)
+	}
+
 	if len(allFieldsC) != len(allFieldsE) && (operator == txtparser.OperatorEqual || len(allFieldsE) > 1) {
 		system.WarningLog("wrong number of fields given in the config file for parameter '%s'\n", key)
 		return ""
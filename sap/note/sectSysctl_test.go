@@ -68,3 +68,34 @@ func TestOptSysctlVal(t *testing.T) {
 		t.Error(val)
 	}
 }
+
+func TestOptSysctlValFieldOperators(t *testing.T) {
+	op := txtparser.Operator("=")
+	// larger SEMMSL and SEMMNI are kept
+	val := OptSysctlVal(op, "kernel.sem", "32000	1024000000	500	32000", ">=1250	*	*	>=1024")
+	if val != "32000	1024000000	500	32000" {
+		t.Error(val)
+	}
+	// smaller values are raised
+	val = OptSysctlVal(op, "kernel.sem", "250	32000	32	128", ">=1250	*	=100	>=1024")
+	if val != "1250	32000	100	1024" {
+		t.Error(val)
+	}
+	val = OptSysctlVal(op, "kernel.sem", "250	32000	32	128", "<=200	<100	>32	4096")
+	if val != "200	99	33	4096" {
+		t.Error(val)
+	}
+	// wrong number of fields
+	val = OptSysctlVal(op, "kernel.sem", "250	32000	32	128", ">=1250	*	*")
+	if val != "" {
+		t.Error(val)
+	}
+	// wrong value
+	val = OptSysctlVal(op, "kernel.sem", "250	32000	32	128", ">=abc	*	*	*")
+	if val != "" {
+		t.Error(val)
+	}
+	if hasFieldOperators([]string{"1250", "256000", "32", "1024"}) {
+		t.Error("wrongly detected per-field operators")
+	}
+}
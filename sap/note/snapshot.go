@@ -0,0 +1,420 @@
+package note
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/SUSE/saptune/sap/param"
+	"github.com/SUSE/saptune/system"
+	"github.com/SUSE/saptune/txtparser"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshots of the system tuning
+// A snapshot captures the current system values of all parameters known by
+// the available Note definitions. The snapshots are stored in a directory
+// surviving a reboot and do not depend on the parameter state files, so a
+// snapshot can be compared or restored at any time.
+
+// SnapshotParam is a parameter value captured by a snapshot
+type SnapshotParam struct {
+	Section string
+	Key     string
+	Value   string
+}
+
+// Snapshot contains the parameter values of the system captured at the
+// time the snapshot was created
+type Snapshot struct {
+	Name   string
+	Date   string
+	Kernel string
+	Params []SnapshotParam
+}
+
+// SnapshotDiff is a parameter with different values in two snapshots.
+// An empty value means, that the parameter is not part of the snapshot
+type SnapshotDiff struct {
+	Section string
+	Key     string
+	Value1  string
+	Value2  string
+}
+
+var snapshotDir = system.SaptuneSnapshotDir
+
+// the snapshot name is used as file name
+var isValidSnapshotName = regexp.MustCompile(`^[\w.-]+$`)
+
+// restorableSections contains the sections, whose parameter values can be
+// set directly without the need of the Note related drop-in files or the
+// boot loader configuration
+var restorableSections = map[string]bool{
+	INISectionSysctl:    true,
+	INISectionSys:       true,
+	INISectionVM:        true,
+	INISectionBlock:     true,
+	INISectionCPU:       true,
+	INISectionMEM:       true,
+	INISectionService:   true,
+	INISectionIRQ:       true,
+	INISectionHugepages: true,
+	INISectionNet:       true,
+}
+
+// getPathToSnapshot returns path to the snapshot file
+func getPathToSnapshot(name string) string {
+	return path.Join(snapshotDir, name)
+}
+
+// CaptureSnapshot collects the current system values of all parameters of
+// the given Note definitions without storing the snapshot.
+// The parameters are kept in the order of the Notes and their sections, which
+// is the order needed to restore them
+func CaptureSnapshot(name string, notes map[string]Note) Snapshot {
+	kernel, _ := system.GetSysctlString("kernel.osrelease")
+	snap := Snapshot{
+		Name:   name,
+		Date:   time.Now().Format("2006-01-02 15:04:05"),
+		Kernel: kernel,
+		Params: make([]SnapshotParam, 0, 64),
+	}
+	noteIDs := make([]string, 0, len(notes))
+	for noteID := range notes {
+		noteIDs = append(noteIDs, noteID)
+	}
+	sort.Strings(noteIDs)
+
+	captured := make(map[string]bool)
+	blckDevs := resetToFactoryBlockDevices()
+	for _, noteID := range noteIDs {
+		vend, ok := notes[noteID].(INISettings)
+		if !ok {
+			continue
+		}
+		noteIni, err := txtparser.ParseINIFile(vend.ConfFilePath, false)
+		if err != nil {
+			system.WarningLog("Failed to read Note definition file '%s' - %v", vend.ConfFilePath, err)
+			continue
+		}
+		noteIni = txtparser.ExpandGlobKeys(noteIni)
+		for _, entry := range noteIni.AllValues {
+			if captured[entry.Key] {
+				continue
+			}
+			val, states, ok := getSnapshotValue(entry, &blckDevs, vend.ConfFilePath)
+			if !ok {
+				continue
+			}
+			captured[entry.Key] = true
+			snap.Params = append(snap.Params, SnapshotParam{Section: entry.Section, Key: entry.Key, Value: val})
+			if entry.Key == "force_latency" {
+				// needed to restore 'force_latency'
+				snap.Params = append(snap.Params, SnapshotParam{Section: entry.Section, Key: "fl_states", Value: states})
+			}
+		}
+	}
+	return snap
+}
+
+// getSnapshotValue returns the current system value of a parameter of a
+// Note definition using the Get functions of the sections.
+// Additional the cpu state values are returned for 'force_latency'.
+// Returns false, if the parameter is not part of a snapshot
+func getSnapshotValue(entry txtparser.INIEntry, blckDevs *param.BlockDeviceQueue, noteFile string) (string, string, bool) {
+	val := ""
+	states := ""
+	switch entry.Section {
+	case INISectionSysctl:
+		val, _ = system.GetSysctlString(entry.Key)
+	case INISectionSys:
+		val, _ = GetSysVal(entry.Key)
+	case INISectionVM:
+		val, _ = GetVMVal(entry.Key)
+	case INISectionFS:
+		if entry.Value == "" {
+			return "", "", false
+		}
+		val, _ = GetFSVal(entry.Key, entry.Value)
+	case INISectionBlock:
+		val, _, _ = GetBlkVal(entry.Key, blckDevs)
+	case INISectionLimits:
+		val, _, _ = GetLimitsVal(entry.Value)
+	case INISectionService:
+		val = GetServiceVal(entry.Key)
+	case INISectionLogin:
+		val, _ = GetLoginVal(entry.Key)
+	case INISectionMEM:
+		val = GetMemVal(entry.Key)
+	case INISectionCPU:
+		val, states, _ = GetCPUVal(entry.Key)
+	case INISectionGrub:
+		val = GetGrubVal(entry.Key)
+	case INISectionKmod, INISectionIRQ, INISectionHugepages, INISectionNet, INISectionCgroup:
+		if entry.Value == "" {
+			// untouched
+			return "", "", false
+		}
+		val = getUntouchedSectVal(entry.Section, entry.Key)
+	case INISectionPagecache:
+		val = GetPagecacheVal(entry.Key, &LinuxPagingImprovements{PagingConfig: noteFile})
+	default:
+		// version, rpm and reminder sections contain no system values
+		return "", "", false
+	}
+	return val, states, true
+}
+
+// getUntouchedSectVal returns the current system value of a parameter of
+// the sections, which support untouched parameters
+func getUntouchedSectVal(section, key string) string {
+	val := ""
+	switch section {
+	case INISectionKmod:
+		val = GetKernelModuleVal(key)
+	case INISectionIRQ:
+		val = GetIRQVal(key)
+	case INISectionHugepages:
+		val = GetHugepagesVal(key)
+	case INISectionNet:
+		val = GetNetVal(key)
+	case INISectionCgroup:
+		val = GetCgroupVal(key)
+	}
+	return val
+}
+
+// CreateSnapshot captures the current system values of all parameters of
+// the given Note definitions and stores them as snapshot
+func CreateSnapshot(name string, notes map[string]Note) (Snapshot, error) {
+	if !isValidSnapshotName.MatchString(name) {
+		return Snapshot{}, fmt.Errorf("invalid snapshot name '%s'. Only letters, digits, '_', '-' and '.' are allowed", name)
+	}
+	if _, err := os.Stat(getPathToSnapshot(name)); err == nil {
+		return Snapshot{}, fmt.Errorf("snapshot '%s' already exists", name)
+	}
+	snap := CaptureSnapshot(name, notes)
+	content, err := json.Marshal(snap)
+	if err != nil {
+		return snap, err
+	}
+	if err := os.MkdirAll(snapshotDir, 0755); err != nil {
+		return snap, err
+	}
+	system.DebugLog("Write snapshot '%s' to file '%s'", name, getPathToSnapshot(name))
+	return snap, os.WriteFile(getPathToSnapshot(name), content, 0644)
+}
+
+// GetSnapshot reads the stored snapshot
+func GetSnapshot(name string) (Snapshot, error) {
+	snap := Snapshot{}
+	if !isValidSnapshotName.MatchString(name) {
+		return snap, fmt.Errorf("invalid snapshot name '%s'", name)
+	}
+	content, err := os.ReadFile(getPathToSnapshot(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return snap, fmt.Errorf("snapshot '%s' not found. Run \"saptune snapshot list\" for a list of available snapshots", name)
+		}
+		return snap, err
+	}
+	if err := json.Unmarshal(content, &snap); err != nil {
+		return snap, fmt.Errorf("wrong content in snapshot file '%s' - %v", getPathToSnapshot(name), err)
+	}
+	return snap, nil
+}
+
+// ListSnapshots returns all stored snapshots sorted by name
+func ListSnapshots() []Snapshot {
+	snaps := []Snapshot{}
+	dirContent, err := os.ReadDir(snapshotDir)
+	if err != nil {
+		return snaps
+	}
+	for _, entry := range dirContent {
+		snap, err := GetSnapshot(entry.Name())
+		if err != nil {
+			system.WarningLog("%v", err)
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps
+}
+
+// DiffSnapshots returns the parameters with different values in the two
+// snapshots sorted by section and parameter name
+func DiffSnapshots(snap1, snap2 Snapshot) []SnapshotDiff {
+	diffs := []SnapshotDiff{}
+	vals2 := make(map[string]SnapshotParam)
+	for _, sparam := range snap2.Params {
+		vals2[sparam.Key] = sparam
+	}
+	for _, sparam := range snap1.Params {
+		sparam2, ok := vals2[sparam.Key]
+		delete(vals2, sparam.Key)
+		if ok && strings.Join(strings.Fields(sparam.Value), " ") == strings.Join(strings.Fields(sparam2.Value), " ") {
+			continue
+		}
+		diffs = append(diffs, SnapshotDiff{Section: sparam.Section, Key: sparam.Key, Value1: sparam.Value, Value2: sparam2.Value})
+	}
+	// parameters only available in the second snapshot
+	for _, sparam := range vals2 {
+		diffs = append(diffs, SnapshotDiff{Section: sparam.Section, Key: sparam.Key, Value2: sparam.Value})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Section != diffs[j].Section {
+			return diffs[i].Section < diffs[j].Section
+		}
+		return diffs[i].Key < diffs[j].Key
+	})
+	return diffs
+}
+
+// RestoreSnapshot sets the parameters of the snapshot back to the captured
+// values, if the current system value differs. The parameter state files
+// are not used or changed.
+// The Note definitions are needed to get the current values of the
+// parameters, which depend on the Note (e.g. filesystem options or limits).
+// Returns the restored parameters and the changed parameters, which can not
+// be restored without the related Note (e.g. boot options or drop-in files)
+func RestoreSnapshot(snap Snapshot, notes map[string]Note) ([]SnapshotParam, []SnapshotParam, error) {
+	restored := []SnapshotParam{}
+	skipped := []SnapshotParam{}
+	errs := make([]error, 0)
+	current := make(map[string]string)
+	for _, sparam := range CaptureSnapshot("current", notes).Params {
+		current[sparam.Key] = sparam.Value
+	}
+	states := ""
+	for _, sparam := range snap.Params {
+		if sparam.Key == "fl_states" {
+			states = sparam.Value
+		}
+	}
+	blckDevs := resetToFactoryBlockDevices()
+	for _, sparam := range restoreOrder(snap.Params, current) {
+		if sparam.Key == "fl_states" {
+			// restored together with 'force_latency'
+			continue
+		}
+		if sparam.Value == "" || sparam.Value == "NA" || sparam.Value == "PNA" {
+			// parameter not supported by the system
+			continue
+		}
+		if !restorableSections[sparam.Section] {
+			if cur, ok := current[sparam.Key]; ok && cur != sparam.Value {
+				skipped = append(skipped, sparam)
+			}
+			continue
+		}
+		entry := txtparser.INIEntry{Section: sparam.Section, Key: sparam.Key, Value: sparam.Value}
+		if cur, _, _ := getSnapshotValue(entry, &blckDevs, ""); cur == sparam.Value {
+			continue
+		}
+		system.InfoLog("restore parameter '%s' of section [%s] to value '%s'", sparam.Key, sparam.Section, sparam.Value)
+		if err := setSnapshotValue(sparam, states, &blckDevs); err != nil {
+			errs = append(errs, fmt.Errorf("parameter '%s' - %v", sparam.Key, err))
+			continue
+		}
+		restored = append(restored, sparam)
+	}
+	if len(errs) != 0 {
+		return restored, skipped, fmt.Errorf("failed to restore one or more parameters: %v", errs)
+	}
+	return restored, skipped, nil
+}
+
+// restoreOrder returns the parameters of the snapshot in the order needed
+// to restore them. The I/O scheduler tunables depend on the scheduler of the
+// block device, so they are restored after the schedulers. The frequency
+// limits are restored in the direction of the change, so that
+// scaling_min_freq never exceeds scaling_max_freq
+func restoreOrder(params []SnapshotParam, current map[string]string) []SnapshotParam {
+	ordered := make([]SnapshotParam, 0, len(params))
+	tunables := []SnapshotParam{}
+	minIdx := -1
+	maxIdx := -1
+	for _, sparam := range params {
+		switch {
+		case sparam.Section == INISectionBlock && system.IsIOSched.MatchString(sparam.Key):
+			tunables = append(tunables, sparam)
+			continue
+		case sparam.Section == INISectionCPU && sparam.Key == "scaling_min_freq":
+			minIdx = len(ordered)
+		case sparam.Section == INISectionCPU && sparam.Key == "scaling_max_freq":
+			maxIdx = len(ordered)
+		}
+		ordered = append(ordered, sparam)
+	}
+	if minIdx >= 0 && maxIdx >= 0 {
+		// raising the frequencies needs the new scaling_max_freq first,
+		// lowering them the new scaling_min_freq first
+		raised := freqLimitRaised(ordered[minIdx].Value, current["scaling_min_freq"])
+		if raised == (minIdx < maxIdx) {
+			ordered[minIdx], ordered[maxIdx] = ordered[maxIdx], ordered[minIdx]
+		}
+	}
+	return append(ordered, tunables...)
+}
+
+// freqLimitRaised checks, if the new per CPU frequency value (e.g.
+// 'all:2000000' or 'cpu0:2000000 cpu1:3000000') is higher than the current
+// value for at least one CPU
+func freqLimitRaised(newVal, curVal string) bool {
+	cur := make(map[string]int)
+	for _, entry := range strings.Fields(curVal) {
+		fields := strings.Split(entry, ":")
+		if freq, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+			cur[fields[0]] = freq
+		}
+	}
+	for _, entry := range strings.Fields(newVal) {
+		fields := strings.Split(entry, ":")
+		freq, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			continue
+		}
+		for cpu, cfreq := range cur {
+			if (fields[0] == "all" || cpu == "all" || cpu == fields[0]) && freq > cfreq {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setSnapshotValue sets the parameter value using the Set functions of the
+// sections
+func setSnapshotValue(sparam SnapshotParam, states string, blckDevs *param.BlockDeviceQueue) error {
+	var err error
+	switch sparam.Section {
+	case INISectionSysctl:
+		err = system.SetSysctlString(sparam.Key, sparam.Value)
+	case INISectionSys:
+		err = SetSysVal(sparam.Key, sparam.Value)
+	case INISectionVM:
+		err = SetVMVal(sparam.Key, sparam.Value)
+	case INISectionBlock:
+		// 'revert' sets the given value instead of the optimised one
+		err = SetBlkVal(sparam.Key, sparam.Value, blckDevs, true)
+	case INISectionCPU:
+		err = SetCPUVal(sparam.Key, sparam.Value, "", states, "", true)
+	case INISectionMEM:
+		err = SetMemVal(sparam.Key, sparam.Value)
+	case INISectionService:
+		err = SetServiceVal(sparam.Key, sparam.Value)
+	case INISectionIRQ:
+		err = SetIRQVal(sparam.Key, sparam.Value)
+	case INISectionHugepages:
+		err = SetHugepagesVal(sparam.Key, sparam.Value)
+	case INISectionNet:
+		err = SetNetVal(sparam.Key, sparam.Value)
+	}
+	return err
+}
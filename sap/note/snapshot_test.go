@@ -0,0 +1,184 @@
+package note
+
+import (
+	"fmt"
+	"github.com/SUSE/saptune/system"
+	"os"
+	"testing"
+)
+
+func TestSnapshots(t *testing.T) {
+	oldSnapshotDir := snapshotDir
+	defer func() { snapshotDir = oldSnapshotDir }()
+	snapshotDir = "/tmp/saptune_snapshots"
+	defer os.RemoveAll(snapshotDir)
+
+	val, err := system.GetSysctlString("vm.swappiness")
+	if err != nil {
+		t.Skip("sysctl parameter 'vm.swappiness' not available")
+	}
+	noteFile := "/tmp/saptune_snapshot_note"
+	defer os.Remove(noteFile)
+	content := "[version]\nVERSION=1\n\n[sysctl]\nvm.swappiness=10\nvm.dirty_ratio=10\n\n[grub]\ngrub:numa_balancing=disable\n\n[reminder]\nsnapshot test\n"
+	if err := os.WriteFile(noteFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notes := map[string]Note{"snaptest": INISettings{ConfFilePath: noteFile, ID: "snaptest"}}
+
+	if snaps := ListSnapshots(); len(snaps) != 0 {
+		t.Errorf("expected no snapshots, but got '%+v'", snaps)
+	}
+	snap, err := CreateSnapshot("before_update", notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Params) != 3 {
+		t.Errorf("expected 3 parameters, but got '%+v'", snap.Params)
+	}
+	// order of the Note definition
+	if snap.Params[0].Key != "vm.swappiness" || snap.Params[1].Key != "vm.dirty_ratio" || snap.Params[2].Key != "grub:numa_balancing" {
+		t.Errorf("wrong parameter order '%+v'", snap.Params)
+	}
+	if snap.Params[0].Section != "sysctl" || snap.Params[0].Value != val {
+		t.Errorf("wrong parameter '%+v'", snap.Params[0])
+	}
+	if _, err := CreateSnapshot("before_update", notes); err == nil {
+		t.Error("expected an error for an already existing snapshot")
+	}
+	if _, err := CreateSnapshot("../wrong", notes); err == nil {
+		t.Error("expected an error for an invalid snapshot name")
+	}
+
+	stored, err := GetSnapshot("before_update")
+	if err != nil {
+		t.Error(err)
+	}
+	if stored.Name != "before_update" || len(stored.Params) != 3 || stored.Kernel == "" {
+		t.Errorf("wrong snapshot '%+v'", stored)
+	}
+	if _, err := GetSnapshot("unknown"); err == nil {
+		t.Error("expected an error for a not existing snapshot")
+	}
+	if snaps := ListSnapshots(); len(snaps) != 1 || snaps[0].Name != "before_update" {
+		t.Errorf("wrong snapshots '%+v'", snaps)
+	}
+
+	// nothing changed
+	if diffs := DiffSnapshots(stored, CaptureSnapshot("current", notes)); len(diffs) != 0 {
+		t.Errorf("expected no differences, but got '%+v'", diffs)
+	}
+	restored, skipped, err := RestoreSnapshot(stored, notes)
+	if err != nil || len(restored) != 0 || len(skipped) != 0 {
+		t.Errorf("expected nothing to restore, but got '%+v', '%+v', '%v'", restored, skipped, err)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	snap1 := Snapshot{Name: "snap1", Params: []SnapshotParam{
+		{Section: "sysctl", Key: "vm.swappiness", Value: "60"},
+		{Section: "sysctl", Key: "vm.dirty_ratio", Value: "10"},
+		{Section: "service", Key: "systemd:uuidd.socket", Value: "start, enable"},
+		{Section: "cpu", Key: "governor", Value: "all:performance"},
+	}}
+	snap2 := Snapshot{Name: "snap2", Params: []SnapshotParam{
+		{Section: "sysctl", Key: "vm.swappiness", Value: "10"},
+		{Section: "sysctl", Key: "vm.dirty_ratio", Value: "10"},
+		{Section: "service", Key: "systemd:uuidd.socket", Value: "start,  enable"},
+		{Section: "block", Key: "NRREQ_sda", Value: "1024"},
+	}}
+	diffs := DiffSnapshots(snap1, snap2)
+	exp := []SnapshotDiff{
+		{Section: "block", Key: "NRREQ_sda", Value1: "", Value2: "1024"},
+		{Section: "cpu", Key: "governor", Value1: "all:performance", Value2: ""},
+		{Section: "sysctl", Key: "vm.swappiness", Value1: "60", Value2: "10"},
+	}
+	if fmt.Sprintf("%+v", diffs) != fmt.Sprintf("%+v", exp) {
+		t.Errorf("expected '%+v', but got '%+v'", exp, diffs)
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	val, err := system.GetSysctlString("vm.swappiness")
+	if err != nil {
+		t.Skip("sysctl parameter 'vm.swappiness' not available")
+	}
+	newVal := "61"
+	if val == newVal {
+		newVal = "59"
+	}
+	orig := Snapshot{Name: "orig", Params: []SnapshotParam{{Section: "sysctl", Key: "vm.swappiness", Value: val}}}
+	defer func() { _, _, _ = RestoreSnapshot(orig, nil) }()
+
+	noteFile := "/tmp/saptune_snapshot_note"
+	defer os.Remove(noteFile)
+	if err := os.WriteFile(noteFile, []byte("[version]\nVERSION=1\n\n[grub]\ngrub:numa_balancing=disable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notes := map[string]Note{"snaptest": INISettings{ConfFilePath: noteFile, ID: "snaptest"}}
+
+	snap := Snapshot{Name: "snap", Params: []SnapshotParam{
+		{Section: "sysctl", Key: "vm.swappiness", Value: newVal},
+		{Section: "sysctl", Key: "vm.not_available", Value: "PNA"},
+		{Section: "grub", Key: "grub:numa_balancing", Value: "wrong_value"},
+		{Section: "limits", Key: "LIMIT_@sapsys_soft_nofile", Value: "@sapsys soft nofile 1048576"},
+	}}
+	restored, skipped, err := RestoreSnapshot(snap, notes)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(restored) != 1 || restored[0].Key != "vm.swappiness" {
+		t.Errorf("wrong restored parameters '%+v'", restored)
+	}
+	if len(skipped) != 1 || skipped[0].Key != "grub:numa_balancing" {
+		t.Errorf("wrong skipped parameters '%+v'", skipped)
+	}
+	if cur, _ := system.GetSysctlString("vm.swappiness"); cur != newVal {
+		t.Errorf("expected '%s', but got '%s'", newVal, cur)
+	}
+	restored, _, err = RestoreSnapshot(orig, nil)
+	if err != nil || len(restored) != 1 {
+		t.Errorf("wrong restored parameters '%+v' - %v", restored, err)
+	}
+	if cur, _ := system.GetSysctlString("vm.swappiness"); cur != val {
+		t.Errorf("expected '%s', but got '%s'", val, cur)
+	}
+
+	// failing parameter
+	snap = Snapshot{Name: "snap", Params: []SnapshotParam{{Section: "sysctl", Key: "vm.swappiness", Value: "invalid"}}}
+	if _, _, err := RestoreSnapshot(snap, nil); err == nil {
+		t.Error("expected an error, but got none")
+	}
+}
+
+func TestRestoreOrder(t *testing.T) {
+	params := []SnapshotParam{
+		{Section: "block", Key: "IOSCHED.read_expire_sda", Value: "500"},
+		{Section: "sysctl", Key: "vm.swappiness", Value: "60"},
+		{Section: "block", Key: "IO_SCHEDULER_sda", Value: "mq-deadline"},
+		{Section: "cpu", Key: "scaling_min_freq", Value: "all:2000000"},
+		{Section: "cpu", Key: "scaling_max_freq", Value: "all:3000000"},
+	}
+	// raising the frequencies - scaling_max_freq first
+	current := map[string]string{"scaling_min_freq": "all:1000000", "scaling_max_freq": "all:1500000"}
+	exp := []string{"vm.swappiness", "IO_SCHEDULER_sda", "scaling_max_freq", "scaling_min_freq", "IOSCHED.read_expire_sda"}
+	keys := []string{}
+	for _, sparam := range restoreOrder(params, current) {
+		keys = append(keys, sparam.Key)
+	}
+	if fmt.Sprintf("%v", keys) != fmt.Sprintf("%v", exp) {
+		t.Errorf("expected '%v', but got '%v'", exp, keys)
+	}
+	// lowering the frequencies - scaling_min_freq first
+	current = map[string]string{"scaling_min_freq": "cpu0:2500000 cpu1:2500000", "scaling_max_freq": "all:3500000"}
+	exp = []string{"vm.swappiness", "IO_SCHEDULER_sda", "scaling_min_freq", "scaling_max_freq", "IOSCHED.read_expire_sda"}
+	keys = []string{}
+	for _, sparam := range restoreOrder(params, current) {
+		keys = append(keys, sparam.Key)
+	}
+	if fmt.Sprintf("%v", keys) != fmt.Sprintf("%v", exp) {
+		t.Errorf("expected '%v', but got '%v'", exp, keys)
+	}
+	if !freqLimitRaised("cpu0:1000000 cpu1:3000000", "all:2000000") || freqLimitRaised("all:2000000", "cpu0:2000000 cpu1:2500000") {
+		t.Error("wrong direction of the frequency change")
+	}
+}
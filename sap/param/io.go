@@ -3,22 +3,54 @@ package param
 import (
 	"fmt"
 	"github.com/SUSE/saptune/system"
+	"math"
 	"path"
 	"strconv"
 	"strings"
 )
 
 // BlockDeviceQueue is the data structure for block devices
-// for schedulers, IO nr_request, read_ahead_kb and max_sectors_kb changes
+// for schedulers, IO nr_request, read_ahead_kb, max_sectors_kb, rq_affinity,
+// nomerges, wbt_lat_usec, add_random and I/O scheduler tunable changes
 type BlockDeviceQueue struct {
 	BlockDeviceSchedulers
 	BlockDeviceNrRequests
 	BlockDeviceReadAheadKB
 	BlockDeviceMaxSectorsKB
+	BlockDeviceRqAffinity
+	BlockDeviceNomerges
+	BlockDeviceWbtLatUsec
+	BlockDeviceAddRandom
+	BlockDeviceIOSchedTunables
+}
+
+// queueValueRange contains the valid values of the integer queue attributes
+var queueValueRange = map[string][2]int{
+	"rq_affinity":  {0, 2},
+	"nomerges":     {0, 2},
+	"wbt_lat_usec": {-1, math.MaxInt32},
+	"add_random":   {0, 1},
+}
+
+// ioSchedTunables contains the tunables of the I/O schedulers, which can
+// be found in /sys/block/<device>/queue/iosched
+var ioSchedTunables = map[string][]string{
+	"mq-deadline": {"async_depth", "fifo_batch", "front_merges", "prio_aging_expire", "read_expire", "write_expire", "writes_starved"},
+	"bfq":         {"back_seek_max", "back_seek_penalty", "fifo_expire_async", "fifo_expire_sync", "low_latency", "max_budget", "slice_idle", "slice_idle_us", "strict_guarantees", "timeout_sync"},
+	"kyber":       {"read_lat_nsec", "write_lat_nsec"},
 }
 
 var blkDev *system.BlockDev
 
+// RefreshBlockDeviceInfo collects the current block device information from
+// the system and replaces the information read before. Needed, if the
+// block device values are read without a previous parse of a Note
+// definition file (e.g. blockdev.run was removed during startup)
+func RefreshBlockDeviceInfo() {
+	_ = system.CollectBlockDeviceInfo()
+	blkDev, _ = system.GetBlockDeviceInfo()
+}
+
 // BlockDeviceSchedulers changes IO elevators on all IO devices
 type BlockDeviceSchedulers struct {
 	SchedulerChoice map[string]string
@@ -78,6 +110,9 @@ func (ioe BlockDeviceSchedulers) Apply(blkdev interface{}) error {
 	bdev := blkdev.(string)
 	elevator := ioe.SchedulerChoice[bdev]
 	err := system.SetSysString(path.Join("block", bdev, "queue", "scheduler"), elevator)
+	if err == nil {
+		applyMpathPaths(bdev, "scheduler", elevator)
+	}
 
 	/* reuse in future
 	for name, elevator := range ioe.SchedulerChoice {
@@ -142,6 +177,8 @@ func (ior BlockDeviceNrRequests) Apply(blkdev interface{}) error {
 		} else {
 			system.WarningLog("skipping device '%s', not valid for setting 'number of requests' to '%v'", bdev, nrreq)
 		}
+	} else {
+		applyMpathPaths(bdev, "nr_requests", strconv.Itoa(nrreq))
 	}
 	/* for future use
 	errs := make([]error, 0, 0)
@@ -211,6 +248,8 @@ func (rakb BlockDeviceReadAheadKB) Apply(blkdev interface{}) error {
 				system.InfoLog("skipping device '%s'. Please check and adapt the value in the Note definition file.", bdev)
 				_ = system.SetSysInt(bfile, oldval)
 			}
+		} else {
+			applyMpathPaths(bdev, "read_ahead_kb", strconv.Itoa(readahead))
 		}
 	}
 	/* for future use
@@ -271,6 +310,8 @@ func (mskb BlockDeviceMaxSectorsKB) Apply(blkdev interface{}) error {
 	err := system.SetSysInt(path.Join("block", bdev, "queue", "max_sectors_kb"), maxsector)
 	if err != nil {
 		system.WarningLog("skipping device '%s', not valid for setting 'max_sectors_kb' to '%v'", bdev, maxsector)
+	} else {
+		applyMpathPaths(bdev, "max_sectors_kb", strconv.Itoa(maxsector))
 	}
 	/* for future use
 	errs := make([]error, 0, 0)
@@ -287,6 +328,265 @@ func (mskb BlockDeviceMaxSectorsKB) Apply(blkdev interface{}) error {
 	return nil
 }
 
+// BlockDeviceRqAffinity changes the rq_affinity value on block devices
+type BlockDeviceRqAffinity struct {
+	RqAffinity map[string]int
+}
+
+// Inspect retrieves the current rq_affinity from the system
+func (rqa BlockDeviceRqAffinity) Inspect() (Parameter, error) {
+	if len(rqa.RqAffinity) != 0 {
+		// inspect needs to run only once per saptune call
+		return rqa, nil
+	}
+	return BlockDeviceRqAffinity{RqAffinity: inspectQueueInt("RQ_AFFINITY")}, nil
+}
+
+// Optimise gets the expected rq_affinity value '<device> <value>' from the
+// configuration
+func (rqa BlockDeviceRqAffinity) Optimise(newRqAffinityValue interface{}) (Parameter, error) {
+	newRQA := rqa
+	if bdev, ival, ok := splitQueueValue(newRqAffinityValue); ok {
+		newRQA.RqAffinity[bdev] = ival
+	}
+	return newRQA, nil
+}
+
+// Apply sets the new rq_affinity value in the system
+func (rqa BlockDeviceRqAffinity) Apply(blkdev interface{}) error {
+	bdev := blkdev.(string)
+	return applyQueueInt(bdev, "rq_affinity", rqa.RqAffinity[bdev])
+}
+
+// BlockDeviceNomerges changes the nomerges value on block devices
+type BlockDeviceNomerges struct {
+	Nomerges map[string]int
+}
+
+// Inspect retrieves the current nomerges from the system
+func (nom BlockDeviceNomerges) Inspect() (Parameter, error) {
+	if len(nom.Nomerges) != 0 {
+		// inspect needs to run only once per saptune call
+		return nom, nil
+	}
+	return BlockDeviceNomerges{Nomerges: inspectQueueInt("NOMERGES")}, nil
+}
+
+// Optimise gets the expected nomerges value '<device> <value>' from the
+// configuration
+func (nom BlockDeviceNomerges) Optimise(newNomergesValue interface{}) (Parameter, error) {
+	newNOM := nom
+	if bdev, ival, ok := splitQueueValue(newNomergesValue); ok {
+		newNOM.Nomerges[bdev] = ival
+	}
+	return newNOM, nil
+}
+
+// Apply sets the new nomerges value in the system
+func (nom BlockDeviceNomerges) Apply(blkdev interface{}) error {
+	bdev := blkdev.(string)
+	return applyQueueInt(bdev, "nomerges", nom.Nomerges[bdev])
+}
+
+// BlockDeviceWbtLatUsec changes the wbt_lat_usec value (writeback
+// throttling latency) on block devices
+type BlockDeviceWbtLatUsec struct {
+	WbtLatUsec map[string]int
+}
+
+// Inspect retrieves the current wbt_lat_usec from the system
+func (wbt BlockDeviceWbtLatUsec) Inspect() (Parameter, error) {
+	if len(wbt.WbtLatUsec) != 0 {
+		// inspect needs to run only once per saptune call
+		return wbt, nil
+	}
+	return BlockDeviceWbtLatUsec{WbtLatUsec: inspectQueueInt("WBT_LAT_USEC")}, nil
+}
+
+// Optimise gets the expected wbt_lat_usec value '<device> <value>' from the
+// configuration
+func (wbt BlockDeviceWbtLatUsec) Optimise(newWbtLatUsecValue interface{}) (Parameter, error) {
+	newWBT := wbt
+	if bdev, ival, ok := splitQueueValue(newWbtLatUsecValue); ok {
+		newWBT.WbtLatUsec[bdev] = ival
+	}
+	return newWBT, nil
+}
+
+// Apply sets the new wbt_lat_usec value in the system
+func (wbt BlockDeviceWbtLatUsec) Apply(blkdev interface{}) error {
+	bdev := blkdev.(string)
+	return applyQueueInt(bdev, "wbt_lat_usec", wbt.WbtLatUsec[bdev])
+}
+
+// BlockDeviceAddRandom changes the add_random value (contribution to the
+// entropy pool) on block devices
+type BlockDeviceAddRandom struct {
+	AddRandom map[string]int
+}
+
+// Inspect retrieves the current add_random from the system
+func (adr BlockDeviceAddRandom) Inspect() (Parameter, error) {
+	if len(adr.AddRandom) != 0 {
+		// inspect needs to run only once per saptune call
+		return adr, nil
+	}
+	return BlockDeviceAddRandom{AddRandom: inspectQueueInt("ADD_RANDOM")}, nil
+}
+
+// Optimise gets the expected add_random value '<device> <value>' from the
+// configuration
+func (adr BlockDeviceAddRandom) Optimise(newAddRandomValue interface{}) (Parameter, error) {
+	newADR := adr
+	if bdev, ival, ok := splitQueueValue(newAddRandomValue); ok {
+		newADR.AddRandom[bdev] = ival
+	}
+	return newADR, nil
+}
+
+// Apply sets the new add_random value in the system
+func (adr BlockDeviceAddRandom) Apply(blkdev interface{}) error {
+	bdev := blkdev.(string)
+	return applyQueueInt(bdev, "add_random", adr.AddRandom[bdev])
+}
+
+// BlockDeviceIOSchedTunables changes the tunables of the I/O schedulers
+// (queue/iosched/*) on block devices
+type BlockDeviceIOSchedTunables struct {
+	Tunables map[string]map[string]string
+}
+
+// Inspect retrieves the current I/O scheduler tunables from the system
+func (ios BlockDeviceIOSchedTunables) Inspect() (Parameter, error) {
+	if len(ios.Tunables) != 0 {
+		// inspect needs to run only once per saptune call
+		return ios, nil
+	}
+	if blkDev == nil || (len(blkDev.AllBlockDevs) == 0 && len(blkDev.BlockAttributes) == 0) {
+		blkDev, _ = system.GetBlockDeviceInfo()
+	}
+	newIOS := BlockDeviceIOSchedTunables{Tunables: make(map[string]map[string]string)}
+	for _, entry := range blkDev.AllBlockDevs {
+		newIOS.Tunables[entry] = make(map[string]string)
+		for attr, val := range blkDev.BlockAttributes[entry] {
+			if strings.HasPrefix(attr, "IOSCHED.") {
+				newIOS.Tunables[entry][strings.TrimPrefix(attr, "IOSCHED.")] = val
+			}
+		}
+	}
+	return newIOS, nil
+}
+
+// Optimise gets the expected I/O scheduler tunable value
+// '<device> <tunable> <value>' from the configuration
+func (ios BlockDeviceIOSchedTunables) Optimise(newTunableValue interface{}) (Parameter, error) {
+	newIOS := ios
+	fields := strings.Fields(newTunableValue.(string))
+	if len(fields) > 2 {
+		if newIOS.Tunables[fields[0]] == nil {
+			newIOS.Tunables[fields[0]] = make(map[string]string)
+		}
+		newIOS.Tunables[fields[0]][fields[1]] = fields[2]
+	}
+	return newIOS, nil
+}
+
+// Apply sets the new I/O scheduler tunable value '<device> <tunable>' in
+// the system
+func (ios BlockDeviceIOSchedTunables) Apply(blkdevTunable interface{}) error {
+	fields := strings.Fields(blkdevTunable.(string))
+	if len(fields) < 2 {
+		return nil
+	}
+	bdev := fields[0]
+	attr := path.Join("iosched", fields[1])
+	val, ok := ios.Tunables[bdev][fields[1]]
+	if !ok {
+		// tunable not supported by the scheduler of the device
+		return nil
+	}
+	err := system.SetSysString(path.Join("block", bdev, "queue", attr), val)
+	if err != nil {
+		system.WarningLog("skipping device '%s', not valid for setting '%s' to '%v'", bdev, attr, val)
+		return err
+	}
+	applyMpathPaths(bdev, attr, val)
+	return nil
+}
+
+// inspectQueueInt retrieves the current values of an integer queue attribute
+// of all block devices from the collected block device information
+func inspectQueueInt(attr string) map[string]int {
+	if blkDev == nil || (len(blkDev.AllBlockDevs) == 0 && len(blkDev.BlockAttributes) == 0) {
+		blkDev, _ = system.GetBlockDeviceInfo()
+	}
+	vals := make(map[string]int)
+	for _, entry := range blkDev.AllBlockDevs {
+		if ival, err := strconv.Atoi(blkDev.BlockAttributes[entry][attr]); err == nil {
+			vals[entry] = ival
+		}
+	}
+	return vals
+}
+
+// splitQueueValue splits an optimise value '<device> <value>' of an
+// integer queue attribute
+func splitQueueValue(newValue interface{}) (string, int, bool) {
+	fields := strings.Fields(newValue.(string))
+	if len(fields) < 2 {
+		return "", 0, false
+	}
+	ival, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", 0, false
+	}
+	return fields[0], ival, true
+}
+
+// applyQueueInt sets the new value of an integer queue attribute of a block
+// device and of the paths of a multipath device
+func applyQueueInt(bdev, attr string, value int) error {
+	err := system.SetSysInt(path.Join("block", bdev, "queue", attr), value)
+	if err != nil {
+		system.WarningLog("skipping device '%s', not valid for setting '%s' to '%v'", bdev, attr, value)
+		return err
+	}
+	applyMpathPaths(bdev, attr, strconv.Itoa(value))
+	return nil
+}
+
+// applyMpathPaths sets the queue attribute of all paths of a multipath
+// device to the value of the multipath device, so that a path added later or
+// used after a fail over does not differ from the multipath device.
+// The start values of the paths are saved before, so that a revert can
+// restore them (see system.RestoreMpathPathStart)
+func applyMpathPaths(bdev, attr, value string) {
+	system.SaveMpathPathStart(bdev, attr)
+	for _, mpath := range system.GetMpathPaths(bdev) {
+		if err := system.SetSysString(path.Join("block", mpath, "queue", attr), value); err != nil {
+			system.WarningLog("skipping path '%s' of multipath device '%s', not valid for setting '%s' to '%v'", mpath, bdev, attr, value)
+		}
+	}
+}
+
+// SyncMpathPaths sets the queue attributes of all paths of a multipath
+// device to the current values of the multipath device (e.g. for a path
+// added to an already tuned multipath device)
+func SyncMpathPaths(bdev string, attrs []string) {
+	for _, attr := range attrs {
+		file := path.Join("block", bdev, "queue", attr)
+		val, err := system.GetSysString(file)
+		if attr == "scheduler" {
+			val, err = system.GetSysChoice(file)
+		}
+		if err != nil || val == "" || val == "NA" || val == "PNA" {
+			system.DebugLog("SyncMpathPaths - '%s' not available for multipath device '%s', skipping", attr, bdev)
+			continue
+		}
+		applyMpathPaths(bdev, attr, val)
+	}
+}
+
 // IsValidScheduler checks, if the scheduler value is supported by the system.
 // only used during optimize
 // During initialize, the scheduler is read from the system, so no check needed.
@@ -330,6 +630,64 @@ func IsValidforReadAheadKB(blockdev, readahead string) bool {
 	return checkIfBlockIsValid(blockdev, readahead, file)
 }
 
+// IsValidQueueValue checks, if the value is in the range of the valid values
+// of the integer queue attribute (rq_affinity, nomerges, wbt_lat_usec and
+// add_random)
+func IsValidQueueValue(attr, value string) bool {
+	limits, ok := queueValueRange[attr]
+	if !ok {
+		return false
+	}
+	ival, err := strconv.Atoi(value)
+	if err != nil || ival < limits[0] || ival > limits[1] {
+		system.InfoLog("'%s' is not a valid value for '%s', valid values are '%d' - '%d'.", value, attr, limits[0], limits[1])
+		return false
+	}
+	return true
+}
+
+// WbtLatDefault returns the default wbt_lat_usec value of the block device,
+// which the kernel sets, if '-1' is written to queue/wbt_lat_usec. The kernel
+// never reports '-1', so this value is needed to compare with the current
+// value. '2000' for non-rotational and '75000' for rotational devices.
+// Returns false, if the device type can not be identified
+func WbtLatDefault(blockdev string) (int, bool) {
+	rot, err := system.GetSysString(path.Join("block", blockdev, "queue", "rotational"))
+	if err != nil || (rot != "0" && rot != "1") {
+		system.DebugLog("WbtLatDefault - can not identify, if device '%s' is rotational", blockdev)
+		return 0, false
+	}
+	if rot == "0" {
+		return 2000, true
+	}
+	return 75000, true
+}
+
+// IsValidIOSchedTunable checks, if the tunable is supported by the I/O
+// scheduler, which is or will be used for the block device. An empty
+// scheduler refers to the current scheduler of the device
+func IsValidIOSchedTunable(blockdev, scheduler, tunable string) bool {
+	if blkDev == nil || (len(blkDev.AllBlockDevs) == 0 && len(blkDev.BlockAttributes) == 0) {
+		blkDev, _ = system.GetBlockDeviceInfo()
+	}
+	curSched := blkDev.BlockAttributes[blockdev]["IO_SCHEDULER"]
+	if scheduler == "" {
+		scheduler = curSched
+	}
+	if scheduler == curSched {
+		if _, ok := blkDev.BlockAttributes[blockdev]["IOSCHED."+tunable]; ok {
+			return true
+		}
+	}
+	for _, tun := range ioSchedTunables[scheduler] {
+		if tun == tunable {
+			return true
+		}
+	}
+	system.InfoLog("'%s' is not a valid tunable of scheduler '%s' for device '%s', skipping.", tunable, scheduler, blockdev)
+	return false
+}
+
 func checkIfBlockIsValid(blockdev string, testString string, file string) bool {
 	elev, _ := system.GetSysChoice(path.Join("block", blockdev, "queue", "scheduler"))
 	if elev != "" && elev != "NA" && elev != "PNA" {
@@ -492,3 +492,129 @@ func TestIsValidforReadAheadKB(t *testing.T) {
 }
 
 // Apply für beide
+
+func TestIsValidQueueValue(t *testing.T) {
+	valid := map[string][]string{"rq_affinity": {"0", "1", "2"}, "nomerges": {"0", "2"}, "wbt_lat_usec": {"-1", "0", "75000"}, "add_random": {"0", "1"}}
+	for attr, vals := range valid {
+		for _, val := range vals {
+			if !IsValidQueueValue(attr, val) {
+				t.Errorf("'%s' is not a valid value for '%s'", val, attr)
+			}
+		}
+	}
+	invalid := map[string][]string{"rq_affinity": {"3", "-1", "abc"}, "nomerges": {"5"}, "wbt_lat_usec": {"-2", ""}, "add_random": {"2"}, "hugo": {"1"}}
+	for attr, vals := range invalid {
+		for _, val := range vals {
+			if IsValidQueueValue(attr, val) {
+				t.Errorf("'%s' is a valid value for '%s'", val, attr)
+			}
+		}
+	}
+}
+
+func TestWbtLatDefault(t *testing.T) {
+	if _, ok := WbtLatDefault("sdz"); ok {
+		t.Error("default reported for a not existing device")
+	}
+	for _, bdev := range blockDev {
+		rot, err := system.GetSysString(path.Join("block", bdev, "queue", "rotational"))
+		if err != nil {
+			continue
+		}
+		exp := 75000
+		if rot == "0" {
+			exp = 2000
+		}
+		if def, ok := WbtLatDefault(bdev); !ok || def != exp {
+			t.Errorf("device '%s': expected '%d', got '%d', '%v'", bdev, exp, def, ok)
+		}
+	}
+}
+
+func TestIsValidIOSchedTunable(t *testing.T) {
+	oldBlkDev := blkDev
+	defer func() { blkDev = oldBlkDev }()
+	blkDev = &system.BlockDev{
+		AllBlockDevs:    []string{"sdz"},
+		BlockAttributes: map[string]map[string]string{"sdz": {"IO_SCHEDULER": "bfq", "IOSCHED.slice_idle": "8", "IOSCHED.new_tunable": "1"}},
+	}
+	if !IsValidIOSchedTunable("sdz", "", "slice_idle") || !IsValidIOSchedTunable("sdz", "bfq", "new_tunable") {
+		t.Error("tunable of the current scheduler not detected as valid")
+	}
+	if !IsValidIOSchedTunable("sdz", "mq-deadline", "fifo_batch") || !IsValidIOSchedTunable("sdz", "kyber", "read_lat_nsec") {
+		t.Error("tunable of the next scheduler not detected as valid")
+	}
+	if IsValidIOSchedTunable("sdz", "mq-deadline", "slice_idle") || IsValidIOSchedTunable("sdz", "none", "fifo_batch") || IsValidIOSchedTunable("sdz", "", "fifo_batch") {
+		t.Error("tunable of another scheduler detected as valid")
+	}
+
+	inspected, _ := BlockDeviceIOSchedTunables{}.Inspect()
+	if inspected.(BlockDeviceIOSchedTunables).Tunables["sdz"]["slice_idle"] != "8" {
+		t.Errorf("wrong inspected values - '%+v'", inspected)
+	}
+	optimised, _ := inspected.Optimise("sdz slice_idle 0")
+	if optimised.(BlockDeviceIOSchedTunables).Tunables["sdz"]["slice_idle"] != "0" {
+		t.Errorf("wrong optimised values - '%+v'", optimised)
+	}
+	// tunable not supported by the scheduler of the device
+	if err := optimised.Apply("sdz fifo_batch"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBlockDeviceApplyErrors(t *testing.T) {
+	if !system.IsUserRoot() {
+		t.Skip("the test requires root access")
+	}
+	bdev := ""
+	for _, entry := range blockDev {
+		if _, err := os.Stat(path.Join("/sys/block", entry, "queue/iosched/fifo_batch")); err == nil {
+			bdev = entry
+			break
+		}
+	}
+	if bdev == "" {
+		t.Skip("no block device with I/O scheduler tunables available")
+	}
+	// write failures are returned
+	nom := BlockDeviceNomerges{Nomerges: map[string]int{bdev: -5}}
+	if err := nom.Apply(bdev); err == nil {
+		t.Error("should return an error and not 'nil'")
+	}
+	ios := BlockDeviceIOSchedTunables{Tunables: map[string]map[string]string{bdev: {"fifo_batch": "abc"}}}
+	if err := ios.Apply(bdev + " fifo_batch"); err == nil {
+		t.Error("should return an error and not 'nil'")
+	}
+}
+
+func TestQueueIntParameter(t *testing.T) {
+	oldBlkDev := blkDev
+	defer func() { blkDev = oldBlkDev }()
+	blkDev = &system.BlockDev{
+		AllBlockDevs:    []string{"sdy", "sdz"},
+		BlockAttributes: map[string]map[string]string{"sdy": {"RQ_AFFINITY": "1", "NOMERGES": "0", "WBT_LAT_USEC": "2000", "ADD_RANDOM": "1"}, "sdz": {"RQ_AFFINITY": "1", "WBT_LAT_USEC": ""}},
+	}
+	rqa, _ := BlockDeviceRqAffinity{}.Inspect()
+	if len(rqa.(BlockDeviceRqAffinity).RqAffinity) != 2 {
+		t.Errorf("wrong inspected values - '%+v'", rqa)
+	}
+	rqa, _ = rqa.Optimise("sdz 2")
+	if rqa.(BlockDeviceRqAffinity).RqAffinity["sdz"] != 2 || rqa.(BlockDeviceRqAffinity).RqAffinity["sdy"] != 1 {
+		t.Errorf("wrong optimised values - '%+v'", rqa)
+	}
+	nom, _ := BlockDeviceNomerges{}.Inspect()
+	nom, _ = nom.Optimise("sdy 2")
+	if len(nom.(BlockDeviceNomerges).Nomerges) != 1 || nom.(BlockDeviceNomerges).Nomerges["sdy"] != 2 {
+		t.Errorf("wrong optimised values - '%+v'", nom)
+	}
+	wbt, _ := BlockDeviceWbtLatUsec{}.Inspect()
+	wbt, _ = wbt.Optimise("sdy -1")
+	if _, ok := wbt.(BlockDeviceWbtLatUsec).WbtLatUsec["sdz"]; ok || wbt.(BlockDeviceWbtLatUsec).WbtLatUsec["sdy"] != -1 {
+		t.Errorf("wrong optimised values - '%+v'", wbt)
+	}
+	adr, _ := BlockDeviceAddRandom{}.Inspect()
+	adr, _ = adr.Optimise("sdy abc")
+	if adr.(BlockDeviceAddRandom).AddRandom["sdy"] != 1 {
+		t.Errorf("wrong optimised values - '%+v'", adr)
+	}
+}
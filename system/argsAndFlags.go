@@ -50,13 +50,13 @@ func GetFlagVal(flag string) string {
 // returns a map of Flags (set/not set or value) and a slice containing the
 // remaining arguments
 // possible Flags - force, dryrun, help, version, show-non-compliant, format,
-// colorscheme, non-compliance-check
-// on command line - --force, --dry-run or --dryrun, --help, --version, --color-scheme, --format
+// colorscheme, non-compliance-check, to-baseline, atomic, param, note, since
+// on command line - --force, --dry-run or --dryrun, --help, --version, --color-scheme, --format, --to-baseline, --atomic, --param, --note, --since
 // Some Flags (like 'format') can have a value (--format json or --format csv)
 func ParseCliArgs() ([]string, map[string]string) {
 	stArgs := []string{}
 	// supported flags
-	stFlags := map[string]string{"force": "false", "dryrun": "false", "help": "false", "version": "false", "show-non-compliant": "false", "format": "", "colorscheme": "", "non-compliance-check": "false", "notSupported": "", "force-color": "false", "fun": "false"}
+	stFlags := map[string]string{"force": "false", "dryrun": "false", "help": "false", "version": "false", "show-non-compliant": "false", "format": "", "colorscheme": "", "non-compliance-check": "false", "notSupported": "", "force-color": "false", "fun": "false", "to-baseline": "false", "atomic": "false", "param": "", "note": "", "since": ""}
 	skip := false
 	for i, arg := range os.Args {
 		if skip {
@@ -106,6 +106,20 @@ func handleValueFlags(arg, farg string, flags map[string]string) bool {
 		flags["colorscheme"] = farg
 		skip = true
 	}
+	switch arg {
+	case "--param", "-param":
+		// --param vm.swappiness
+		flags["param"] = farg
+		skip = true
+	case "--note", "-note":
+		// --note 1656250
+		flags["note"] = farg
+		skip = true
+	case "--since", "-since":
+		// --since 2024-01-31
+		flags["since"] = farg
+		skip = true
+	}
 	return skip
 }
 
@@ -129,6 +143,10 @@ func handleSimpleFlags(arg string, flags map[string]string) {
 		flags["force-color"] = "true"
 	case "--fun", "-fun":
 		flags["fun"] = "true"
+	case "--to-baseline", "-to-baseline":
+		flags["to-baseline"] = "true"
+	case "--atomic", "-atomic":
+		flags["atomic"] = "true"
 	default:
 		setUnsupportedFlag(arg, flags)
 	}
@@ -288,11 +306,15 @@ func chkGlobalFlag(globalFlags []string, result bool) (bool, bool, int) {
 }
 
 // chkRealmOpts checks for realm options
-// at the moment only 'saptune status' has an option (--non-compliance-check)
+// 'saptune status' has an option (--non-compliance-check)
+// 'saptune history' has the options --param, --note and --since
 func chkRealmOpts(cmdLinePos map[string]int) bool {
 	DebugLog("chkRealmOpts - cmdLinePos is '%+v'", cmdLinePos)
 	stArgs := os.Args
 	ret := true
+	if IsFlagSet("param") || IsFlagSet("note") || IsFlagSet("since") {
+		ret = chkHistorySyntax(stArgs, cmdLinePos)
+	}
 	if IsFlagSet("non-compliance-check") {
 		// check for valid realm
 		if !(stArgs[cmdLinePos["realm"]] == "status" || stArgs[cmdLinePos["realm"]] == "service" || stArgs[cmdLinePos["realm"]] == "daemon") {
@@ -330,7 +352,7 @@ func chkCmdOpts(cmdLinePos map[string]int) bool {
 		DebugLog("chkCmdOpts failed - too few arguments for flags 'force' or 'dryrun' or 'colorscheme' or 'show-non-compliant'")
 		return false
 	}
-	if len(os.Args) < cmdLinePos["cmdOpt"]+1 || (!IsFlagSet("force") && !IsFlagSet("dryrun") && !IsFlagSet("colorscheme") && !IsFlagSet("show-non-compliant") && !IsFlagSet("non-compliance-check")) {
+	if len(os.Args) < cmdLinePos["cmdOpt"]+1 || (!IsFlagSet("force") && !IsFlagSet("dryrun") && !IsFlagSet("colorscheme") && !IsFlagSet("show-non-compliant") && !IsFlagSet("non-compliance-check") && !IsFlagSet("to-baseline") && !IsFlagSet("atomic")) {
 		// no command options set or too few options
 		// and/or non of the flags set, which need further checks
 		// so let the 'old' default checks (in main and/or actions) set
@@ -350,6 +372,11 @@ func chkCmdOpts(cmdLinePos map[string]int) bool {
 		"chkVerifySyntax",
 		// saptune (service) status  [--non-compliance-check]
 		"chkServiceStatusSyntax",
+		// saptune revert all [--to-baseline]
+		"chkToBaselineFlag",
+		// saptune note apply [--atomic] NOTEID
+		// saptune solution apply [--atomic] SOLUTIONNAME
+		"chkAtomicFlag",
 	}
 
 	for _, flag := range flagToCheck {
@@ -413,6 +440,18 @@ func checkFlag(cmdLinePos map[string]int, flagValue string) bool {
 		isWrongPosition := stArgs[cmdLinePos["cmdOpt"]] != "--dry-run"
 		result = runChecks("chkDryrunFlag", "dry-run", "dryrun", notInRealm, isWrongPosition)
 
+	case "chkToBaselineFlag":
+		// Checks the syntax of 'saptune revert all' regarding the use of the 'to-baseline' flag
+		notInRealm := syntaxCheckNotRealm([][]string{{"revert", "all"}})
+		isWrongPosition := stArgs[cmdLinePos["cmdOpt"]] != "--to-baseline"
+		result = runChecks("chkToBaselineFlag", "to-baseline", "to-baseline", notInRealm, isWrongPosition)
+
+	case "chkAtomicFlag":
+		// Checks the syntax of 'saptune note apply' and 'saptune solution apply' regarding the use of the 'atomic' flag
+		notInRealm := syntaxCheckNotRealm([][]string{{"note", "apply"}, {"solution", "apply"}})
+		isWrongPosition := stArgs[cmdLinePos["cmdOpt"]] != "--atomic"
+		result = runChecks("chkAtomicFlag", "atomic", "atomic", notInRealm, isWrongPosition)
+
 	case "chkVerifySyntax":
 		result = chkVerifySyntax(stArgs, cmdLinePos, result)
 	}
@@ -457,3 +496,27 @@ func chkVerifySyntax(stArgs []string, cmdLinePos map[string]int, ret bool) bool
 	}
 	return ret
 }
+
+// chkHistorySyntax checks the syntax of 'saptune history' regarding the
+// flags 'param', 'note' and 'since'. The flags can be used in any order, but
+// each needs a value and no further arguments are allowed
+// saptune history [--param KEY] [--note ID] [--since TIME]
+func chkHistorySyntax(stArgs []string, cmdLinePos map[string]int) bool {
+	if stArgs[cmdLinePos["realm"]] != "history" {
+		DebugLog("chkHistorySyntax failed - 'param', 'note' or 'since' flag used with wrong realm '%+v'", stArgs[cmdLinePos["realm"]])
+		return false
+	}
+	for pos := cmdLinePos["realmOpt"]; pos < len(stArgs); pos = pos + 2 {
+		switch stArgs[pos] {
+		case "--param", "-param", "--note", "-note", "--since", "-since":
+		default:
+			DebugLog("chkHistorySyntax failed - unexpected argument '%+v' in command line", stArgs[pos])
+			return false
+		}
+		if pos+1 >= len(stArgs) || strings.HasPrefix(stArgs[pos+1], "-") {
+			DebugLog("chkHistorySyntax failed - missing value for flag '%+v'", stArgs[pos])
+			return false
+		}
+	}
+	return true
+}
@@ -73,7 +73,7 @@ func TestCliArg(t *testing.T) {
 }
 
 func TestCliFlags(t *testing.T) {
-	os.Args = []string{"saptune", "note", "list", "--format", "json", "--force", "--dryrun", "--help", "--version", "--colorscheme", "full-green-zebra", "--show-non-compliant", "--non-compliance-check", "--wrongflag", "--unknownflag=none", "--force-color", "--fun"}
+	os.Args = []string{"saptune", "note", "list", "--format", "json", "--force", "--dryrun", "--help", "--version", "--colorscheme", "full-green-zebra", "--show-non-compliant", "--non-compliance-check", "--wrongflag", "--unknownflag=none", "--force-color", "--fun", "--to-baseline", "--atomic", "--param", "vm.swappiness", "--note", "1680803", "--since", "2024-01-31"}
 	// parse command line, to get the test parameters
 	saptArgs, saptFlags = ParseCliArgs()
 
@@ -110,12 +110,21 @@ func TestCliFlags(t *testing.T) {
 	if !IsFlagSet("fun") {
 		t.Errorf("Test failed, expected 'fun' flag as 'true', but got 'false'")
 	}
+	if !IsFlagSet("to-baseline") {
+		t.Errorf("Test failed, expected 'to-baseline' flag as 'true', but got 'false'")
+	}
+	if !IsFlagSet("atomic") {
+		t.Errorf("Test failed, expected 'atomic' flag as 'true', but got 'false'")
+	}
 
 	expected := "json"
 	actual := GetFlagVal("format")
 	if actual != expected {
 		t.Errorf("Test failed, expected: '%s', got: '%s'", expected, actual)
 	}
+	if GetFlagVal("param") != "vm.swappiness" || GetFlagVal("note") != "1680803" || GetFlagVal("since") != "2024-01-31" {
+		t.Errorf("Test failed, wrong values of the history flags: '%s', '%s', '%s'", GetFlagVal("param"), GetFlagVal("note"), GetFlagVal("since"))
+	}
 
 	expected = ""
 	actual = GetFlagVal("unknownflag")
@@ -508,6 +517,101 @@ func TestChkCliSyntax(t *testing.T) {
 		t.Errorf("Test failed, expected good syntax, but got 'wrong'")
 	}
 
+	// saptune revert all [--to-baseline]
+	// {"saptune", "revert", "all", "--to-baseline"} -> ok
+	os.Args = []string{"saptune", "revert", "all", "--to-baseline"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if !ChkCliSyntax() {
+		t.Errorf("Test failed, expected good syntax, but got 'wrong'")
+	}
+
+	// {"saptune", "--to-baseline", "revert", "all"} -> wrong
+	os.Args = []string{"saptune", "--to-baseline", "revert", "all"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if ChkCliSyntax() {
+		t.Errorf("Test failed, expected wrong syntax, but got 'good'")
+	}
+
+	// {"saptune", "note", "revert", "--to-baseline", "1680803"} -> wrong
+	os.Args = []string{"saptune", "note", "revert", "--to-baseline", "1680803"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if ChkCliSyntax() {
+		t.Errorf("Test failed, expected wrong syntax, but got 'good'")
+	}
+
+	// saptune note apply [--atomic] NOTEID
+	// saptune solution apply [--atomic] SOLUTIONNAME
+	// {"saptune", "note", "apply", "--atomic", "1680803"} -> ok
+	os.Args = []string{"saptune", "note", "apply", "--atomic", "1680803"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if !ChkCliSyntax() {
+		t.Errorf("Test failed, expected good syntax, but got 'wrong'")
+	}
+
+	// {"saptune", "solution", "apply", "--atomic", "HANA"} -> ok
+	os.Args = []string{"saptune", "solution", "apply", "--atomic", "HANA"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if !ChkCliSyntax() {
+		t.Errorf("Test failed, expected good syntax, but got 'wrong'")
+	}
+
+	// {"saptune", "note", "apply", "1680803", "--atomic"} -> wrong
+	os.Args = []string{"saptune", "note", "apply", "1680803", "--atomic"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if ChkCliSyntax() {
+		t.Errorf("Test failed, expected wrong syntax, but got 'good'")
+	}
+
+	// {"saptune", "solution", "change", "--atomic", "HANA"} -> wrong
+	os.Args = []string{"saptune", "solution", "change", "--atomic", "HANA"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if ChkCliSyntax() {
+		t.Errorf("Test failed, expected wrong syntax, but got 'good'")
+	}
+
+	// saptune history [--param KEY] [--note NOTEID] [--since TIME]
+	// {"saptune", "history", "--param", "vm.swappiness"} -> ok
+	os.Args = []string{"saptune", "history", "--param", "vm.swappiness"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if !ChkCliSyntax() {
+		t.Errorf("Test failed, expected good syntax, but got 'wrong'")
+	}
+
+	// {"saptune", "--format", "json", "history", "--since", "2024-01-31", "--note", "1680803", "--param", "vm.swappiness"} -> ok
+	os.Args = []string{"saptune", "--format", "json", "history", "--since", "2024-01-31", "--note", "1680803", "--param", "vm.swappiness"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if !ChkCliSyntax() {
+		t.Errorf("Test failed, expected good syntax, but got 'wrong'")
+	}
+
+	// {"saptune", "history", "--note"} -> wrong
+	os.Args = []string{"saptune", "history", "--note"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if ChkCliSyntax() {
+		t.Errorf("Test failed, expected wrong syntax, but got 'good'")
+	}
+
+	// {"saptune", "history", "--note", "--param", "vm.swappiness"} -> wrong
+	os.Args = []string{"saptune", "history", "--note", "--param", "vm.swappiness"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if ChkCliSyntax() {
+		t.Errorf("Test failed, expected wrong syntax, but got 'good'")
+	}
+
+	// {"saptune", "history", "--note", "1680803", "all"} -> wrong
+	os.Args = []string{"saptune", "history", "--note", "1680803", "all"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if ChkCliSyntax() {
+		t.Errorf("Test failed, expected wrong syntax, but got 'good'")
+	}
+
+	// {"saptune", "note", "list", "--note", "1680803"} -> wrong
+	os.Args = []string{"saptune", "note", "list", "--note", "1680803"}
+	saptArgs, saptFlags = ParseCliArgs()
+	if ChkCliSyntax() {
+		t.Errorf("Test failed, expected wrong syntax, but got 'good'")
+	}
+
 	// reset CLI flags and args
 	saptArgs = []string{}
 	saptFlags = map[string]string{}
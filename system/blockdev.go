@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -16,6 +17,20 @@ type BlockDev struct {
 	BlockAttributes map[string]map[string]string
 }
 
+// procMountInfo contains the mount points with the major and minor number
+// of the mounted devices
+var procMountInfo = "/proc/self/mountinfo"
+
+// sysDevBlockDir maps the major and minor numbers to the block devices
+var sysDevBlockDir = "/sys/dev/block"
+
+// sysClassBlockDir contains all block devices including the partitions
+var sysClassBlockDir = "/sys/class/block"
+
+// mpathStartFile contains the values of the queue attributes of the
+// multipath paths before saptune changed them for the first time
+var mpathStartFile = "/run/saptune/mpath_paths"
+
 // IsSched matches block device scheduler tag
 var IsSched = regexp.MustCompile(`^IO_SCHEDULER_\w+\-?\d*$`)
 
@@ -28,6 +43,22 @@ var IsRahead = regexp.MustCompile(`^READ_AHEAD_KB_\w+\-?\d*$`)
 // IsMsect matches block device max_sectors_kb tag
 var IsMsect = regexp.MustCompile(`^MAX_SECTORS_KB_\w+\-?\d*$`)
 
+// IsRqAffinity matches block device rq_affinity tag
+var IsRqAffinity = regexp.MustCompile(`^RQ_AFFINITY_\w+\-?\d*$`)
+
+// IsNomerges matches block device nomerges tag
+var IsNomerges = regexp.MustCompile(`^NOMERGES_\w+\-?\d*$`)
+
+// IsWbtLat matches block device wbt_lat_usec tag
+var IsWbtLat = regexp.MustCompile(`^WBT_LAT_USEC_\w+\-?\d*$`)
+
+// IsAddRandom matches block device add_random tag
+var IsAddRandom = regexp.MustCompile(`^ADD_RANDOM_\w+\-?\d*$`)
+
+// IsIOSched matches block device I/O scheduler tunable tag
+// (e.g. 'IOSCHED.read_expire_sda' for queue/iosched/read_expire of sda)
+var IsIOSched = regexp.MustCompile(`^IOSCHED\.(\w+)_([^_]+)$`)
+
 var isVD = regexp.MustCompile(`^x?vd\w+$`)
 
 // devices like /dev/nvme0n1 are the NVME storage namespaces: the devices you
@@ -185,6 +216,16 @@ func CollectBlockDeviceInfo() []string {
 		maxsectkb, _ := GetSysString(path.Join("block", bdev, "queue", "max_sectors_kb"))
 		blockMap["MAX_SECTORS_KB"] = maxsectkb
 
+		blockMap["RQ_AFFINITY"] = getOptQueueAttr(bdev, "rq_affinity")
+		blockMap["NOMERGES"] = getOptQueueAttr(bdev, "nomerges")
+		blockMap["WBT_LAT_USEC"] = getOptQueueAttr(bdev, "wbt_lat_usec")
+		blockMap["ADD_RANDOM"] = getOptQueueAttr(bdev, "add_random")
+		// the tunables of the current I/O scheduler
+		_, tunables := ListDir(path.Join("/sys/block", bdev, "queue", "iosched"), "")
+		for _, tunable := range tunables {
+			blockMap["IOSCHED."+tunable] = getOptQueueAttr(bdev, path.Join("iosched", tunable))
+		}
+
 		nrTagsFile := path.Join("block", bdev, "mq", "0", "nr_tags")
 		nrtags := ""
 		if _, err := os.Stat(path.Join("/sys", nrTagsFile)); err == nil {
@@ -227,6 +268,27 @@ func CollectBlockDeviceInfo() []string {
 	return bdevConf.AllBlockDevs
 }
 
+// getOptQueueAttr returns the value of an optional queue attribute of a
+// block device or an empty string, if the attribute is not available
+func getOptQueueAttr(bdev, attr string) string {
+	attrFile := path.Join("block", bdev, "queue", attr)
+	if _, err := os.Stat(path.Join("/sys", attrFile)); err != nil {
+		return ""
+	}
+	val, _ := GetSysString(attrFile)
+	return val
+}
+
+// SplitIOSchedKey returns the I/O scheduler tunable and the block device of
+// an I/O scheduler tunable key (e.g. 'IOSCHED.read_expire_sda')
+func SplitIOSchedKey(key string) (string, string) {
+	fields := IsIOSched.FindStringSubmatch(key)
+	if len(fields) < 3 {
+		return "", ""
+	}
+	return fields[1], fields[2]
+}
+
 // storeBlockDeviceInfo stores block device information to file blockdev.run
 // only used in txtparser
 // storeSectionInfo stores INIFile section information to section directory
@@ -272,3 +334,196 @@ func GetAvailBlockInfo(info, tag string) []string {
 	}
 	return ret
 }
+
+// getMountDevice returns the kernel name of the block device (e.g. 'dm-3'
+// or 'sda2') mounted at the mount point or an empty string, if the mount
+// point is not available or not backed by a block device
+func getMountDevice(mountPoint string) string {
+	mountInfo, err := os.ReadFile(procMountInfo)
+	if err != nil {
+		WarningLog("failed to read '%s' - %v", procMountInfo, err)
+		return ""
+	}
+	majMin := ""
+	mountPoint = path.Clean(mountPoint)
+	for _, line := range strings.Split(string(mountInfo), "\n") {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[4] != mountPoint {
+			continue
+		}
+		// the last entry wins in case of over-mounts
+		majMin = fields[2]
+	}
+	if majMin == "" {
+		return ""
+	}
+	dev, err := filepath.EvalSymlinks(path.Join(sysDevBlockDir, majMin))
+	if err != nil {
+		// no block device (e.g. nfs or tmpfs)
+		return ""
+	}
+	return path.Base(dev)
+}
+
+// resolveBlockDevice resolves a block device through the LVM,
+// device-mapper and partition stack down to the disks or multipath devices
+func resolveBlockDevice(dev string) []string {
+	devs := []string{}
+	if uuid, err := os.ReadFile(path.Join(sysClassBlockDir, dev, "dm", "uuid")); err == nil && strings.HasPrefix(string(uuid), "mpath-") {
+		// multipath device - the paths are handled by the device
+		return append(devs, dev)
+	}
+	_, slaves := ListDir(path.Join(sysClassBlockDir, dev, "slaves"), "")
+	if len(slaves) != 0 {
+		// device-mapper device (e.g. LVM)
+		for _, slave := range slaves {
+			devs = append(devs, resolveBlockDevice(slave)...)
+		}
+		return devs
+	}
+	if _, err := os.Stat(path.Join(sysClassBlockDir, dev, "partition")); err == nil {
+		// partition - the parent directory is the disk
+		if devPath, err := filepath.EvalSymlinks(path.Join(sysClassBlockDir, dev)); err == nil {
+			return append(devs, path.Base(path.Dir(devPath)))
+		}
+	}
+	return append(devs, dev)
+}
+
+// GetBlockDevicesByMount returns the block devices backing the file
+// systems mounted at the mount points. Only block devices valid for the
+// block device tuning (see getValidBlockDevices) are returned
+func GetBlockDevicesByMount(validDevs []string, mountPoints ...string) []string {
+	ret := []string{}
+	found := make(map[string]bool)
+	for _, mountPoint := range mountPoints {
+		mntDev := getMountDevice(mountPoint)
+		if mntDev == "" {
+			InfoLog("mount point '%s' not available or not backed by a block device", mountPoint)
+			continue
+		}
+		for _, dev := range resolveBlockDevice(mntDev) {
+			found[dev] = true
+		}
+	}
+	for _, dev := range validDevs {
+		if found[dev] {
+			ret = append(ret, dev)
+		}
+	}
+	return ret
+}
+
+// GetMpathPaths returns the paths (the dm slaves) of a multipath device or
+// an empty list, if the device is not a multipath device
+func GetMpathPaths(dev string) []string {
+	uuid, err := os.ReadFile(path.Join(sysClassBlockDir, dev, "dm", "uuid"))
+	if err != nil || !strings.HasPrefix(string(uuid), "mpath-") {
+		return []string{}
+	}
+	_, paths := ListDir(path.Join(sysClassBlockDir, dev, "slaves"), "multipath paths")
+	return paths
+}
+
+// GetMpathOfPath returns the multipath device, which uses the block device
+// as path or an empty string, if the block device is not a multipath path
+func GetMpathOfPath(dev string) string {
+	_, holders := ListDir(path.Join(sysClassBlockDir, dev, "holders"), "")
+	for _, holder := range holders {
+		for _, mpath := range GetMpathPaths(holder) {
+			if mpath == dev {
+				return holder
+			}
+		}
+	}
+	return ""
+}
+
+// SaveMpathPathStart saves the current value of the queue attribute of all
+// paths of a multipath device, if not already saved. So the start value of
+// each path is available for RestoreMpathPathStart, as the paths can differ
+// from the multipath device
+func SaveMpathPathStart(dev, attr string) {
+	paths := GetMpathPaths(dev)
+	if len(paths) == 0 {
+		return
+	}
+	start := readOptionFile(mpathStartFile)
+	changed := false
+	for _, mpath := range paths {
+		skey := attr + "@" + mpath
+		if _, ok := start[skey]; ok {
+			// remember the value before saptune changed it
+			// for the first time
+			continue
+		}
+		start[skey] = getQueueValue(mpath, attr)
+		changed = true
+	}
+	if changed {
+		writeOptionFile(mpathStartFile, start)
+	}
+}
+
+// RestoreMpathPathStart restores the queue attribute of all paths of a
+// multipath device to the values saved by SaveMpathPathStart and removes
+// the saved values
+func RestoreMpathPathStart(dev, attr string) error {
+	start := readOptionFile(mpathStartFile)
+	errs := make([]error, 0)
+	for _, mpath := range GetMpathPaths(dev) {
+		skey := attr + "@" + mpath
+		val, ok := start[skey]
+		if !ok {
+			continue
+		}
+		delete(start, skey)
+		if val == "" || val == "NA" {
+			// attribute not available for the path
+			continue
+		}
+		if err := os.WriteFile(path.Join(sysClassBlockDir, mpath, "queue", attr), []byte(val), 0644); err != nil {
+			WarningLog("failed to restore '%s' of path '%s' of multipath device '%s' to '%s' - %v", attr, mpath, dev, val, err)
+			errs = append(errs, err)
+		}
+	}
+	writeOptionFile(mpathStartFile, start)
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to restore '%s' for one or more paths of multipath device '%s': %v", attr, dev, errs)
+	}
+	return nil
+}
+
+// getQueueValue returns the current value of a queue attribute of a block
+// device. For the scheduler only the selected entry is returned
+func getQueueValue(dev, attr string) string {
+	val, err := os.ReadFile(path.Join(sysClassBlockDir, dev, "queue", attr))
+	if err != nil {
+		return "NA"
+	}
+	for _, choice := range strings.Fields(string(val)) {
+		if len(choice) > 2 && choice[0] == '[' && choice[len(choice)-1] == ']' {
+			return choice[1 : len(choice)-1]
+		}
+	}
+	return strings.TrimSpace(string(val))
+}
+
+// GetMpathPathDiffs compares the queue attribute (e.g. 'scheduler' or
+// 'nr_requests') of all paths of a multipath device with the value of the
+// multipath device. It returns the differing paths as '<path>=<value>'
+func GetMpathPathDiffs(dev, attr string) []string {
+	diffs := []string{}
+	paths := GetMpathPaths(dev)
+	if len(paths) == 0 {
+		return diffs
+	}
+	mpathVal := getQueueValue(dev, attr)
+	for _, mpath := range paths {
+		if val := getQueueValue(mpath, attr); val != mpathVal {
+			diffs = append(diffs, mpath+"="+val)
+		}
+	}
+	return diffs
+}
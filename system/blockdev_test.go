@@ -131,3 +131,209 @@ func TestGetAvailBlockInfo(t *testing.T) {
 	bdevFile := path.Join(SaptuneSectionDir, "/blockdev.run")
 	_ = os.Remove(bdevFile)
 }
+
+func TestGetBlockDevicesByMount(t *testing.T) {
+	oldMountInfo := procMountInfo
+	oldDevBlockDir := sysDevBlockDir
+	oldClassBlockDir := sysClassBlockDir
+	defer func() {
+		procMountInfo = oldMountInfo
+		sysDevBlockDir = oldDevBlockDir
+		sysClassBlockDir = oldClassBlockDir
+	}()
+	tstDir := t.TempDir()
+	procMountInfo = path.Join(tstDir, "mountinfo")
+	sysDevBlockDir = path.Join(tstDir, "dev", "block")
+	sysClassBlockDir = path.Join(tstDir, "class", "block")
+	devDir := path.Join(tstDir, "devices")
+
+	mountInfo := `22 1 8:2 / / rw,relatime shared:1 - xfs /dev/sda2 rw
+36 22 253:3 / /hana/data rw,relatime shared:2 - xfs /dev/mapper/vg-data rw
+37 22 253:5 / /hana/log rw,relatime shared:3 - xfs /dev/mapper/vg-log rw
+38 22 0:45 / /hana/shared rw,relatime shared:4 - nfs4 srv:/shared rw
+`
+	if err := os.WriteFile(procMountInfo, []byte(mountInfo), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// devices: partition sda2 of sda, LVM dm-3 on multipath dm-1 and
+	// partition sdc2, LVM dm-5 on nvme0n1
+	for _, dir := range []string{"sda/sda2", "sdc/sdc2", "dm-1/dm", "dm-3/slaves", "dm-5/slaves", "nvme0n1"} {
+		if err := os.MkdirAll(path.Join(devDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{"sda/sda2/partition": "2", "sdc/sdc2/partition": "2", "dm-1/dm/uuid": "mpath-3600a098038304", "dm-3/slaves/dm-1": "", "dm-3/slaves/sdc2": "", "dm-5/slaves/nvme0n1": ""}
+	for file, cont := range files {
+		if err := os.WriteFile(path.Join(devDir, file), []byte(cont), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.MkdirAll(sysDevBlockDir, 0755)
+	_ = os.MkdirAll(sysClassBlockDir, 0755)
+	for majMin, dev := range map[string]string{"8:2": "sda/sda2", "253:3": "dm-3", "253:5": "dm-5"} {
+		if err := os.Symlink(path.Join(devDir, dev), path.Join(sysDevBlockDir, majMin)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for dev, target := range map[string]string{"sda": "sda", "sda2": "sda/sda2", "sdc": "sdc", "sdc2": "sdc/sdc2", "dm-1": "dm-1", "dm-3": "dm-3", "dm-5": "dm-5", "nvme0n1": "nvme0n1"} {
+		if err := os.Symlink(path.Join(devDir, target), path.Join(sysClassBlockDir, dev)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	validDevs := []string{"sda", "sdb", "sdc", "dm-1", "nvme0n1"}
+	if devs := GetBlockDevicesByMount(validDevs, "/hana/data"); !reflect.DeepEqual(devs, []string{"sdc", "dm-1"}) {
+		t.Errorf("got '%v'", devs)
+	}
+	if devs := GetBlockDevicesByMount(validDevs, "/hana/data", "/hana/log/"); !reflect.DeepEqual(devs, []string{"sdc", "dm-1", "nvme0n1"}) {
+		t.Errorf("got '%v'", devs)
+	}
+	if devs := GetBlockDevicesByMount(validDevs, "/"); !reflect.DeepEqual(devs, []string{"sda"}) {
+		t.Errorf("got '%v'", devs)
+	}
+	// nfs and not available mount points
+	if devs := GetBlockDevicesByMount(validDevs, "/hana/shared", "/usr/sap"); len(devs) != 0 {
+		t.Errorf("got '%v'", devs)
+	}
+}
+
+func TestGetMpathPathDiffs(t *testing.T) {
+	oldClassBlockDir := sysClassBlockDir
+	defer func() { sysClassBlockDir = oldClassBlockDir }()
+	sysClassBlockDir = t.TempDir()
+
+	// multipath device dm-0 with the paths sdb, sdc and sdd, disk sda
+	for _, dir := range []string{"dm-0/dm", "dm-0/slaves", "dm-0/queue", "sda/queue", "sdb/queue", "sdc/queue", "sdd/queue", "sdc/holders"} {
+		if err := os.MkdirAll(path.Join(sysClassBlockDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"dm-0/dm/uuid":           "mpath-3600a098038304\n",
+		"dm-0/slaves/sdb":        "",
+		"dm-0/slaves/sdc":        "",
+		"dm-0/slaves/sdd":        "",
+		"sdc/holders/dm-0":       "",
+		"dm-0/queue/scheduler":   "none\n",
+		"dm-0/queue/nr_requests": "256\n",
+		"sdb/queue/scheduler":    "mq-deadline kyber bfq [none]\n",
+		"sdc/queue/scheduler":    "[mq-deadline] kyber bfq none\n",
+		"sdd/queue/scheduler":    "mq-deadline kyber bfq [none]\n",
+		"sdb/queue/nr_requests":  "256\n",
+		"sdc/queue/nr_requests":  "256\n",
+		"sdd/queue/nr_requests":  "256\n",
+	}
+	for file, cont := range files {
+		if err := os.WriteFile(path.Join(sysClassBlockDir, file), []byte(cont), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if paths := GetMpathPaths("dm-0"); !reflect.DeepEqual(paths, []string{"sdb", "sdc", "sdd"}) {
+		t.Errorf("got '%v'", paths)
+	}
+	if paths := GetMpathPaths("sda"); len(paths) != 0 {
+		t.Errorf("got '%v'", paths)
+	}
+	if diffs := GetMpathPathDiffs("dm-0", "scheduler"); !reflect.DeepEqual(diffs, []string{"sdc=mq-deadline"}) {
+		t.Errorf("got '%v'", diffs)
+	}
+	if diffs := GetMpathPathDiffs("dm-0", "nr_requests"); len(diffs) != 0 {
+		t.Errorf("got '%v'", diffs)
+	}
+	// missing attribute of a path
+	if diffs := GetMpathPathDiffs("dm-0", "read_ahead_kb"); len(diffs) != 0 {
+		t.Errorf("got '%v'", diffs)
+	}
+	if diffs := GetMpathPathDiffs("sda", "scheduler"); len(diffs) != 0 {
+		t.Errorf("got '%v'", diffs)
+	}
+	if mpath := GetMpathOfPath("sdc"); mpath != "dm-0" {
+		t.Errorf("got '%s'", mpath)
+	}
+	if mpath := GetMpathOfPath("sda"); mpath != "" {
+		t.Errorf("got '%s'", mpath)
+	}
+}
+
+func TestMpathPathStart(t *testing.T) {
+	oldClassBlockDir := sysClassBlockDir
+	oldStartFile := mpathStartFile
+	defer func() {
+		sysClassBlockDir = oldClassBlockDir
+		mpathStartFile = oldStartFile
+	}()
+	tstDir := t.TempDir()
+	sysClassBlockDir = path.Join(tstDir, "block")
+	mpathStartFile = path.Join(tstDir, "mpath_paths")
+
+	// multipath device dm-0 with the paths sdb and sdc with different
+	// start values
+	for _, dir := range []string{"dm-0/dm", "dm-0/slaves", "dm-0/queue", "sdb/queue", "sdc/queue"} {
+		if err := os.MkdirAll(path.Join(sysClassBlockDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"dm-0/dm/uuid":             "mpath-3600a098038304\n",
+		"dm-0/slaves/sdb":          "",
+		"dm-0/slaves/sdc":          "",
+		"dm-0/queue/read_ahead_kb": "4096\n",
+		"sdb/queue/read_ahead_kb":  "128\n",
+		"sdc/queue/read_ahead_kb":  "512\n",
+	}
+	for file, cont := range files {
+		if err := os.WriteFile(path.Join(sysClassBlockDir, file), []byte(cont), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setPaths := func(val string) {
+		for _, mpath := range []string{"sdb", "sdc"} {
+			if err := os.WriteFile(path.Join(sysClassBlockDir, mpath, "queue", "read_ahead_kb"), []byte(val), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	SaveMpathPathStart("dm-0", "read_ahead_kb")
+	setPaths("16384")
+	// the start values are only saved for the first change
+	SaveMpathPathStart("dm-0", "read_ahead_kb")
+	setPaths("8192")
+	if err := RestoreMpathPathStart("dm-0", "read_ahead_kb"); err != nil {
+		t.Error(err)
+	}
+	if val := getQueueValue("sdb", "read_ahead_kb"); val != "128" {
+		t.Errorf("expected '128', got '%s'", val)
+	}
+	if val := getQueueValue("sdc", "read_ahead_kb"); val != "512" {
+		t.Errorf("expected '512', got '%s'", val)
+	}
+	if _, err := os.Stat(mpathStartFile); !os.IsNotExist(err) {
+		t.Errorf("file '%s' should be removed", mpathStartFile)
+	}
+	// nothing saved, nothing restored
+	setPaths("8192")
+	if err := RestoreMpathPathStart("dm-0", "read_ahead_kb"); err != nil {
+		t.Error(err)
+	}
+	if val := getQueueValue("sdb", "read_ahead_kb"); val != "8192" {
+		t.Errorf("expected '8192', got '%s'", val)
+	}
+	// no multipath device
+	SaveMpathPathStart("sdb", "read_ahead_kb")
+	if _, err := os.Stat(mpathStartFile); !os.IsNotExist(err) {
+		t.Errorf("file '%s' should not exist", mpathStartFile)
+	}
+}
+
+func TestSplitIOSchedKey(t *testing.T) {
+	if tunable, bdev := SplitIOSchedKey("IOSCHED.read_expire_sda"); tunable != "read_expire" || bdev != "sda" {
+		t.Errorf("got '%s', '%s'", tunable, bdev)
+	}
+	if tunable, bdev := SplitIOSchedKey("IOSCHED.slice_idle_dm-0"); tunable != "slice_idle" || bdev != "dm-0" {
+		t.Errorf("got '%s', '%s'", tunable, bdev)
+	}
+	if tunable, bdev := SplitIOSchedKey("NRREQ_sda"); tunable != "" || bdev != "" {
+		t.Errorf("got '%s', '%s'", tunable, bdev)
+	}
+}
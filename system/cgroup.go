@@ -0,0 +1,135 @@
+package system
+
+// Handle the cgroup resource control of systemd units by saptune owned
+// unit drop-in files
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// systemdUnitDir is the directory for the saptune unit drop-in files
+var systemdUnitDir = "/etc/systemd/system"
+
+// DefaultCgroupUnit is the unit used by the section [cgroup], if no unit
+// is selected by the section tag 'unit'
+const DefaultCgroupUnit = "sap.slice"
+
+// IsCgroupProperty matches the supported resource control properties
+var IsCgroupProperty = regexp.MustCompile(`^(CPUWeight|StartupCPUWeight|IOWeight|StartupIOWeight|MemoryMin|MemoryLow|MemoryHigh|MemoryMax|AllowedCPUs|AllowedMemoryNodes)$`)
+
+// isMemSize matches a memory size with an optional K, M, G or T suffix
+var isMemSize = regexp.MustCompile(`^(\d+)([KMGT]?)$`)
+
+// notSetValues are the values reported by 'systemctl show' for not set
+// properties
+var notSetValues = map[string]bool{"": true, "[not set]": true, "18446744073709551615": true}
+
+// unitSections maps the unit types to the unit file sections, which
+// support the resource control settings
+var unitSections = map[string]string{
+	"slice":   "Slice",
+	"service": "Service",
+	"scope":   "Scope",
+	"socket":  "Socket",
+	"mount":   "Mount",
+	"swap":    "Swap",
+}
+
+// cgroupDropInFile returns the name of the saptune drop-in file for a
+// property of a unit
+func cgroupDropInFile(unit, property string) string {
+	return path.Join(systemdUnitDir, unit+".d", fmt.Sprintf("saptune-%s.conf", property))
+}
+
+// NormCgroupValue checks the value of a resource control property and
+// returns it in the format reported by 'systemctl show'
+func NormCgroupValue(property, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasSuffix(property, "Weight"):
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 1 || weight > 10000 {
+			return "", fmt.Errorf("weight needs to be a number between 1 and 10000")
+		}
+		return strconv.Itoa(weight), nil
+	case strings.HasPrefix(property, "Memory"):
+		if value == "infinity" {
+			return value, nil
+		}
+		size := isMemSize.FindStringSubmatch(strings.ToUpper(value))
+		if size == nil {
+			return "", fmt.Errorf("memory size needs to be a number with an optional K, M, G or T suffix or 'infinity'")
+		}
+		bytes, _ := strconv.ParseUint(size[1], 10, 64)
+		shift := map[string]uint{"": 0, "K": 10, "M": 20, "G": 30, "T": 40}[size[2]]
+		return strconv.FormatUint(bytes<<shift, 10), nil
+	case strings.HasPrefix(property, "Allowed"):
+		cpus, err := ParseCPUList(value)
+		if err != nil {
+			return "", err
+		}
+		return FormatCPUList(cpus), nil
+	}
+	return "", fmt.Errorf("unsupported property")
+}
+
+// GetCgroupProperty returns the value of a resource control property of a
+// unit or 'NA', if not set
+func GetCgroupProperty(unit, property string) string {
+	val, err := SystemctlShowProperty(unit, property)
+	if err != nil || notSetValues[val] {
+		return "NA"
+	}
+	if strings.HasPrefix(property, "Allowed") {
+		// 'systemctl show' may report the CPU list space separated
+		if cpus, err := ParseCPUList(strings.Join(strings.Fields(val), ",")); err == nil {
+			val = FormatCPUList(cpus)
+		}
+	}
+	return val
+}
+
+// SetCgroupDropIn writes the saptune drop-in file for a resource control
+// property of a unit. Returns true, if the drop-in file was changed.
+// A 'systemctl daemon-reload' is needed afterwards
+func SetCgroupDropIn(unit, property, value string) (bool, error) {
+	unitType := unit[strings.LastIndex(unit, ".")+1:]
+	section, ok := unitSections[unitType]
+	if !ok {
+		return false, ErrorLog("resource control is not supported for unit '%s'", unit)
+	}
+	dropInFile := cgroupDropInFile(unit, property)
+	content := fmt.Sprintf("### %s\n### file autogenerated by saptune!\n###\n### Please do NOT change or delete!\n###\n\n[%s]\n%s=%s\n", dropInFile, section, property, value)
+	if old, err := os.ReadFile(dropInFile); err == nil && string(old) == content {
+		return false, nil
+	}
+	if err := os.MkdirAll(path.Dir(dropInFile), 0755); err != nil {
+		return false, ErrorLog("failed to create needed directories for the drop-in file '%s': %v", dropInFile, err)
+	}
+	if err := os.WriteFile(dropInFile, []byte(content), 0644); err != nil {
+		return false, ErrorLog("failed to write drop-in file '%s': %v", dropInFile, err)
+	}
+	return true, nil
+}
+
+// RemoveCgroupDropIn removes the saptune drop-in file for a resource
+// control property of a unit and the drop-in directory, if empty.
+// Returns true, if the drop-in file was removed.
+// A 'systemctl daemon-reload' is needed afterwards
+func RemoveCgroupDropIn(unit, property string) (bool, error) {
+	dropInFile := cgroupDropInFile(unit, property)
+	if err := os.Remove(dropInFile); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, ErrorLog("failed to remove drop-in file '%s': %v", dropInFile, err)
+	}
+	// remove the drop-in directory, if empty
+	_ = os.Remove(path.Dir(dropInFile))
+	return true, nil
+}
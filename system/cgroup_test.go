@@ -0,0 +1,92 @@
+package system
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestNormCgroupValue(t *testing.T) {
+	tests := []struct{ property, value, exp string }{
+		{"CPUWeight", "1000", "1000"},
+		{"IOWeight", " 50", "50"},
+		{"MemoryLow", "8G", "8589934592"},
+		{"MemoryMin", "512m", "536870912"},
+		{"MemoryMax", "infinity", "infinity"},
+		{"MemoryHigh", "4096", "4096"},
+		{"AllowedCPUs", "3,0-2,8", "0-3,8"},
+	}
+	for _, test := range tests {
+		val, err := NormCgroupValue(test.property, test.value)
+		if err != nil || val != test.exp {
+			t.Errorf("%s: expected '%s', got '%s', '%v'", test.property, test.exp, val, err)
+		}
+	}
+	for _, wrong := range [][]string{{"CPUWeight", "0"}, {"CPUWeight", "high"}, {"MemoryLow", "10%"}, {"AllowedCPUs", "all"}, {"TasksMax", "10"}} {
+		if _, err := NormCgroupValue(wrong[0], wrong[1]); err == nil {
+			t.Errorf("expected an error for '%s=%s'", wrong[0], wrong[1])
+		}
+	}
+}
+
+func TestCgroupDropIn(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "cgrouptest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldUnitDir := systemdUnitDir
+	defer func() { systemdUnitDir = oldUnitDir }()
+	systemdUnitDir = tmpDir
+
+	changed, err := SetCgroupDropIn("sap.slice", "CPUWeight", "1000")
+	if !changed || err != nil {
+		t.Error(changed, err)
+	}
+	dropInFile := path.Join(tmpDir, "sap.slice.d", "saptune-CPUWeight.conf")
+	content, _ := os.ReadFile(dropInFile)
+	expCont := "### " + dropInFile + "\n### file autogenerated by saptune!\n###\n### Please do NOT change or delete!\n###\n\n[Slice]\nCPUWeight=1000\n"
+	if string(content) != expCont {
+		t.Errorf("got '%s'", string(content))
+	}
+	changed, err = SetCgroupDropIn("sap.slice", "CPUWeight", "1000")
+	if changed || err != nil {
+		t.Error(changed, err)
+	}
+	if _, err := SetCgroupDropIn("sap.target", "CPUWeight", "1000"); err == nil {
+		t.Error("expected an error for a target unit")
+	}
+	changed, err = RemoveCgroupDropIn("sap.slice", "CPUWeight")
+	if !changed || err != nil {
+		t.Error(changed, err)
+	}
+	if _, err := os.Stat(path.Dir(dropInFile)); !os.IsNotExist(err) {
+		t.Errorf("drop-in directory '%s' should be removed", path.Dir(dropInFile))
+	}
+	changed, err = RemoveCgroupDropIn("sap.slice", "CPUWeight")
+	if changed || err != nil {
+		t.Error(changed, err)
+	}
+}
+
+func TestGetCgroupProperty(t *testing.T) {
+	oldSystemctlCmd := systemctlCmd
+	defer func() { systemctlCmd = oldSystemctlCmd }()
+	systemctlCmd = "/usr/bin/false"
+	if val := GetCgroupProperty("sap.slice", "CPUWeight"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	tmpDir, err := os.MkdirTemp("", "cgrouptest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	// fake systemctl reporting the CPU list space separated
+	systemctlCmd = path.Join(tmpDir, "systemctl")
+	if err := os.WriteFile(systemctlCmd, []byte("#!/bin/sh\necho '0 1 2 3 8'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if val := GetCgroupProperty("sap.slice", "AllowedCPUs"); val != "0-3,8" {
+		t.Errorf("expected '0-3,8', got '%s'", val)
+	}
+}
@@ -64,7 +64,15 @@ var defaultCommand = map[string]bool{
 	"refresh applied":             false,
 	"verify applied":              false,
 	"revert all":                  false,
+	"block apply":                 false,
+	"snapshot create":             false,
+	"snapshot list":               false,
+	"snapshot show":               false,
+	"snapshot diff":               false,
+	"snapshot restore":            false,
 	"lock remove":                 false,
+	"history":                     false,
+	"drift":                       false,
 	"check":                       false,
 	"status":                      false,
 	"version":                     false,
@@ -99,6 +107,8 @@ func supportedRACMap() map[string]bool {
 	supportedRAC["verify applied"] = true
 	supportedRAC["version"] = true
 	supportedRAC["check"] = true
+	supportedRAC["history"] = true
+	supportedRAC["drift"] = true
 
 	return supportedRAC
 }
@@ -148,6 +158,9 @@ func lockCommandsMap() map[string]bool {
 	lockCommand["configure TrentoASDP"] = true
 	lockCommand["refresh applied"] = true
 	lockCommand["revert all"] = true
+	lockCommand["block apply"] = true
+	lockCommand["snapshot create"] = true
+	lockCommand["snapshot restore"] = true
 
 	return lockCommand
 }
@@ -10,15 +10,14 @@ import (
 	"path"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // constant definition
 const (
-	notSupportedX86 = "System does not support Intel's performance bias setting"
-	notSupportedIBM = "Subcommand not supported on POWER."
-	cpuDirSys       = "devices/system/cpu"
+	cpuDirSys = "devices/system/cpu"
 )
 
 var cpuPlatformFile = "/sys/devices/cpu/caps/pmu_name"
@@ -32,45 +31,35 @@ var govCnt = 0
 var latCnt = 0
 
 // GetPerfBias retrieve CPU performance configuration from the system
+// read directly from /sys/devices/system/cpu/cpu*/power/energy_perf_bias
 func GetPerfBias() string {
-	isPBCpu := regexp.MustCompile(`analyzing CPU \d+`)
-	isPBias := regexp.MustCompile(`perf-bias: \d+`)
 	setAll := true
 	str := ""
 	oldpb := "99"
-	cmdName := cpupowerCmd
-	cmdArgs := []string{"-c", "all", "info", "-b"}
 
 	if !supportsPerfBiasSettings() {
 		return "all:none"
 	}
 
-	cmdOut, err := exec.Command(cmdName, cmdArgs...).CombinedOutput()
-	if err != nil {
-		WarningLog("There was an error running external command 'cpupower -c all info -b': %v, output: %s", err, cmdOut)
-		return "all:none"
-	}
-
-	for k, line := range strings.Split(strings.TrimSpace(string(cmdOut)), "\n") {
-		switch {
-		case line == notSupportedX86 || line == notSupportedIBM:
-			// safety net - check already done in supportsPerfBiasSettings()
-			return "all:none"
-		case isPBCpu.MatchString(line):
-			str = str + fmt.Sprintf("cpu%d", k/2)
-		case isPBias.MatchString(line):
-			pb := strings.Split(line, ":")
-			if len(pb) < 2 {
-				continue
-			}
-			if oldpb == "99" {
-				oldpb = strings.TrimSpace(pb[1])
-			}
-			if oldpb != strings.TrimSpace(pb[1]) {
-				setAll = false
-			}
-			str = str + fmt.Sprintf(":%s ", strings.TrimSpace(pb[1]))
+	for _, cpu := range OnlineCPUs() {
+		val, err := os.ReadFile(path.Join(cpuDir, cpu, "power", "energy_perf_bias"))
+		if err != nil {
+			DebugLog("GetPerfBias - energy_perf_bias not available for CPU '%s' - %v", cpu, err)
+			continue
 		}
+		pb := strings.TrimSpace(string(val))
+		if oldpb == "99" {
+			// starting point
+			oldpb = pb
+		}
+		if oldpb != pb {
+			setAll = false
+		}
+		str = str + fmt.Sprintf("%s:%s ", cpu, pb)
+	}
+	if oldpb == "99" {
+		// no readable value found
+		return "all:none"
 	}
 	if setAll {
 		str = "all:" + oldpb
@@ -78,30 +67,41 @@ func GetPerfBias() string {
 	return strings.TrimSpace(str)
 }
 
-// SetPerfBias set CPU performance configuration to the system using 'cpupower' command
+// SetPerfBias set CPU performance configuration to the system
+// written directly to /sys/devices/system/cpu/cpu*/power/energy_perf_bias
+// A failure does not stop the setting of the remaining CPUs, all failures
+// are returned together
 func SetPerfBias(value string) error {
-	//cmd := exec.Command("cpupower", "-c", "all", "set", "-b", value)
 	if !supportsPerfBiasSettings() {
 		return nil
 	}
 
-	cpu := ""
-	for k, entry := range strings.Fields(value) {
+	errs := make([]error, 0)
+	for _, entry := range strings.Fields(value) {
 		fields := strings.Split(entry, ":")
-		if len(fields) < 2 {
+		if len(fields) < 2 || fields[1] == "none" {
+			// not supported by the system
 			continue
 		}
-		if fields[0] != "all" {
-			cpu = strconv.Itoa(k)
-		} else {
-			cpu = fields[0]
+		cpus := []string{fields[0]}
+		if fields[0] == "all" {
+			cpus = OnlineCPUs()
 		}
-		out, err := exec.Command(cpupowerCmd, "-c", cpu, "set", "-b", fields[1]).CombinedOutput()
-		if err != nil {
-			WarningLog("failed to invoke external command 'cpupower -c %s set -b %s': %v, output: %s", cpu, fields[1], err, out)
-			return err
+		for _, cpu := range cpus {
+			pbFile := path.Join(cpuDir, cpu, "power", "energy_perf_bias")
+			if _, err := os.Stat(pbFile); err != nil {
+				WarningLog("energy_perf_bias not supported for cpu '%s', skipping.", cpu)
+				continue
+			}
+			if err := os.WriteFile(pbFile, []byte(fields[1]), 0644); err != nil {
+				WarningLog("failed to set 'energy_perf_bias' of cpu '%s' to '%s' - %v", cpu, fields[1], err)
+				errs = append(errs, fmt.Errorf("cpu '%s' - %v", cpu, err))
+			}
 		}
 	}
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to set 'energy_perf_bias' for one or more CPUs: %v", errs)
+	}
 	return nil
 }
 
@@ -156,20 +156,17 @@ func SecureBootEnabled() bool {
 }
 
 // supportsPerfBias check, if the system will support CPU performance settings
+// the kernel provides the energy_perf_bias file only for CPUs supporting
+// Intel's performance bias setting
 func supportsPerfBias() bool {
-	cmdName := cpupowerCmd
-	cmdArgs := []string{"info", "-b"}
-
-	if !CmdIsAvailable(cmdName) {
-		WarningLog("command '%s' not found", cmdName)
+	cpus := OnlineCPUs()
+	if len(cpus) == 0 {
+		PrintLog(perfCnt, "warn", "Perf Bias settings not supported by the system")
 		return false
 	}
-	cmdOut, err := exec.Command(cmdName, cmdArgs...).CombinedOutput()
-	PrintLog(perfCnt, "info", "supportsPerfBias - command 'cpupower info -b' returned: %s - %v", string(cmdOut), err)
-	if err != nil || (err == nil && (strings.Contains(string(cmdOut), notSupportedX86) || strings.Contains(string(cmdOut), notSupportedIBM))) {
-		// does not support perf bias
+	if _, err := os.Stat(path.Join(cpuDir, cpus[0], "power", "energy_perf_bias")); err != nil {
 		if SecureBootEnabled() {
-			PrintLog(perfCnt, "warn", "Cannot set Perf Bias when SecureBoot is enabled, skipping")
+			PrintLog(perfCnt, "warn", "Perf Bias settings not available with SecureBoot enabled, skipping")
 		} else {
 			PrintLog(perfCnt, "warn", "Perf Bias settings not supported by the system")
 		}
@@ -241,31 +238,127 @@ func SetGovernor(value string) error {
 		return nil
 	}
 
-	cpu := ""
-	tst := ""
-	for k, entry := range strings.Fields(value) {
+	vals, cpuLists := cpuValueGroups(value)
+	for _, val := range vals {
+		cpu := cpuLists[val]
+		// check each cpu of the cpu list, as mixed hardware may support
+		// different governors
+		validCPUs, invalidCPUs := governorCPUs(cpu, val)
+		if len(invalidCPUs) != 0 {
+			WarningLog("'%s' is not a valid governor for cpu '%s', skipping.", val, FormatCPUList(invalidCPUs))
+			if len(validCPUs) == 0 {
+				continue
+			}
+			cpu = FormatCPUList(validCPUs)
+		}
+		out, err := exec.Command(cpupowerCmd, "-c", cpu, "frequency-set", "-g", val).CombinedOutput()
+		if err != nil {
+			WarningLog("failed to invoke external command 'cpupower -c %s frequency-set -g %s': %v, output: %s", cpu, val, err, out)
+			return err
+		}
+	}
+	return nil
+}
+
+// governorCPUs splits the CPUs of a CPU list (e.g. '0,2-3' or 'all') into
+// the CPUs supporting the governor and the CPUs not supporting it
+func governorCPUs(cpuList, gov string) ([]int, []int) {
+	validCPUs := []int{}
+	invalidCPUs := []int{}
+	cpus := []int{}
+	if cpuList == "all" {
+		for _, cpuName := range OnlineCPUs() {
+			if cpu, err := strconv.Atoi(strings.TrimPrefix(cpuName, "cpu")); err == nil {
+				cpus = append(cpus, cpu)
+			}
+		}
+	} else {
+		var err error
+		if cpus, err = ParseCPUList(cpuList); err != nil {
+			WarningLog("%v", err)
+		}
+	}
+	for _, cpu := range cpus {
+		if isValidGovernor("cpu"+strconv.Itoa(cpu), gov) {
+			validCPUs = append(validCPUs, cpu)
+		} else {
+			invalidCPUs = append(invalidCPUs, cpu)
+		}
+	}
+	return validCPUs, invalidCPUs
+}
+
+// cpuValueGroups groups the CPUs of a per CPU value like
+// 'cpu0:performance cpu1:powersave cpu2:performance' or 'all:performance'
+// by their values.
+// Returns the values in the order of their first appearance and the related
+// CPU lists (e.g. '0,2' or 'all') as used by 'cpupower -c'
+func cpuValueGroups(value string) ([]string, map[string]string) {
+	vals := []string{}
+	cpus := make(map[string][]int)
+	cpuLists := make(map[string]string)
+	for _, entry := range strings.Fields(value) {
 		fields := strings.Split(entry, ":")
 		if len(fields) < 2 {
 			continue
 		}
-		if fields[0] != "all" {
-			cpu = strconv.Itoa(k)
-			tst = cpu
-		} else {
-			cpu = fields[0]
-			tst = "cpu0"
+		val := fields[1]
+		if _, ok := cpuLists[val]; !ok {
+			vals = append(vals, val)
+			cpuLists[val] = ""
 		}
-		if !isValidGovernor(tst, fields[1]) {
-			WarningLog("'%s' is not a valid governor for cpu '%s', skipping.", fields[1], tst)
+		if fields[0] == "all" {
+			cpuLists[val] = "all"
 			continue
 		}
-		out, err := exec.Command(cpupowerCmd, "-c", cpu, "frequency-set", "-g", fields[1]).CombinedOutput()
+		cpu, err := strconv.Atoi(strings.TrimPrefix(fields[0], "cpu"))
 		if err != nil {
-			WarningLog("failed to invoke external command 'cpupower -c %s frequency-set -g %s': %v, output: %s", cpu, fields[1], err, out)
-			return err
+			WarningLog("invalid cpu '%s' in value '%s', skipping.", fields[0], value)
+			continue
 		}
+		cpus[val] = append(cpus[val], cpu)
 	}
-	return nil
+	for _, val := range vals {
+		if cpuLists[val] == "all" {
+			continue
+		}
+		sort.Ints(cpus[val])
+		cpuLists[val] = FormatCPUList(cpus[val])
+	}
+	// skip values without valid cpus
+	validVals := []string{}
+	for _, val := range vals {
+		if cpuLists[val] != "" {
+			validVals = append(validVals, val)
+		}
+	}
+	return validVals, cpuLists
+}
+
+// OnlineCPUs returns the names (cpu0, cpu1, ...) of the online CPUs sorted
+// by the CPU number
+func OnlineCPUs() []string {
+	cpuNums := []int{}
+	dirCont, err := os.ReadDir(cpuDir)
+	if err != nil {
+		WarningLog("failed to read cpu directory '%s' - %v", cpuDir, err)
+		return []string{}
+	}
+	for _, entry := range dirCont {
+		cpuName := entry.Name()
+		if !isCPU.MatchString(cpuName) || !isCPUonline(cpuName) {
+			continue
+		}
+		if cpu, err := strconv.Atoi(strings.TrimPrefix(cpuName, "cpu")); err == nil {
+			cpuNums = append(cpuNums, cpu)
+		}
+	}
+	sort.Ints(cpuNums)
+	cpus := make([]string, 0, len(cpuNums))
+	for _, cpu := range cpuNums {
+		cpus = append(cpus, fmt.Sprintf("cpu%d", cpu))
+	}
+	return cpus
 }
 
 // supportsGovernorSettings checks, if governor settings supported by the system
@@ -612,3 +705,57 @@ func GetdmaLatency() string {
 	ret := fmt.Sprintf("%v", binary.LittleEndian.Uint32(latency))
 	return ret
 }
+
+// ParseCPUList returns the sorted CPU numbers of a CPU list like
+// '0-3,8,10-11' as used in sysfs and procfs
+func ParseCPUList(list string) ([]int, error) {
+	cpus := []int{}
+	seen := make(map[int]bool)
+	for _, entry := range strings.Split(strings.TrimSpace(list), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		fields := strings.SplitN(entry, "-", 2)
+		start, err := strconv.Atoi(fields[0])
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("invalid CPU '%s' in CPU list '%s'", fields[0], list)
+		}
+		end := start
+		if len(fields) == 2 {
+			end, err = strconv.Atoi(fields[1])
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid CPU range '%s' in CPU list '%s'", entry, list)
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			if !seen[cpu] {
+				seen[cpu] = true
+				cpus = append(cpus, cpu)
+			}
+		}
+	}
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("empty CPU list '%s'", list)
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// FormatCPUList returns the CPU list representation ('0-3,8,10-11') of a
+// sorted list of CPU numbers
+func FormatCPUList(cpus []int) string {
+	entries := []string{}
+	for i := 0; i < len(cpus); i++ {
+		start := cpus[i]
+		for i+1 < len(cpus) && cpus[i+1] == cpus[i]+1 {
+			i++
+		}
+		if cpus[i] == start {
+			entries = append(entries, strconv.Itoa(start))
+		} else {
+			entries = append(entries, fmt.Sprintf("%d-%d", start, cpus[i]))
+		}
+	}
+	return strings.Join(entries, ",")
+}
@@ -3,8 +3,8 @@ package system
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -24,58 +24,103 @@ func TestCheckCPUState(t *testing.T) {
 	}
 }
 
-func TestSupportsPerfBias(t *testing.T) {
-	if !IsUserRoot() {
-		t.Skip("the test requires root access")
+// setUpPerfBias creates a cpu directory with two CPUs supporting Intel's
+// performance bias setting
+func setUpPerfBias(t *testing.T, tstCPUDir string) {
+	t.Helper()
+	for _, cpu := range []string{"cpu0", "cpu1"} {
+		powerDir := path.Join(tstCPUDir, cpu, "power")
+		if err := os.MkdirAll(powerDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(powerDir, "energy_perf_bias"), []byte("6\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
 	}
-
-	if !supportsPerfBias() {
-		t.Skip("System does not support Intel's performance bias setting. Skipping test")
+	if err := os.WriteFile(path.Join(tstCPUDir, "cpu1", "online"), []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
 	}
-	cmdName := "/usr/bin/cpupower"
-	cmdArgs := []string{"info", "-b"}
+}
 
-	cmdOut, err := exec.Command(cmdName, cmdArgs...).CombinedOutput()
-	if err != nil || (err == nil && (strings.Contains(string(cmdOut), notSupportedX86) || strings.Contains(string(cmdOut), notSupportedIBM))) {
-		t.Error(string(cmdOut))
+func TestSupportsPerfBias(t *testing.T) {
+	tstCPUDir := "/tmp/saptune_perfbias"
+	defer os.RemoveAll(tstCPUDir)
+	oldCPUDir := cpuDir
+	defer func() { cpuDir = oldCPUDir }()
+	cpuDir = tstCPUDir
+	if supportsPerfBias() {
+		t.Errorf("reports supported, but shouldn't")
+	}
+	setUpPerfBias(t, tstCPUDir)
+	if !supportsPerfBias() {
+		t.Errorf("reports not supported, but should")
 	}
 }
 
 func TestGetPerfBias(t *testing.T) {
-	if !IsUserRoot() {
-		t.Skip("the test requires root access")
+	tstCPUDir := "/tmp/saptune_perfbias"
+	defer os.RemoveAll(tstCPUDir)
+	oldCPUDir := cpuDir
+	defer func() { cpuDir = oldCPUDir }()
+	cpuDir = tstCPUDir
+	if value := GetPerfBias(); value != "all:none" {
+		t.Error(value)
 	}
-	value := GetPerfBias()
-	if !supportsPerfBias() {
-		if value != "all:none" {
-			t.Error(value)
-		}
-	} else {
-		if len(value) < 3 {
-			t.Error(value)
-		}
+	setUpPerfBias(t, tstCPUDir)
+	if value := GetPerfBias(); value != "all:6" {
+		t.Error(value)
+	}
+	if err := os.WriteFile(path.Join(tstCPUDir, "cpu1", "power", "energy_perf_bias"), []byte("15\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if value := GetPerfBias(); value != "cpu0:6 cpu1:15" {
+		t.Error(value)
 	}
 }
 
 func TestSetPerfBias(t *testing.T) {
-	if !IsUserRoot() {
-		t.Skip("the test requires root access")
+	tstCPUDir := "/tmp/saptune_perfbias"
+	defer os.RemoveAll(tstCPUDir)
+	oldCPUDir := cpuDir
+	defer func() { cpuDir = oldCPUDir }()
+	cpuDir = tstCPUDir
+	// not supported - nothing to do
+	if err := SetPerfBias("all:15"); err != nil {
+		t.Error(err)
 	}
-	oldPerf := GetPerfBias()
-	err := SetPerfBias("all:15")
-	if err != nil {
+	setUpPerfBias(t, tstCPUDir)
+	if err := SetPerfBias("all:15"); err != nil {
 		t.Error(err)
 	}
-	val := GetPerfBias()
-	if val != "all:15" && val != "all:none" {
+	if val := GetPerfBias(); val != "all:15" {
 		t.Error(val)
 	}
-	if oldPerf != "" && oldPerf != "all:none" {
-		// set test value back
-		err := SetPerfBias(oldPerf)
-		if err != nil {
-			t.Error(err)
-		}
+	if err := SetPerfBias("cpu0:0 cpu1:6"); err != nil {
+		t.Error(err)
+	}
+	if val := GetPerfBias(); val != "cpu0:0 cpu1:6" {
+		t.Error(val)
+	}
+	// CPU without energy_perf_bias is skipped
+	if err := SetPerfBias("cpu1:15 cpu7:15"); err != nil {
+		t.Error(err)
+	}
+	if val := GetPerfBias(); val != "cpu0:0 cpu1:15" {
+		t.Error(val)
+	}
+	// a failing CPU does not stop the setting of the remaining CPUs
+	pbFile := path.Join(tstCPUDir, "cpu0", "power", "energy_perf_bias")
+	if err := os.Remove(pbFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(pbFile, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPerfBias("all:0"); err == nil {
+		t.Error("should return an error and not 'nil'")
+	}
+	if val, _ := os.ReadFile(path.Join(tstCPUDir, "cpu1", "power", "energy_perf_bias")); string(val) != "0" {
+		t.Errorf("cpu1 not set, value is '%s'", val)
 	}
 }
 
@@ -251,9 +296,6 @@ func TestSecureBootEnabled(t *testing.T) {
 	if !SecureBootEnabled() {
 		t.Errorf("reports secure boot disabled, but should be enabled")
 	}
-	if supportsPerfBias() {
-		t.Errorf("reports supported, but shouldn't")
-	}
 	efiVarsDir = oldEfiDir
 }
 
@@ -264,17 +306,7 @@ func TestMissingCmd(t *testing.T) {
 	if err := os.Rename(cmdName, savName); err != nil {
 		t.Error(err)
 	}
-	value := GetPerfBias()
-	if value != "all:none" {
-		t.Error(value)
-	}
-	if err := SetPerfBias("all:15"); err != nil {
-		t.Error(err)
-	}
-	if supportsPerfBias() {
-		t.Errorf("reports supported, but shouldn't")
-	}
-	if supportsGovernorSettings(value) {
+	if supportsGovernorSettings("") {
 		t.Errorf("reports supported, but shouldn't")
 	}
 	if err := SetGovernor("all:performance"); err != nil {
@@ -289,13 +321,6 @@ func TestCPUErrorCases(t *testing.T) {
 	oldCpupowerCmd := cpupowerCmd
 	defer func() { cpupowerCmd = oldCpupowerCmd }()
 	cpupowerCmd = "/usr/bin/false"
-	val := GetPerfBias()
-	if val != "all:none" {
-		t.Error(val)
-	}
-	if err := SetPerfBias("all:15"); err != nil {
-		t.Errorf("should return 'nil' and not '%v'\n", err)
-	}
 	if isValidGovernor("cpu0", "performance") {
 		if err := SetGovernor("all:performance"); err == nil {
 			t.Error("should return an error and not 'nil'")
@@ -305,14 +330,17 @@ func TestCPUErrorCases(t *testing.T) {
 			t.Errorf("should return 'nil' and not '%v'\n", err)
 		}
 	}
-	if supportsPerfBias() {
-		t.Error("reports supported, but shouldn't")
-	}
 	cpupowerCmd = oldCpupowerCmd
 
 	oldCPUDir := cpuDir
 	defer func() { cpuDir = oldCPUDir }()
 	cpuDir = "/unknownDir"
+	if val := GetPerfBias(); val != "all:none" {
+		t.Error(val)
+	}
+	if supportsPerfBias() {
+		t.Error("reports supported, but shouldn't")
+	}
 	gval := GetGovernor()
 	if len(gval) != 1 {
 		t.Errorf("should return only one entry, but returns: %+v", gval)
@@ -350,3 +378,69 @@ func TestCPUErrorCases(t *testing.T) {
 	}
 	cpuDir = oldCPUDir
 }
+
+func TestCPUList(t *testing.T) {
+	cpus, err := ParseCPUList("8, 0-3,2,10-11")
+	if err != nil {
+		t.Error(err)
+	}
+	if val := FormatCPUList(cpus); val != "0-3,8,10-11" {
+		t.Errorf("expected '0-3,8,10-11', got '%s'", val)
+	}
+	if val := FormatCPUList([]int{5}); val != "5" {
+		t.Errorf("expected '5', got '%s'", val)
+	}
+	for _, list := range []string{"", "a", "3-1", "1-", "-1"} {
+		if _, err := ParseCPUList(list); err == nil {
+			t.Errorf("expected an error for CPU list '%s'", list)
+		}
+	}
+}
+
+func TestCPUValueGroups(t *testing.T) {
+	vals, cpuLists := cpuValueGroups("cpu0:performance cpu1:powersave cpu2:performance cpu3:performance cpux:ondemand")
+	if strings.Join(vals, " ") != "performance powersave" {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+	if cpuLists["performance"] != "0,2-3" || cpuLists["powersave"] != "1" {
+		t.Errorf("wrong cpu lists '%+v'", cpuLists)
+	}
+	vals, cpuLists = cpuValueGroups("all:6")
+	if len(vals) != 1 || cpuLists["6"] != "all" {
+		t.Errorf("wrong values '%+v' or cpu lists '%+v'", vals, cpuLists)
+	}
+	vals, _ = cpuValueGroups("performance")
+	if len(vals) != 0 {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+}
+
+func TestGovernorCPUs(t *testing.T) {
+	oldCPUDir := cpuDir
+	defer func() { cpuDir = oldCPUDir }()
+	cpuDir = path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/testdata/cpu")
+	// cpu1 does not support governors at all
+	for _, cpuList := range []string{"all", "0-1"} {
+		validCPUs, invalidCPUs := governorCPUs(cpuList, "performance")
+		if !reflect.DeepEqual(validCPUs, []int{0}) || !reflect.DeepEqual(invalidCPUs, []int{1}) {
+			t.Errorf("'%s': wrong cpus '%+v', '%+v'", cpuList, validCPUs, invalidCPUs)
+		}
+	}
+	validCPUs, invalidCPUs := governorCPUs("0", "ondemand")
+	if len(validCPUs) != 0 || !reflect.DeepEqual(invalidCPUs, []int{0}) {
+		t.Errorf("wrong cpus '%+v', '%+v'", validCPUs, invalidCPUs)
+	}
+}
+
+func TestOnlineCPUs(t *testing.T) {
+	oldCPUDir := cpuDir
+	defer func() { cpuDir = oldCPUDir }()
+	cpuDir = path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/testdata/cpu")
+	if cpus := OnlineCPUs(); strings.Join(cpus, " ") != "cpu0 cpu1" {
+		t.Errorf("wrong online cpus '%+v'", cpus)
+	}
+	cpuDir = "/unknown_dir"
+	if cpus := OnlineCPUs(); len(cpus) != 0 {
+		t.Errorf("wrong online cpus '%+v'", cpus)
+	}
+}
@@ -0,0 +1,139 @@
+package system
+
+// read and write the cpufreq settings of the CPUs directly using
+// /sys/devices/system/cpu/cpu*/cpufreq, without the need of 'cpupower'
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// GetCPUFreqAttr returns the values of the cpufreq attribute (e.g.
+// 'energy_performance_preference' or 'scaling_max_freq') of all online
+// CPUs. CPUs without the attribute are not part of the result, so an empty
+// map means, the attribute is not supported by the system
+func GetCPUFreqAttr(attr string) map[string]string {
+	vals := make(map[string]string)
+	for _, cpu := range OnlineCPUs() {
+		val, err := os.ReadFile(path.Join(cpuDir, cpu, "cpufreq", attr))
+		if err != nil {
+			DebugLog("GetCPUFreqAttr - cpufreq attribute '%s' not available for CPU '%s' - %v", attr, cpu, err)
+			continue
+		}
+		vals[cpu] = strings.TrimSpace(string(val))
+	}
+	return vals
+}
+
+// SetCPUFreqAttr sets the cpufreq attribute (e.g.
+// 'energy_performance_preference' or 'scaling_max_freq') of the CPUs to the
+// values of a per CPU value like 'all:balance_performance' or
+// 'cpu0:2000000 cpu1:3000000'
+// A failure does not stop the setting of the remaining CPUs, all failures
+// are returned together
+func SetCPUFreqAttr(attr, value string) error {
+	errs := make([]error, 0)
+	for _, entry := range strings.Fields(value) {
+		fields := strings.Split(entry, ":")
+		if len(fields) < 2 || fields[1] == "none" {
+			// not supported by the system
+			continue
+		}
+		cpus := []string{fields[0]}
+		if fields[0] == "all" {
+			cpus = OnlineCPUs()
+		}
+		for _, cpu := range cpus {
+			if err := setCPUFreqVal(cpu, attr, fields[1]); err != nil {
+				errs = append(errs, fmt.Errorf("cpu '%s' - %v", cpu, err))
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to set '%s' for one or more CPUs: %v", attr, errs)
+	}
+	return nil
+}
+
+// setCPUFreqVal sets the cpufreq attribute of a single CPU
+// A CPU without the attribute is skipped, a value not supported by the CPU
+// is returned as error
+func setCPUFreqVal(cpu, attr, val string) error {
+	attrFile := path.Join(cpuDir, cpu, "cpufreq", attr)
+	if _, err := os.Stat(attrFile); err != nil {
+		InfoLog("cpufreq attribute '%s' not supported for cpu '%s', skipping.", attr, cpu)
+		return nil
+	}
+	if attr == "energy_performance_preference" && eppFixedByGovernor(cpu, val) {
+		return fmt.Errorf("'%s' can not be set to '%s', because the 'intel_pstate' driver only supports 'performance' for the scaling governor 'performance'", attr, val)
+	}
+	if !isValidCPUFreqVal(cpu, attr, val) {
+		return fmt.Errorf("'%s' is not a valid value for '%s'", val, attr)
+	}
+	return os.WriteFile(attrFile, []byte(val), 0644)
+}
+
+// eppFixedByGovernor checks, if the energy performance preference of the CPU
+// is fixed by the scaling governor. The active 'intel_pstate' driver
+// rejects all preferences except 'performance' (or the raw value 0) for the
+// scaling governor 'performance' with EBUSY
+func eppFixedByGovernor(cpu, val string) bool {
+	if val == "performance" || val == "0" {
+		return false
+	}
+	driver, _ := os.ReadFile(path.Join(cpuDir, cpu, "cpufreq", "scaling_driver"))
+	gov, _ := os.ReadFile(path.Join(cpuDir, cpu, "cpufreq", "scaling_governor"))
+	return strings.TrimSpace(string(driver)) == "intel_pstate" && strings.TrimSpace(string(gov)) == "performance"
+}
+
+// isValidCPUFreqVal checks, if the value is supported for the cpufreq
+// attribute of the CPU
+// energy_performance_preference - one of the available preferences or a
+// raw EPP value (0-255)
+// scaling_min_freq, scaling_max_freq - a frequency in kHz within the
+// hardware limits of the CPU
+func isValidCPUFreqVal(cpu, attr, val string) bool {
+	switch attr {
+	case "energy_performance_preference":
+		if epp, err := strconv.Atoi(val); err == nil {
+			return epp >= 0 && epp <= 255
+		}
+		avail, err := os.ReadFile(path.Join(cpuDir, cpu, "cpufreq", "energy_performance_available_preferences"))
+		if err != nil {
+			// let the kernel decide
+			return true
+		}
+		for _, pref := range strings.Fields(string(avail)) {
+			if pref == val {
+				return true
+			}
+		}
+		return false
+	case "scaling_min_freq", "scaling_max_freq":
+		freq, err := strconv.Atoi(val)
+		if err != nil || freq < 0 {
+			return false
+		}
+		if minFreq, err := strconv.Atoi(CPUFreqLimit(cpu, "min")); err == nil && freq < minFreq {
+			return false
+		}
+		if maxFreq, err := strconv.Atoi(CPUFreqLimit(cpu, "max")); err == nil && freq > maxFreq {
+			return false
+		}
+	}
+	return true
+}
+
+// CPUFreqLimit returns the minimal ('min') or maximal ('max') hardware
+// frequency of the CPU in kHz (cpuinfo_min_freq, cpuinfo_max_freq) or an
+// empty string, if not available
+func CPUFreqLimit(cpu, limit string) string {
+	val, err := os.ReadFile(path.Join(cpuDir, cpu, "cpufreq", "cpuinfo_"+limit+"_freq"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(val))
+}
@@ -0,0 +1,117 @@
+package system
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+// setUpCPUFreq creates a cpu directory with two CPUs supporting cpufreq
+func setUpCPUFreq(t *testing.T, tstCPUDir string) {
+	t.Helper()
+	files := map[string]string{
+		"energy_performance_preference":            "balance_performance",
+		"energy_performance_available_preferences": "default performance balance_performance balance_power power",
+		"scaling_min_freq":                         "800000",
+		"scaling_max_freq":                         "3000000",
+		"cpuinfo_min_freq":                         "800000",
+		"cpuinfo_max_freq":                         "3500000",
+	}
+	for _, cpu := range []string{"cpu0", "cpu1"} {
+		freqDir := path.Join(tstCPUDir, cpu, "cpufreq")
+		if err := os.MkdirAll(freqDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for file, content := range files {
+			if err := os.WriteFile(path.Join(freqDir, file), []byte(content+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := os.WriteFile(path.Join(tstCPUDir, "cpu1", "online"), []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCPUFreqAttr(t *testing.T) {
+	tstCPUDir := "/tmp/saptune_cpufreq"
+	defer os.RemoveAll(tstCPUDir)
+	setUpCPUFreq(t, tstCPUDir)
+	oldCPUDir := cpuDir
+	defer func() { cpuDir = oldCPUDir }()
+	cpuDir = tstCPUDir
+
+	vals := GetCPUFreqAttr("energy_performance_preference")
+	if len(vals) != 2 || vals["cpu0"] != "balance_performance" || vals["cpu1"] != "balance_performance" {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+	if err := SetCPUFreqAttr("energy_performance_preference", "all:performance"); err != nil {
+		t.Error(err)
+	}
+	if err := SetCPUFreqAttr("scaling_max_freq", "cpu0:2000000 cpu1:3500000"); err != nil {
+		t.Error(err)
+	}
+	vals = GetCPUFreqAttr("energy_performance_preference")
+	if vals["cpu0"] != "performance" || vals["cpu1"] != "performance" {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+	vals = GetCPUFreqAttr("scaling_max_freq")
+	if vals["cpu0"] != "2000000" || vals["cpu1"] != "3500000" {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+
+	// invalid values are not set and reported, the valid values of the
+	// other CPUs are set nevertheless
+	if err := SetCPUFreqAttr("energy_performance_preference", "cpu0:turbo cpu1:128"); err == nil {
+		t.Error("expected an error")
+	}
+	if err := SetCPUFreqAttr("scaling_min_freq", "cpu0:100000 cpu1:fast"); err == nil {
+		t.Error("expected an error")
+	}
+	vals = GetCPUFreqAttr("energy_performance_preference")
+	if vals["cpu0"] != "performance" || vals["cpu1"] != "128" {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+	vals = GetCPUFreqAttr("scaling_min_freq")
+	if vals["cpu0"] != "800000" || vals["cpu1"] != "800000" {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+	// not supported
+	if vals := GetCPUFreqAttr("unknown_attr"); len(vals) != 0 {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+	if err := SetCPUFreqAttr("unknown_attr", "all:1 cpu0:none"); err != nil {
+		t.Error(err)
+	}
+
+	// intel_pstate with governor 'performance' only supports the
+	// preference 'performance'
+	for _, cpu := range []string{"cpu0", "cpu1"} {
+		_ = os.WriteFile(path.Join(tstCPUDir, cpu, "cpufreq", "scaling_driver"), []byte("intel_pstate\n"), 0644)
+	}
+	_ = os.WriteFile(path.Join(tstCPUDir, "cpu1", "cpufreq", "scaling_governor"), []byte("performance\n"), 0644)
+	if err := SetCPUFreqAttr("energy_performance_preference", "all:balance_power"); err == nil {
+		t.Error("expected an error")
+	}
+	vals = GetCPUFreqAttr("energy_performance_preference")
+	if vals["cpu0"] != "balance_power" || vals["cpu1"] != "128" {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+	// all CPUs are set, even if a CPU fails (cpu0 - not writable)
+	_ = os.Remove(path.Join(tstCPUDir, "cpu0", "cpufreq", "scaling_max_freq"))
+	_ = os.Mkdir(path.Join(tstCPUDir, "cpu0", "cpufreq", "scaling_max_freq"), 0755)
+	if err := SetCPUFreqAttr("scaling_max_freq", "all:2500000"); err == nil {
+		t.Error("expected an error")
+	}
+	vals = GetCPUFreqAttr("scaling_max_freq")
+	if vals["cpu1"] != "2500000" {
+		t.Errorf("wrong values '%+v'", vals)
+	}
+
+	if val := CPUFreqLimit("cpu0", "max"); val != "3500000" {
+		t.Error(val)
+	}
+	if val := CPUFreqLimit("cpu7", "min"); val != "" {
+		t.Error(val)
+	}
+}
@@ -194,6 +194,17 @@ func SystemctlIsActive(thing string) (string, error) {
 	return strings.TrimSpace(string(out)), err
 }
 
+// SystemctlShowProperty returns the value of a unit property as reported by
+// 'systemctl show'
+func SystemctlShowProperty(thing, property string) (string, error) {
+	out, err := exec.Command(systemctlCmd, "show", "-p", property, "--value", thing).CombinedOutput()
+	DebugLog("SystemctlShowProperty - /usr/bin/systemctl show -p %s --value %s : '%+v %s'", property, thing, err, strings.TrimSpace(string(out)))
+	if err != nil {
+		return "", ErrorLog("%v - Failed to call systemctl show on %s - %s", err, thing, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // SystemctlDaemonReload calls 'systemctl daemon-reload'
 func SystemctlDaemonReload() error {
 	out, err := exec.Command(systemctlCmd, "daemon-reload").CombinedOutput()
@@ -0,0 +1,55 @@
+package system
+
+// Gather the host facts usable in the expressions of the note definition
+// files (e.g. 'kernel.shmmax = ${MemTotalBytes} * 0.8')
+
+import (
+	"os"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// GetOnlineCPUCount returns the number of online CPUs
+func GetOnlineCPUCount() int {
+	online, err := os.ReadFile(path.Join(cpuDir, "online"))
+	if err == nil {
+		if cpus, err := ParseCPUList(strings.TrimSpace(string(online))); err == nil && len(cpus) > 0 {
+			return len(cpus)
+		}
+	}
+	return runtime.NumCPU()
+}
+
+// HostFacts returns the host facts available as variables in the
+// expressions of the note definition files
+func HostFacts() map[string]uint64 {
+	memInfo := ParseMeminfo()
+	memTotal := memInfo[MemMainTotalKey]
+	numaNodes := uint64(len(GetNUMANodes()))
+	if numaNodes == 0 {
+		// system without NUMA support
+		numaNodes = 1
+	}
+	return map[string]uint64{
+		"MemTotalBytes":  memTotal * 1024,
+		"MemTotalKB":     memTotal,
+		"MemTotalMB":     memTotal / 1024,
+		"SwapTotalKB":    memInfo[MemSwapTotalKey],
+		"PageSize":       uint64(os.Getpagesize()),
+		"HugepageSizeKB": memInfo[MemHugepageSize],
+		"CPUs":           uint64(GetOnlineCPUCount()),
+		"NUMANodes":      numaNodes,
+	}
+}
+
+// GetKernelRelease returns the release of the running kernel
+// (e.g. '6.4.0-150600.23-default')
+func GetKernelRelease() string {
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		WarningLog("failed to read the kernel release - %v", err)
+		return ""
+	}
+	return strings.TrimSpace(string(release))
+}
@@ -0,0 +1,47 @@
+package system
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestGetOnlineCPUCount(t *testing.T) {
+	oldCPUDir := cpuDir
+	defer func() { cpuDir = oldCPUDir }()
+
+	cpuDir = t.TempDir()
+	if err := os.WriteFile(path.Join(cpuDir, "online"), []byte("0-3,8\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if cnt := GetOnlineCPUCount(); cnt != 5 {
+		t.Errorf("expected '5', got '%d'", cnt)
+	}
+	// fallback, if the 'online' file is not available
+	cpuDir = path.Join(cpuDir, "missing")
+	if cnt := GetOnlineCPUCount(); cnt < 1 {
+		t.Errorf("expected at least one CPU, got '%d'", cnt)
+	}
+}
+
+func TestHostFacts(t *testing.T) {
+	facts := HostFacts()
+	for _, fact := range []string{"MemTotalBytes", "MemTotalKB", "MemTotalMB", "SwapTotalKB", "PageSize", "HugepageSizeKB", "CPUs", "NUMANodes"} {
+		if _, ok := facts[fact]; !ok {
+			t.Errorf("missing host fact '%s'", fact)
+		}
+	}
+	if facts["MemTotalBytes"] != facts["MemTotalKB"]*1024 {
+		t.Errorf("wrong memory size - '%+v'", facts)
+	}
+	if facts["CPUs"] < 1 || facts["NUMANodes"] < 1 {
+		t.Errorf("wrong CPU or NUMA node count - '%+v'", facts)
+	}
+}
+
+func TestGetKernelRelease(t *testing.T) {
+	if release := GetKernelRelease(); release == "" || strings.ContainsAny(release, " \n") {
+		t.Errorf("wrong kernel release '%s'", release)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"syscall"
 )
 
 // ReadConfigFile read content of config file
@@ -230,6 +231,54 @@ func CopyFile(srcFile, destFile string) error {
 	return err
 }
 
+// replaceFile replaces the content of a configuration file (e.g. the boot
+// loader configuration or /etc/fstab). The content is written to a temporary
+// file, which is renamed afterwards, so the file is never left incomplete.
+// Permissions and owner of the file are preserved. A copy of the original
+// file is kept as <file>.saptune.bak
+func replaceFile(file string, content []byte) error {
+	if _, err := os.Stat(file + ".saptune.bak"); os.IsNotExist(err) {
+		if err := CopyFile(file, file+".saptune.bak"); err != nil {
+			return fmt.Errorf("failed to create backup of '%s' - %v", file, err)
+		}
+	}
+	mode := os.FileMode(0644)
+	uid, gid := -1, -1
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			uid, gid = int(stat.Uid), int(stat.Gid)
+		}
+	}
+	tmpFile := file + ".saptune.tmp"
+	tmp, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	// O_CREATE is subject to the umask
+	err = tmp.Chmod(mode)
+	if err == nil && uid != -1 {
+		err = tmp.Chown(uid, gid)
+	}
+	if err == nil {
+		_, err = tmp.Write(content)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile, file)
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile)
+		return fmt.Errorf("failed to write '%s' - %v", file, err)
+	}
+	return nil
+}
+
 // GetFiles returns the files from a directory as map
 // skip directories
 func GetFiles(dir string) map[string]string {
@@ -15,10 +15,30 @@ var mountOptionSeparator = regexp.MustCompile("[[:space:]]*,[[:space:]]*")
 
 // IsXFSOption matches xfs options
 var IsXFSOption = regexp.MustCompile(`^xfsopt_\w+$`)
+
+// IsFSOption matches the options of all supported file system types
+// '<fstype>opt_<option>' or '<fstype>opt_<option>@<mount point list>'
+var IsFSOption = regexp.MustCompile(`^(xfs|ext4|btrfs|nfs|nfs4)opt_[^\s@*]+(@\S+)?$`)
+
+// FSOptTypes are the file system types supported in section [filesystem]
+var FSOptTypes = []string{"xfs", "ext4", "btrfs", "nfs", "nfs4"}
 var fstab = "/etc/fstab"
 var mtab = "/etc/mtab"
 var procMounts = "/proc/mounts"
 
+// fstabBackupFile contains the state of the mount options in /etc/fstab
+// before saptune changed them for the first time.
+// Needs to survive a reboot, so it is not located in /run
+var fstabBackupFile = "/var/lib/saptune/working/.fstabbackup"
+
+// mountCmd is the command to remount file systems
+var mountCmd = "mount"
+
+// mountOptNegation contains the mount options, which can not be negated by
+// simply adding or removing the 'no' prefix
+var mountOptNegation = map[string]string{"ro": "rw", "rw": "ro", "sync": "async", "async": "sync"}
+var allFields = regexp.MustCompile(`\S+`)
+
 // MountPoint Represent a mount point entry in /proc/mounts or /etc/fstab
 type MountPoint struct {
 	Device     string
@@ -59,6 +79,49 @@ func (mounts MountPoints) GetByMountPoint(mountPoint string) (MountPoint, bool)
 	return MountPoint{}, false
 }
 
+// IsFSType returns true, if the file system type of a mount point matches
+// the requested file system type. 'nfs' matches 'nfs4' mounts too.
+func IsFSType(fstype, mountType string) bool {
+	if fstype == "nfs" && mountType == "nfs4" {
+		return true
+	}
+	return fstype == mountType
+}
+
+// Filter returns the mount points of the given file system type.
+// If mntPts is not empty, only the listed mount points are returned.
+func (mounts MountPoints) Filter(fstype string, mntPts []string) MountPoints {
+	filtered := make(MountPoints, 0)
+	for _, mount := range mounts {
+		if !IsFSType(fstype, mount.Type) {
+			continue
+		}
+		if len(mntPts) != 0 && !isMntAvail(mount.MountPoint, mntPts) {
+			continue
+		}
+		filtered = append(filtered, mount)
+	}
+	return filtered
+}
+
+// ParseFSOptionKey splits a file system option key of section [filesystem]
+// ('<fstype>opt_<option>@<mount point list>') into the file system type,
+// the mount option and the list of mount points.
+// An empty list of mount points means all mount points of the file system
+// type.
+func ParseFSOptionKey(key string) (string, string, []string) {
+	mntPts := []string{}
+	fields := strings.SplitN(key, "opt_", 2)
+	if len(fields) != 2 {
+		return "", "", mntPts
+	}
+	optFields := strings.SplitN(fields[1], "@", 2)
+	if len(optFields) == 2 {
+		mntPts = strings.Split(optFields[1], ",")
+	}
+	return fields[0], optFields[0], mntPts
+}
+
 // GetByMountOption find a mount point with special mount option.
 // returns a list of mount points containing the option and a second list
 // with mount points missing the option.
@@ -68,7 +131,7 @@ func (mounts MountPoints) GetByMountOption(fstype, mountOption, chkDflt string)
 	mntOK := []string{}
 	mntNok := []string{}
 	for _, mount := range mounts {
-		if mount.Type == fstype {
+		if IsFSType(fstype, mount.Type) {
 			found = false
 			dflt = false
 			for _, opt := range mount.Options {
@@ -178,17 +241,26 @@ func RemountSHM(newSizeMB uint64) error {
 // Returns a list of mount point containing the option and a list of mount
 // point NOT containing the option
 func GetMountOpts(mustExist bool, fstype, fsopt string) ([]string, []string) {
+	return GetMountOptsOfMounts(mustExist, fstype, fsopt, []string{})
+}
+
+// GetMountOptsOfMounts checks if mount points with the given type exists and
+// contain the needed/not needed option.
+// If mntPts is not empty, only the listed mount points are checked.
+// Returns a list of mount point containing the option and a list of mount
+// point NOT containing the option
+func GetMountOptsOfMounts(mustExist bool, fstype, fsopt string, mntPts []string) ([]string, []string) {
 	// Find out mount options
 	chkdflt := "noChk"
 	// check the mounted FS
-	mountProcOk, mountProcNok := ParseProcMounts().GetByMountOption(fstype, fsopt, chkdflt)
+	mountProcOk, mountProcNok := ParseProcMounts().Filter(fstype, mntPts).GetByMountOption(fstype, fsopt, chkdflt)
 	if mustExist {
 		chkdflt = "chkOK"
 	} else {
 		chkdflt = "chkNOK"
 	}
 	// check /etc/fstab to get the not mounted FS as well
-	mountFSTOk, mountFSTNok := ParseFstab().GetByMountOption(fstype, fsopt, chkdflt)
+	mountFSTOk, mountFSTNok := ParseFstab().Filter(fstype, mntPts).GetByMountOption(fstype, fsopt, chkdflt)
 	mntOk := getMounts(mountProcOk, mountFSTOk)
 	mntNok := getMounts(mountProcNok, mountFSTNok)
 	return mntOk, mntNok
@@ -222,3 +294,166 @@ func isMntAvail(mntPt string, poolOfMnts []string) bool {
 	}
 	return ret
 }
+
+// HasOption returns true, if the mount point contains the given mount option
+func (mount MountPoint) HasOption(option string) bool {
+	for _, opt := range mount.Options {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// negateMountOption returns the mount option, which reverses the given
+// mount option (e.g. 'noatime' - 'atime')
+func negateMountOption(option string) string {
+	if neg, ok := mountOptNegation[option]; ok {
+		return neg
+	}
+	if strings.HasPrefix(option, "no") {
+		return strings.TrimPrefix(option, "no")
+	}
+	return "no" + option
+}
+
+// RemountOption remounts a mounted file system to add (set == true) or
+// remove (set == false) a mount option at runtime.
+// Removing is done by remounting with the negated option, so mount options
+// with values (e.g. 'logbsize=256k') can not be removed at runtime.
+func RemountOption(mountPoint, option string, set bool) error {
+	opt := option
+	if !set {
+		if strings.Contains(option, "=") {
+			return fmt.Errorf("mount option '%s' can not be removed from '%s' at runtime", option, mountPoint)
+		}
+		opt = negateMountOption(option)
+	}
+	InfoLog("RemountOption - remount '%s' with option '%s'\n", mountPoint, opt)
+	cmd := exec.Command(mountCmd, "-o", "remount,"+opt, mountPoint)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to invoke external command mount: %v, output: %s", err, out)
+	}
+	return nil
+}
+
+// changeOptionList adds or removes a mount option in a comma separated list
+// of mount options
+func changeOptionList(optList, option string, set bool) string {
+	newOpts := []string{}
+	found := false
+	for _, opt := range mountOptionSeparator.Split(optList, -1) {
+		if opt == option {
+			found = true
+			if !set {
+				continue
+			}
+		}
+		if opt == "defaults" && set && len(mountOptionSeparator.Split(optList, -1)) == 1 {
+			// 'defaults' alone will be replaced by the new option
+			continue
+		}
+		newOpts = append(newOpts, opt)
+	}
+	if set && !found {
+		newOpts = append(newOpts, option)
+	}
+	if len(newOpts) == 0 {
+		newOpts = append(newOpts, "defaults")
+	}
+	return strings.Join(newOpts, ",")
+}
+
+// changeFstabEntries adds or removes a mount option for the given mount
+// points of the given file system type in /etc/fstab.
+// changes contains the mount points as key and true (add option) or
+// false (remove option) as value.
+// The layout of the file is preserved, only the option field is changed.
+// A copy of the original file is kept as /etc/fstab.saptune.bak
+func changeFstabEntries(fstype, option string, changes map[string]bool) error {
+	content, err := os.ReadFile(fstab)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(content), "\n")
+	changed := false
+	for i, line := range lines {
+		fields := consecutiveSpaces.Split(strings.TrimSpace(line), -1)
+		if len(fields) < 4 || fields[0] == "" || fields[0][0] == '#' || !IsFSType(fstype, fields[2]) {
+			continue
+		}
+		set, ok := changes[fields[1]]
+		if !ok {
+			continue
+		}
+		newOpts := changeOptionList(fields[3], option, set)
+		if newOpts == fields[3] {
+			continue
+		}
+		// replace the 4th field, keep the white spaces of the line
+		idx := allFields.FindAllStringIndex(line, -1)
+		lines[i] = line[:idx[3][0]] + newOpts + line[idx[3][1]:]
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	NoticeLog("Changing mount option '%s' in '%s'", option, fstab)
+	return replaceFile(fstab, []byte(strings.Join(lines, "\n")))
+}
+
+// SetFstabOption adds (set == true) or removes (set == false) a mount option
+// for all entries of the given file system type in /etc/fstab.
+// If mntPts is not empty, only the listed mount points are changed.
+// The former state of the mount option is saved for RestoreFstabOption
+func SetFstabOption(fstype, option string, set bool, mntPts []string) error {
+	backup := readOptionFile(fstabBackupFile)
+	changes := make(map[string]bool)
+	for _, mount := range ParseFstab().Filter(fstype, mntPts) {
+		if mount.HasOption(option) == set {
+			continue
+		}
+		changes[mount.MountPoint] = set
+		bkey := fstabBackupKey(fstype, option, mount.MountPoint)
+		if _, ok := backup[bkey]; !ok {
+			// remember the state before saptune changed it
+			// for the first time
+			backup[bkey] = "-"
+			if mount.HasOption(option) {
+				backup[bkey] = "+"
+			}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	writeOptionFile(fstabBackupFile, backup)
+	return changeFstabEntries(fstype, option, changes)
+}
+
+// fstabBackupKey returns the key of a mount point in the fstab backup file.
+// The file system type is part of the key, because the same option can be
+// used for different file system types (e.g. 'nfs' and 'nfs4')
+func fstabBackupKey(fstype, option, mountPoint string) string {
+	return fstype + "opt_" + option + "@" + mountPoint
+}
+
+// RestoreFstabOption restores the former state of a mount option in
+// /etc/fstab as found before saptune changed it for the first time
+func RestoreFstabOption(fstype, option string) error {
+	backup := readOptionFile(fstabBackupFile)
+	changes := make(map[string]bool)
+	prefix := fstabBackupKey(fstype, option, "")
+	for bkey, state := range backup {
+		if !strings.HasPrefix(bkey, prefix) {
+			continue
+		}
+		changes[strings.TrimPrefix(bkey, prefix)] = state == "+"
+		delete(backup, bkey)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	writeOptionFile(fstabBackupFile, backup)
+	return changeFstabEntries(fstype, option, changes)
+}
@@ -273,3 +273,212 @@ func TestGetMountOpts(t *testing.T) {
 		t.Errorf("got: %+v, expected: %+v\n", mountNok, resNok)
 	}
 }
+
+func TestGetMountOptsOfMountsNFS(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "nfstest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldFstab := fstab
+	oldProcMounts := procMounts
+	defer func() {
+		fstab = oldFstab
+		procMounts = oldProcMounts
+	}()
+	fstab = path.Join(tmpDir, "fstab")
+	procMounts = path.Join(tmpDir, "mounts")
+	if err := os.WriteFile(fstab, []byte("nfssrv:/shared /hana/shared nfs4 vers=4.2,rsize=1048576,hard 0 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(procMounts, []byte("nfssrv:/shared /hana/shared nfs4 rw,relatime,vers=4.2,rsize=1048576,hard,proto=tcp 0 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// mounted nfs file system without the option is reported
+	mountOk, mountNok := GetMountOptsOfMounts(true, "nfs4", "vers=4.1", []string{})
+	if len(mountOk) != 0 || !reflect.DeepEqual(mountNok, []string{"/hana/shared"}) {
+		t.Errorf("got: %+v, %+v", mountOk, mountNok)
+	}
+	mountOk, mountNok = GetMountOptsOfMounts(true, "nfs4", "rsize=1048576", []string{})
+	if !reflect.DeepEqual(mountOk, []string{"/hana/shared"}) || len(mountNok) != 0 {
+		t.Errorf("got: %+v, %+v", mountOk, mountNok)
+	}
+}
+
+func TestNegateMountOption(t *testing.T) {
+	for opt, neg := range map[string]string{"noatime": "atime", "relatime": "norelatime", "nobarrier": "barrier", "ro": "rw", "async": "sync"} {
+		if val := negateMountOption(opt); val != neg {
+			t.Errorf("expected '%s' for '%s', got '%s'", neg, opt, val)
+		}
+	}
+}
+
+func TestChangeOptionList(t *testing.T) {
+	if val := changeOptionList("rw,relatime", "noatime", true); val != "rw,relatime,noatime" {
+		t.Errorf("got '%s'", val)
+	}
+	if val := changeOptionList("rw,noatime,attr2", "noatime", false); val != "rw,attr2" {
+		t.Errorf("got '%s'", val)
+	}
+	if val := changeOptionList("defaults", "noatime", true); val != "noatime" {
+		t.Errorf("got '%s'", val)
+	}
+	if val := changeOptionList("noatime", "noatime", false); val != "defaults" {
+		t.Errorf("got '%s'", val)
+	}
+	if val := changeOptionList("noauto,defaults", "noatime", true); val != "noauto,defaults,noatime" {
+		t.Errorf("got '%s'", val)
+	}
+}
+
+func TestRemountOption(t *testing.T) {
+	oldMountCmd := mountCmd
+	defer func() { mountCmd = oldMountCmd }()
+	mountCmd = "/usr/bin/true"
+	if err := RemountOption("/hana/data", "noatime", true); err != nil {
+		t.Error(err)
+	}
+	if err := RemountOption("/hana/data", "logbsize=256k", false); err == nil {
+		t.Error("removing an option with value at runtime should fail")
+	}
+	mountCmd = "/usr/bin/false"
+	if err := RemountOption("/hana/data", "noatime", false); err == nil {
+		t.Error("failed mount command should return an error")
+	}
+}
+
+func TestSetAndRestoreFstabOption(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "fstabtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldFstab := fstab
+	oldBackup := fstabBackupFile
+	defer func() {
+		fstab = oldFstab
+		fstabBackupFile = oldBackup
+	}()
+	fstab = path.Join(tmpDir, "fstab")
+	fstabBackupFile = path.Join(tmpDir, ".fstabbackup")
+
+	fstabCont := `# comment line
+/dev/sda   /hana/data   xfs    defaults         0 0
+/dev/sdb   /hana/log    xfs    rw,noatime       0 0
+/dev/sdc   /home        btrfs  defaults         0 0
+`
+	if err := os.WriteFile(fstab, []byte(fstabCont), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFstabOption("xfs", "noatime", true, []string{}); err != nil {
+		t.Error(err)
+	}
+	expCont := `# comment line
+/dev/sda   /hana/data   xfs    noatime         0 0
+/dev/sdb   /hana/log    xfs    rw,noatime       0 0
+/dev/sdc   /home        btrfs  defaults         0 0
+`
+	content, _ := os.ReadFile(fstab)
+	if string(content) != expCont {
+		t.Errorf("got '%s'", string(content))
+	}
+	if info, err := os.Stat(fstab); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("permissions of '%s' not preserved - %v", fstab, err)
+	}
+	backup, _ := os.ReadFile(fstab + ".saptune.bak")
+	if string(backup) != fstabCont {
+		t.Errorf("wrong backup file content '%s'", string(backup))
+	}
+	// second apply must not change the recorded former state
+	if err := SetFstabOption("xfs", "noatime", false, []string{}); err != nil {
+		t.Error(err)
+	}
+	if err := RestoreFstabOption("xfs", "noatime"); err != nil {
+		t.Error(err)
+	}
+	content, _ = os.ReadFile(fstab)
+	expCont = `# comment line
+/dev/sda   /hana/data   xfs    defaults         0 0
+/dev/sdb   /hana/log    xfs    rw,noatime       0 0
+/dev/sdc   /home        btrfs  defaults         0 0
+`
+	if string(content) != expCont {
+		t.Errorf("got '%s'", string(content))
+	}
+	if _, err := os.Stat(fstabBackupFile); !os.IsNotExist(err) {
+		t.Errorf("backup file '%s' should be removed", fstabBackupFile)
+	}
+
+	// the same option for 'nfs' and 'nfs4' is recorded separately
+	nfsCont := "nfssrv:/shared /hana/shared nfs4 defaults 0 0\n"
+	if err := os.WriteFile(fstab, []byte(nfsCont), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFstabOption("nfs", "noatime", true, []string{}); err != nil {
+		t.Error(err)
+	}
+	if err := SetFstabOption("nfs4", "noatime", true, []string{}); err != nil {
+		t.Error(err)
+	}
+	if err := RestoreFstabOption("nfs4", "noatime"); err != nil {
+		t.Error(err)
+	}
+	content, _ = os.ReadFile(fstab)
+	if string(content) != "nfssrv:/shared /hana/shared nfs4 noatime 0 0\n" {
+		t.Errorf("got '%s'", string(content))
+	}
+	if err := RestoreFstabOption("nfs", "noatime"); err != nil {
+		t.Error(err)
+	}
+	content, _ = os.ReadFile(fstab)
+	if string(content) != nfsCont {
+		t.Errorf("got '%s'", string(content))
+	}
+}
+
+func TestParseFSOptionKey(t *testing.T) {
+	fstype, opt, mntPts := ParseFSOptionKey("xfsopt_nobarrier")
+	if fstype != "xfs" || opt != "nobarrier" || len(mntPts) != 0 {
+		t.Errorf("got '%s', '%s', '%+v'", fstype, opt, mntPts)
+	}
+	fstype, opt, mntPts = ParseFSOptionKey("nfsopt_vers=4.1@/hana/shared")
+	if fstype != "nfs" || opt != "vers=4.1" || !reflect.DeepEqual(mntPts, []string{"/hana/shared"}) {
+		t.Errorf("got '%s', '%s', '%+v'", fstype, opt, mntPts)
+	}
+	fstype, opt, _ = ParseFSOptionKey("THP")
+	if fstype != "" || opt != "" {
+		t.Errorf("got '%s', '%s'", fstype, opt)
+	}
+	for _, key := range []string{"xfsopt_nobarrier", "ext4opt_noatime", "btrfsopt_ssd", "nfsopt_hard@/hana/shared", "nfs4opt_vers=4.1"} {
+		if !IsFSOption.MatchString(key) {
+			t.Errorf("'%s' should be a file system option", key)
+		}
+	}
+	for _, key := range []string{"xfsopt_*", "vfatopt_noatime", "nfsopt_hard@"} {
+		if IsFSOption.MatchString(key) {
+			t.Errorf("'%s' should not be a file system option", key)
+		}
+	}
+}
+
+func TestFilterMounts(t *testing.T) {
+	mounts := ParseMounts(`/dev/sda /hana/data xfs rw,noatime 0 0
+server:/shared /hana/shared nfs4 rw,hard,vers=4.1 0 0
+server:/trans /usr/sap/trans nfs rw,soft 0 0
+/dev/sdb /home ext4 rw 0 0`)
+	nfs := mounts.Filter("nfs", []string{})
+	if len(nfs) != 2 {
+		t.Errorf("expected 2 nfs mounts, got '%+v'", nfs)
+	}
+	nfs = mounts.Filter("nfs", []string{"/hana/shared"})
+	if len(nfs) != 1 || nfs[0].MountPoint != "/hana/shared" {
+		t.Errorf("got '%+v'", nfs)
+	}
+	if len(mounts.Filter("nfs4", []string{})) != 1 {
+		t.Errorf("'nfs4' should only match nfs4 mounts")
+	}
+	ok, nok := nfs.GetByMountOption("nfs", "hard", "noChk")
+	if !reflect.DeepEqual(ok, []string{"/hana/shared"}) || len(nok) != 0 {
+		t.Errorf("got '%+v', '%+v'", ok, nok)
+	}
+}
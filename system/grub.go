@@ -0,0 +1,339 @@
+package system
+
+// Handle kernel boot options in the boot loader configuration
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// GrubDefaultFile is the grub2 default configuration file
+var GrubDefaultFile = "/etc/default/grub"
+
+// grubConfigFile is the generated grub2 boot configuration
+var grubConfigFile = "/boot/grub2/grub.cfg"
+
+// grubMkconfigCmd is the command to regenerate the grub2 boot configuration
+var grubMkconfigCmd = "/usr/sbin/grub2-mkconfig"
+
+// BLSEntriesDir contains the Boot Loader Specification entries used on SLE16
+var BLSEntriesDir = "/boot/efi/loader/entries"
+
+// KernelCmdlineFile is the kernel command line template used by sdbootutil
+// for new BLS entries on SLE16
+var KernelCmdlineFile = "/etc/kernel/cmdline"
+
+// GrubBackupFile contains the boot options as found in the boot loader
+// configuration before saptune changed them for the first time.
+// Needs to survive a reboot, so it is not located in /run
+var GrubBackupFile = "/var/lib/saptune/working/.grubbackup"
+
+// GrubPendingFile contains the boot options changed by saptune, which are
+// not yet active because the system was not rebooted
+var GrubPendingFile = "/var/lib/saptune/working/.grubpending"
+
+// grubCmdlineVar is the variable in /etc/default/grub holding the kernel
+// command line options
+const grubCmdlineVar = "GRUB_CMDLINE_LINUX_DEFAULT"
+
+// useBLS returns true, if the boot options are handled by Boot Loader
+// Specification entries instead of /etc/default/grub
+func useBLS() bool {
+	if !IsSLE16() {
+		return false
+	}
+	_, err := os.Stat(BLSEntriesDir)
+	return err == nil
+}
+
+// getCmdlineOption returns the value of a boot option from a command line
+// string or 'NA', if not available
+// a boot option without a value (e.g. 'showopts') returns the option itself
+func getCmdlineOption(cmdLine, option string) string {
+	opt := "NA"
+	for _, param := range strings.Fields(cmdLine) {
+		fields := strings.SplitN(param, "=", 2)
+		if fields[0] == option {
+			if len(fields) > 1 {
+				opt = fields[1]
+			} else {
+				opt = option
+			}
+		}
+	}
+	return opt
+}
+
+// setCmdlineOption changes, adds or removes a boot option in a command line
+// string. A value of 'NA' removes the option, a value equal to the option
+// name sets the option without a value (e.g. 'showopts')
+func setCmdlineOption(cmdLine, option, value string) string {
+	newOpt := option + "=" + value
+	if value == option {
+		newOpt = option
+	}
+	found := false
+	newFields := []string{}
+	for _, param := range strings.Fields(cmdLine) {
+		fields := strings.SplitN(param, "=", 2)
+		if fields[0] != option {
+			newFields = append(newFields, param)
+			continue
+		}
+		if value != "NA" && !found {
+			newFields = append(newFields, newOpt)
+		}
+		found = true
+	}
+	if !found && value != "NA" {
+		newFields = append(newFields, newOpt)
+	}
+	return strings.Join(newFields, " ")
+}
+
+// readGrubCmdline returns the kernel command line options from
+// /etc/default/grub
+func readGrubCmdline() (string, error) {
+	content, err := os.ReadFile(GrubDefaultFile)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, grubCmdlineVar+"=") {
+			return strings.Trim(strings.TrimPrefix(line, grubCmdlineVar+"="), `"'`), nil
+		}
+	}
+	return "", nil
+}
+
+// writeGrubCmdline writes the kernel command line options to
+// /etc/default/grub
+func writeGrubCmdline(cmdLine string) error {
+	content, err := os.ReadFile(GrubDefaultFile)
+	if err != nil {
+		return err
+	}
+	found := false
+	newLine := fmt.Sprintf("%s=\"%s\"", grubCmdlineVar, cmdLine)
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), grubCmdlineVar+"=") {
+			lines[i] = newLine
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, newLine)
+	}
+	return replaceFile(GrubDefaultFile, []byte(strings.Join(lines, "\n")))
+}
+
+// blsEntries returns the Boot Loader Specification entry files
+func blsEntries() []string {
+	entries := []string{}
+	_, files := ListDir(BLSEntriesDir, "")
+	for _, file := range files {
+		if strings.HasSuffix(file, ".conf") {
+			entries = append(entries, path.Join(BLSEntriesDir, file))
+		}
+	}
+	return entries
+}
+
+// readBLSCmdline returns the kernel command line options from the 'options'
+// line of the first BLS entry
+func readBLSCmdline() (string, error) {
+	entries := blsEntries()
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no boot loader entries found in '%s'", BLSEntriesDir)
+	}
+	content, err := os.ReadFile(entries[0])
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == "options" {
+			return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "options")), nil
+		}
+	}
+	return "", nil
+}
+
+// setBLSOption changes a boot option in the 'options' line of all BLS
+// entries and in /etc/kernel/cmdline, if available
+func setBLSOption(option, value string) error {
+	for _, entry := range blsEntries() {
+		content, err := os.ReadFile(entry)
+		if err != nil {
+			return err
+		}
+		lines := strings.Split(string(content), "\n")
+		for i, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) > 0 && fields[0] == "options" {
+				opts := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "options"))
+				lines[i] = "options " + setCmdlineOption(opts, option, value)
+			}
+		}
+		if err := replaceFile(entry, []byte(strings.Join(lines, "\n"))); err != nil {
+			return err
+		}
+	}
+	if content, err := os.ReadFile(KernelCmdlineFile); err == nil {
+		cmdLine := setCmdlineOption(strings.TrimSpace(string(content)), option, value)
+		return replaceFile(KernelCmdlineFile, []byte(cmdLine+"\n"))
+	}
+	return nil
+}
+
+// GetBootCmdlineOption returns the value of a boot option as configured in
+// the boot loader configuration or 'NA', if not available
+func GetBootCmdlineOption(option string) string {
+	var cmdLine string
+	var err error
+	if useBLS() {
+		cmdLine, err = readBLSCmdline()
+	} else {
+		cmdLine, err = readGrubCmdline()
+	}
+	if err != nil {
+		WarningLog("GetBootCmdlineOption: failed to read boot loader configuration: %v", err)
+		return "NA"
+	}
+	return getCmdlineOption(cmdLine, option)
+}
+
+// SetBootCmdlineOption sets a boot option in the boot loader configuration.
+// A value of 'NA' removes the option.
+// Returns true, if the configuration was changed. The boot configuration
+// needs to be regenerated by UpdateBootConfig afterwards.
+func SetBootCmdlineOption(option, value string) (bool, error) {
+	if GetBootCmdlineOption(option) == value {
+		return false, nil
+	}
+	var err error
+	if useBLS() {
+		err = setBLSOption(option, value)
+	} else {
+		var cmdLine string
+		if cmdLine, err = readGrubCmdline(); err == nil {
+			err = writeGrubCmdline(setCmdlineOption(cmdLine, option, value))
+		}
+	}
+	if err != nil {
+		return false, ErrorLog("failed to set boot option '%s' to '%s' - %v", option, value, err)
+	}
+	InfoLog("boot option '%s' set to '%s' in the boot loader configuration", option, value)
+	addPendingBootOption(option)
+	return true, nil
+}
+
+// UpdateBootConfig regenerates the grub2 boot configuration.
+// BLS entries are changed directly, so nothing to do on SLE16
+func UpdateBootConfig() error {
+	if useBLS() {
+		return nil
+	}
+	cmd := exec.Command(grubMkconfigCmd, "-o", grubConfigFile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return ErrorLog("failed to regenerate boot configuration with '%s' - %v: %s", grubMkconfigCmd, err, string(out))
+	}
+	NoticeLog("Boot loader configuration changed. A reboot is required to activate the new kernel command line.")
+	return nil
+}
+
+// GetGrubBackupValue returns the value of a boot option as found in the boot
+// loader configuration before saptune changed it for the first time.
+// If no backup exists, the current value is stored as backup value
+func GetGrubBackupValue(option string) string {
+	backup := readOptionFile(GrubBackupFile)
+	if val, ok := backup[option]; ok {
+		return val
+	}
+	val := GetBootCmdlineOption(option)
+	backup[option] = val
+	writeOptionFile(GrubBackupFile, backup)
+	return val
+}
+
+// RemoveGrubBackupValue removes the backup value of a boot option
+func RemoveGrubBackupValue(option string) {
+	backup := readOptionFile(GrubBackupFile)
+	if _, ok := backup[option]; ok {
+		delete(backup, option)
+		writeOptionFile(GrubBackupFile, backup)
+	}
+}
+
+// addPendingBootOption remembers a changed boot option until the next reboot
+func addPendingBootOption(option string) {
+	pending := readOptionFile(GrubPendingFile)
+	pending[option] = ""
+	writeOptionFile(GrubPendingFile, pending)
+}
+
+// BootOptionsPending returns the boot options changed by saptune in the
+// boot loader configuration, which are not yet active in the running kernel,
+// so a reboot is required.
+func BootOptionsPending() []string {
+	options := []string{}
+	pending := readOptionFile(GrubPendingFile)
+	if len(pending) == 0 {
+		return options
+	}
+	for option := range pending {
+		if GetBootCmdlineOption(option) != ParseCmdline(ProcCmdLine, option) {
+			options = append(options, option)
+		}
+	}
+	if len(options) == 0 {
+		// running kernel matches the boot configuration
+		_ = os.Remove(GrubPendingFile)
+	}
+	return options
+}
+
+// readOptionFile reads a file with 'option value' lines into a map
+func readOptionFile(fileName string) map[string]string {
+	options := make(map[string]string)
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return options
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			options[fields[0]] = ""
+		default:
+			options[fields[0]] = fields[1]
+		}
+	}
+	return options
+}
+
+// writeOptionFile writes a map as 'option value' lines into a file
+// an empty map removes the file
+func writeOptionFile(fileName string, options map[string]string) {
+	if len(options) == 0 {
+		_ = os.Remove(fileName)
+		return
+	}
+	content := ""
+	for option, value := range options {
+		content = content + strings.TrimSpace(option+" "+value) + "\n"
+	}
+	if err := os.MkdirAll(path.Dir(fileName), 0755); err != nil {
+		DebugLog("creating directory for '%s' failed - '%v'", fileName, err)
+	}
+	if err := os.WriteFile(fileName, []byte(content), 0600); err != nil {
+		DebugLog("writing file '%s' failed - '%v'", fileName, err)
+	}
+}
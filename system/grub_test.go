@@ -0,0 +1,181 @@
+package system
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestGetCmdlineOption(t *testing.T) {
+	cmdLine := "BOOT_IMAGE=/boot/vmlinuz root=UUID=1234 showopts intel_idle.max_cstate=1 crashkernel=256M,high"
+	if val := getCmdlineOption(cmdLine, "intel_idle.max_cstate"); val != "1" {
+		t.Errorf("expected '1', got '%s'", val)
+	}
+	if val := getCmdlineOption(cmdLine, "showopts"); val != "showopts" {
+		t.Errorf("expected 'showopts', got '%s'", val)
+	}
+	if val := getCmdlineOption(cmdLine, "root"); val != "UUID=1234" {
+		t.Errorf("expected 'UUID=1234', got '%s'", val)
+	}
+	if val := getCmdlineOption(cmdLine, "numa_balancing"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+}
+
+func TestSetCmdlineOption(t *testing.T) {
+	cmdLine := "splash=silent quiet intel_idle.max_cstate=2"
+	newLine := setCmdlineOption(cmdLine, "intel_idle.max_cstate", "1")
+	if newLine != "splash=silent quiet intel_idle.max_cstate=1" {
+		t.Errorf("got '%s'", newLine)
+	}
+	newLine = setCmdlineOption(cmdLine, "numa_balancing", "disable")
+	if newLine != "splash=silent quiet intel_idle.max_cstate=2 numa_balancing=disable" {
+		t.Errorf("got '%s'", newLine)
+	}
+	newLine = setCmdlineOption(cmdLine, "quiet", "NA")
+	if newLine != "splash=silent intel_idle.max_cstate=2" {
+		t.Errorf("got '%s'", newLine)
+	}
+	newLine = setCmdlineOption(cmdLine, "showopts", "showopts")
+	if newLine != "splash=silent quiet intel_idle.max_cstate=2 showopts" {
+		t.Errorf("got '%s'", newLine)
+	}
+	newLine = setCmdlineOption(cmdLine, "numa_balancing", "NA")
+	if newLine != cmdLine {
+		t.Errorf("got '%s'", newLine)
+	}
+}
+
+func TestBootCmdlineOption(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "grubtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldGrubFile := GrubDefaultFile
+	oldBackup := GrubBackupFile
+	oldPending := GrubPendingFile
+	oldCmdline := ProcCmdLine
+	defer func() {
+		GrubDefaultFile = oldGrubFile
+		GrubBackupFile = oldBackup
+		GrubPendingFile = oldPending
+		ProcCmdLine = oldCmdline
+	}()
+	GrubDefaultFile = path.Join(tmpDir, "grub")
+	GrubBackupFile = path.Join(tmpDir, ".grubbackup")
+	GrubPendingFile = path.Join(tmpDir, ".grubpending")
+	ProcCmdLine = path.Join(tmpDir, "cmdline")
+
+	grubCont := "GRUB_DISTRIBUTOR=\nGRUB_CMDLINE_LINUX_DEFAULT=\"splash=silent quiet\"\nGRUB_TIMEOUT=8\n"
+	if err := os.WriteFile(GrubDefaultFile, []byte(grubCont), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ProcCmdLine, []byte("splash=silent quiet\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if val := GetBootCmdlineOption("quiet"); val != "quiet" {
+		t.Errorf("expected 'quiet', got '%s'", val)
+	}
+	if val := GetGrubBackupValue("numa_balancing"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	changed, err := SetBootCmdlineOption("numa_balancing", "disable")
+	if !changed || err != nil {
+		t.Error(changed, err)
+	}
+	content, _ := os.ReadFile(GrubDefaultFile)
+	expCont := "GRUB_DISTRIBUTOR=\nGRUB_CMDLINE_LINUX_DEFAULT=\"splash=silent quiet numa_balancing=disable\"\nGRUB_TIMEOUT=8\n"
+	if string(content) != expCont {
+		t.Errorf("got '%s'", string(content))
+	}
+	// the original file is kept, no temporary file is left
+	content, _ = os.ReadFile(GrubDefaultFile + ".saptune.bak")
+	if string(content) != grubCont {
+		t.Errorf("wrong backup file content '%s'", string(content))
+	}
+	if _, err := os.Stat(GrubDefaultFile + ".saptune.tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file '%s' should be removed", GrubDefaultFile+".saptune.tmp")
+	}
+	// backup value has to stay the first value found
+	if val := GetGrubBackupValue("numa_balancing"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	pending := BootOptionsPending()
+	if len(pending) != 1 || pending[0] != "numa_balancing" {
+		t.Errorf("got '%+v'", pending)
+	}
+	// 'reboot'
+	if err := os.WriteFile(ProcCmdLine, []byte("splash=silent quiet numa_balancing=disable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pending = BootOptionsPending()
+	if len(pending) != 0 {
+		t.Errorf("got '%+v'", pending)
+	}
+	if _, err := os.Stat(GrubPendingFile); !os.IsNotExist(err) {
+		t.Errorf("pending file '%s' should be removed", GrubPendingFile)
+	}
+	RemoveGrubBackupValue("numa_balancing")
+	if _, err := os.Stat(GrubBackupFile); !os.IsNotExist(err) {
+		t.Errorf("backup file '%s' should be removed", GrubBackupFile)
+	}
+}
+
+func TestSetBLSOption(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "blstest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldBLSDir := BLSEntriesDir
+	oldCmdlineFile := KernelCmdlineFile
+	defer func() {
+		BLSEntriesDir = oldBLSDir
+		KernelCmdlineFile = oldCmdlineFile
+	}()
+	BLSEntriesDir = path.Join(tmpDir, "entries")
+	KernelCmdlineFile = path.Join(tmpDir, "cmdline")
+	if err := os.MkdirAll(BLSEntriesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	entry := path.Join(BLSEntriesDir, "system-6.4.0.conf")
+	entryCont := "title SUSE\noptions root=UUID=1234 quiet\nlinux /vmlinuz\n"
+	if err := os.WriteFile(entry, []byte(entryCont), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(KernelCmdlineFile, []byte("root=UUID=1234 quiet\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := setBLSOption("numa_balancing", "disable"); err != nil {
+		t.Error(err)
+	}
+	if val, _ := readBLSCmdline(); val != "root=UUID=1234 quiet numa_balancing=disable" {
+		t.Errorf("got '%s'", val)
+	}
+	content, _ := os.ReadFile(KernelCmdlineFile)
+	if string(content) != "root=UUID=1234 quiet numa_balancing=disable\n" {
+		t.Errorf("got '%s'", string(content))
+	}
+	// permissions and original content are kept
+	if info, err := os.Stat(entry); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("wrong permissions of '%s' - %v", entry, err)
+	}
+	content, _ = os.ReadFile(entry + ".saptune.bak")
+	if string(content) != entryCont {
+		t.Errorf("wrong backup file content '%s'", string(content))
+	}
+	// the backup is not overwritten by later changes
+	if err := setBLSOption("numa_balancing", "NA"); err != nil {
+		t.Error(err)
+	}
+	content, _ = os.ReadFile(entry + ".saptune.bak")
+	if string(content) != entryCont {
+		t.Errorf("wrong backup file content '%s'", string(content))
+	}
+	if entries := blsEntries(); len(entries) != 1 {
+		t.Errorf("expected only the boot loader entry, but got '%v'", entries)
+	}
+}
@@ -0,0 +1,106 @@
+package system
+
+// Handle the static hugepage pools, globally and per NUMA node
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sysHugepagesDir contains the global hugepage pools
+var sysHugepagesDir = "/sys/kernel/mm/hugepages"
+
+// sysNodeDir contains the NUMA nodes and their hugepage pools
+var sysNodeDir = "/sys/devices/system/node"
+
+// isNUMANode matches the NUMA node directories in /sys/devices/system/node
+var isNUMANode = regexp.MustCompile(`^node\d+$`)
+
+// IsHugepageSize matches a hugepage size like '2048kB'
+var IsHugepageSize = regexp.MustCompile(`^\d+kB$`)
+
+// DefaultHugepageSize returns the default hugepage size of the system
+// (e.g. '2048kB')
+func DefaultHugepageSize() string {
+	return fmt.Sprintf("%dkB", ParseMeminfo()[MemHugepageSize])
+}
+
+// GetNUMANodes returns the numerically sorted list of the NUMA nodes of
+// the system (e.g. 'node0', 'node1')
+func GetNUMANodes() []string {
+	nodes := []string{}
+	dirs, _ := ListDir(sysNodeDir, "")
+	for _, dir := range dirs {
+		if isNUMANode.MatchString(dir) {
+			nodes = append(nodes, dir)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(nodes[i], "node"))
+		b, _ := strconv.Atoi(strings.TrimPrefix(nodes[j], "node"))
+		return a < b
+	})
+	return nodes
+}
+
+// hugepagesFile returns the sysfs file of a hugepage pool parameter
+// an empty node means the global pool
+func hugepagesFile(node, size, param string) string {
+	dir := sysHugepagesDir
+	if node != "" {
+		dir = path.Join(sysNodeDir, node, "hugepages")
+	}
+	return path.Join(dir, "hugepages-"+size, param)
+}
+
+// GetHugepages returns the value of a hugepage pool parameter
+// (e.g. 'nr_hugepages') of the given page size for the whole system
+// (empty node) or for a NUMA node or 'NA', if not available
+func GetHugepages(node, size, param string) string {
+	val, err := os.ReadFile(hugepagesFile(node, size, param))
+	if err != nil {
+		return "NA"
+	}
+	return strings.TrimSpace(string(val))
+}
+
+// SetHugepages sets the value of a hugepage pool parameter of the given
+// page size for the whole system (empty node) or for a NUMA node.
+// The kernel allocates as many pages as possible, so the number of
+// allocated pages may be lower than requested
+func SetHugepages(node, size, param, value string) error {
+	if value == "NA" || GetHugepages(node, size, param) == value {
+		return nil
+	}
+	hpFile := hugepagesFile(node, size, param)
+	if err := os.WriteFile(hpFile, []byte(value), 0644); err != nil {
+		return ErrorLog("failed to set '%s' to '%s' - %v", hpFile, value, err)
+	}
+	if got := GetHugepages(node, size, param); got != value {
+		WarningLog("requested %s hugepages of size %s for '%s', but only %s are allocated", value, size, nodeName(node), got)
+	}
+	return nil
+}
+
+// HugepagesPerNode returns the allocated hugepages of the given page size
+// per NUMA node as 'node0:<pages> node1:<pages>...'
+func HugepagesPerNode(size string) string {
+	val := ""
+	for _, node := range GetNUMANodes() {
+		val = val + fmt.Sprintf("%s:%s ", node, GetHugepages(node, size, "nr_hugepages"))
+	}
+	return strings.TrimSpace(val)
+}
+
+// nodeName returns a printable name of a NUMA node
+func nodeName(node string) string {
+	if node == "" {
+		return "system"
+	}
+	return node
+}
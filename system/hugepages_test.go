@@ -0,0 +1,63 @@
+package system
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestHugepages(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "hptest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldHugepagesDir := sysHugepagesDir
+	oldNodeDir := sysNodeDir
+	defer func() {
+		sysHugepagesDir = oldHugepagesDir
+		sysNodeDir = oldNodeDir
+	}()
+	sysHugepagesDir = path.Join(tmpDir, "hugepages")
+	sysNodeDir = path.Join(tmpDir, "node")
+	for _, dir := range []string{sysHugepagesDir, path.Join(sysNodeDir, "node0", "hugepages"), path.Join(sysNodeDir, "node1", "hugepages"), path.Join(sysNodeDir, "node10", "hugepages")} {
+		if err := os.MkdirAll(path.Join(dir, "hugepages-2048kB"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(dir, "hugepages-2048kB", "nr_hugepages"), []byte("0\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(path.Join(sysNodeDir, "possible"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := GetNUMANodes()
+	if len(nodes) != 3 || nodes[0] != "node0" || nodes[1] != "node1" || nodes[2] != "node10" {
+		t.Errorf("got '%+v'", nodes)
+	}
+	if val := GetHugepages("", "2048kB", "nr_hugepages"); val != "0" {
+		t.Errorf("expected '0', got '%s'", val)
+	}
+	if val := GetHugepages("", "1048576kB", "nr_hugepages"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	if err := SetHugepages("node1", "2048kB", "nr_hugepages", "512"); err != nil {
+		t.Error(err)
+	}
+	if val := GetHugepages("node1", "2048kB", "nr_hugepages"); val != "512" {
+		t.Errorf("expected '512', got '%s'", val)
+	}
+	if val := HugepagesPerNode("2048kB"); val != "node0:0 node1:512 node10:0" {
+		t.Errorf("got '%s'", val)
+	}
+	if err := SetHugepages("", "1048576kB", "nr_hugepages", "4"); err == nil {
+		t.Error("expected an error for a not available page size")
+	}
+	if err := SetHugepages("", "1048576kB", "nr_hugepages", "NA"); err != nil {
+		t.Error(err)
+	}
+	if size := DefaultHugepageSize(); !IsHugepageSize.MatchString(size) {
+		t.Errorf("wrong hugepage size '%s'", size)
+	}
+}
@@ -0,0 +1,180 @@
+package system
+
+// Handle the CPU affinity of interrupts and the irqbalance configuration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// procIRQDir contains the affinity settings of the interrupts
+var procIRQDir = "/proc/irq"
+
+// sysIRQDir contains the interrupt information like the action names
+var sysIRQDir = "/sys/kernel/irq"
+
+// irqbalanceConfFile is the sysconfig file of the irqbalance service
+var irqbalanceConfFile = "/etc/sysconfig/irqbalance"
+
+// irqbalanceService is the name of the irqbalance service
+const irqbalanceService = "irqbalance.service"
+
+// IRQBalanceBannedCPUs is the irqbalance variable holding the CPUs, which
+// irqbalance should not assign interrupts to
+const IRQBalanceBannedCPUs = "IRQBALANCE_BANNED_CPULIST"
+
+// GetIRQActions returns the action names (the names of the devices or
+// drivers) of an interrupt as listed in /sys/kernel/irq/<irq>/actions
+func GetIRQActions(irq string) string {
+	actions, err := os.ReadFile(path.Join(sysIRQDir, irq, "actions"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(actions))
+}
+
+// GetIRQsByPattern returns the numerically sorted list of interrupts, whose
+// action names match all of the given patterns
+func GetIRQsByPattern(patterns ...string) []string {
+	irqs := []string{}
+	regs := []*regexp.Regexp{}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		reg, err := regexp.Compile(pattern)
+		if err != nil {
+			WarningLog("wrong syntax of interrupt pattern '%s' - %v", pattern, err)
+			return irqs
+		}
+		regs = append(regs, reg)
+	}
+	dirs, _ := ListDir(sysIRQDir, "")
+	for _, irq := range dirs {
+		if _, err := strconv.Atoi(irq); err != nil {
+			continue
+		}
+		actions := GetIRQActions(irq)
+		if actions == "" {
+			// interrupt without handler
+			continue
+		}
+		match := true
+		for _, reg := range regs {
+			if !reg.MatchString(actions) {
+				match = false
+				break
+			}
+		}
+		if match {
+			irqs = append(irqs, irq)
+		}
+	}
+	sort.Slice(irqs, func(i, j int) bool {
+		a, _ := strconv.Atoi(irqs[i])
+		b, _ := strconv.Atoi(irqs[j])
+		return a < b
+	})
+	return irqs
+}
+
+// GetIRQAffinity returns the CPU affinity list of an interrupt from
+// /proc/irq/<irq>/smp_affinity_list or 'NA', if not available
+func GetIRQAffinity(irq string) string {
+	val, err := os.ReadFile(path.Join(procIRQDir, irq, "smp_affinity_list"))
+	if err != nil {
+		return "NA"
+	}
+	return strings.TrimSpace(string(val))
+}
+
+// SetIRQAffinity sets the CPU affinity list of an interrupt
+// The affinity of kernel managed interrupts (e.g. nvme queues) can not be
+// changed, the kernel rejects the change with EIO. So only a warning is
+// logged in this case, all other errors are returned
+func SetIRQAffinity(irq, cpuList string) error {
+	affFile := path.Join(procIRQDir, irq, "smp_affinity_list")
+	if _, err := os.Stat(affFile); err != nil {
+		// interrupt no longer available
+		DebugLog("SetIRQAffinity: interrupt '%s' not available - %v", irq, err)
+		return nil
+	}
+	if GetIRQAffinity(irq) == cpuList {
+		return nil
+	}
+	err := os.WriteFile(affFile, []byte(cpuList), 0644)
+	if errors.Is(err, syscall.EIO) {
+		WarningLog("CPU affinity of interrupt '%s' (%s) can not be set to '%s', because it is a kernel managed interrupt, skipping - %v", irq, GetIRQActions(irq), cpuList, err)
+		return nil
+	}
+	if err != nil {
+		WarningLog("failed to set CPU affinity of interrupt '%s' (%s) to '%s' - %v", irq, GetIRQActions(irq), cpuList, err)
+		return err
+	}
+	return nil
+}
+
+// GetIRQBalanceBannedCPUs returns the CPUs banned from interrupt handling
+// by irqbalance or 'NA', if not set
+func GetIRQBalanceBannedCPUs() string {
+	content, err := os.ReadFile(irqbalanceConfFile)
+	if err != nil {
+		return "NA"
+	}
+	val := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, IRQBalanceBannedCPUs+"=") {
+			val = strings.Trim(strings.TrimPrefix(line, IRQBalanceBannedCPUs+"="), `"'`)
+		}
+	}
+	if val == "" {
+		return "NA"
+	}
+	return val
+}
+
+// SetIRQBalanceBannedCPUs writes the CPUs banned from interrupt handling to
+// the irqbalance configuration and restarts a running irqbalance service.
+// A value of 'NA' resets the setting
+func SetIRQBalanceBannedCPUs(cpuList string) error {
+	if GetIRQBalanceBannedCPUs() == cpuList {
+		return nil
+	}
+	if cpuList == "NA" {
+		cpuList = ""
+	}
+	content, err := os.ReadFile(irqbalanceConfFile)
+	if err != nil && !os.IsNotExist(err) {
+		return ErrorLog("failed to read irqbalance configuration '%s' - %v", irqbalanceConfFile, err)
+	}
+	found := false
+	newLine := fmt.Sprintf("%s=\"%s\"", IRQBalanceBannedCPUs, cpuList)
+	lines := []string{}
+	if len(content) != 0 {
+		lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), IRQBalanceBannedCPUs+"=") {
+			lines[i] = newLine
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, newLine)
+	}
+	if err := os.WriteFile(irqbalanceConfFile, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return ErrorLog("failed to write irqbalance configuration '%s' - %v", irqbalanceConfFile, err)
+	}
+	if running, _ := SystemctlIsRunning(irqbalanceService); running {
+		return SystemctlRestart(irqbalanceService)
+	}
+	return nil
+}
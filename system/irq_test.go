@@ -0,0 +1,127 @@
+package system
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestIRQAffinity(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "irqtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldProcIRQDir := procIRQDir
+	oldSysIRQDir := sysIRQDir
+	defer func() {
+		procIRQDir = oldProcIRQDir
+		sysIRQDir = oldSysIRQDir
+	}()
+	procIRQDir = path.Join(tmpDir, "proc")
+	sysIRQDir = path.Join(tmpDir, "sys")
+	irqs := map[string]string{"9": "acpi", "45": "nvme0q1", "120": "mlx5_comp0@pci:0000:3b:00.0", "46": "nvme0q2", "7": ""}
+	for irq, actions := range irqs {
+		for _, dir := range []string{path.Join(procIRQDir, irq), path.Join(sysIRQDir, irq)} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.WriteFile(path.Join(sysIRQDir, irq, "actions"), []byte(actions+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(procIRQDir, irq, "smp_affinity_list"), []byte("0-63\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found := GetIRQsByPattern("nvme")
+	if len(found) != 2 || found[0] != "45" || found[1] != "46" {
+		t.Errorf("got '%+v'", found)
+	}
+	found = GetIRQsByPattern("nvme", "q2$")
+	if len(found) != 1 || found[0] != "46" {
+		t.Errorf("got '%+v'", found)
+	}
+	found = GetIRQsByPattern("")
+	if len(found) != 4 || found[0] != "9" || found[3] != "120" {
+		t.Errorf("got '%+v'", found)
+	}
+	found = GetIRQsByPattern("nvme[")
+	if len(found) != 0 {
+		t.Errorf("got '%+v'", found)
+	}
+	if val := GetIRQAffinity("45"); val != "0-63" {
+		t.Errorf("expected '0-63', got '%s'", val)
+	}
+	if val := GetIRQAffinity("1000"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	if err := SetIRQAffinity("45", "4-7"); err != nil {
+		t.Error(err)
+	}
+	if val := GetIRQAffinity("45"); val != "4-7" {
+		t.Errorf("expected '4-7', got '%s'", val)
+	}
+	if err := SetIRQAffinity("1000", "4-7"); err != nil {
+		t.Error(err)
+	}
+	// errors other than EIO of a kernel managed interrupt are returned
+	affFile := path.Join(procIRQDir, "46", "smp_affinity_list")
+	if err := os.Remove(affFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(affFile, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetIRQAffinity("46", "4-7"); err == nil {
+		t.Error("should return an error and not 'nil'")
+	}
+}
+
+func TestIRQBalanceBannedCPUs(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "irqtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldConfFile := irqbalanceConfFile
+	oldSystemctlCmd := systemctlCmd
+	defer func() {
+		irqbalanceConfFile = oldConfFile
+		systemctlCmd = oldSystemctlCmd
+	}()
+	irqbalanceConfFile = path.Join(tmpDir, "irqbalance")
+	systemctlCmd = "/usr/bin/false"
+
+	if val := GetIRQBalanceBannedCPUs(); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	if err := SetIRQBalanceBannedCPUs("0-3"); err != nil {
+		t.Error(err)
+	}
+	if val := GetIRQBalanceBannedCPUs(); val != "0-3" {
+		t.Errorf("expected '0-3', got '%s'", val)
+	}
+	conf := "## Path:	System/irqbalance\nIRQBALANCE_ONESHOT=\"\"\nIRQBALANCE_BANNED_CPULIST=\"\"\n"
+	if err := os.WriteFile(irqbalanceConfFile, []byte(conf), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if val := GetIRQBalanceBannedCPUs(); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	if err := SetIRQBalanceBannedCPUs("0-3,8"); err != nil {
+		t.Error(err)
+	}
+	content, _ := os.ReadFile(irqbalanceConfFile)
+	expCont := "## Path:	System/irqbalance\nIRQBALANCE_ONESHOT=\"\"\nIRQBALANCE_BANNED_CPULIST=\"0-3,8\"\n"
+	if string(content) != expCont {
+		t.Errorf("got '%s'", string(content))
+	}
+	if err := SetIRQBalanceBannedCPUs("NA"); err != nil {
+		t.Error(err)
+	}
+	if val := GetIRQBalanceBannedCPUs(); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+}
@@ -181,6 +181,7 @@ type JStatus struct {
 	AppliedNotes    []string       `json:"Notes applied"`
 	OrphanedOver    []string       `json:"orphaned Overrides"`
 	Staging         JStatusStaging `json:"staging"`
+	RebootRequired  []string       `json:"reboot required,omitempty"`
 	Msg             string         `json:"remember message"`
 }
 
@@ -215,6 +216,39 @@ type JSolList struct {
 	Msg      string          `json:"remember message"`
 }
 
+// JHistoryEntry is one parameter change of 'saptune history'
+type JHistoryEntry struct {
+	Timestamp string `json:"timestamp"`
+	CmdLine   string `json:"command line"`
+	Action    string `json:"action"`
+	NoteID    string `json:"Note ID"`
+	Section   string `json:"section"`
+	Param     string `json:"parameter"`
+	OldValue  string `json:"old value"`
+	NewValue  string `json:"new value"`
+}
+
+// JHistory is the whole 'saptune history'
+type JHistory struct {
+	Changes []JHistoryEntry `json:"parameter changes"`
+}
+
+// JDriftEntry is one drifted parameter of 'saptune drift'
+type JDriftEntry struct {
+	Section  string `json:"section"`
+	Param    string `json:"parameter"`
+	NoteID   string `json:"Note ID"`
+	Expected string `json:"value set by saptune"`
+	Actual   string `json:"actual value"`
+	Source   string `json:"likely source"`
+}
+
+// JDrift is the whole 'saptune drift'
+type JDrift struct {
+	DriftDetected bool          `json:"drift detected"`
+	Drifts        []JDriftEntry `json:"drifted parameters"`
+}
+
 // jInit creates an initial json entry
 // used in system/InitOut
 func jInit() {
@@ -319,8 +353,8 @@ func Jcollect(data interface{}) {
 			appSol.AppliedSol = make([]JAppliedSol, 0)
 		}
 		jentry.CmdResult = appSol
-	case JSolList, JNoteList, JStatus, JPNotes:
-		//"solution list", "note list", "status", "daemon status", "service status", "note verify", "solution verify", "note simulate", "solution simulate":
+	case JSolList, JNoteList, JStatus, JPNotes, JHistory, JDrift:
+		//"solution list", "note list", "status", "daemon status", "service status", "note verify", "solution verify", "note simulate", "solution simulate", "history", "drift":
 		jentry.CmdResult = res
 	case []byte:
 		// "saptune check" - "saptune_check --json" - []uint8
@@ -0,0 +1,324 @@
+package system
+
+// Handle kernel modules and their parameters
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// procModules lists the currently loaded kernel modules
+var procModules = "/proc/modules"
+
+// sysModuleDir contains the parameters of the kernel modules
+var sysModuleDir = "/sys/module"
+
+// modprobeDropDir is the directory for the saptune modprobe drop-in files
+var modprobeDropDir = "/etc/modprobe.d"
+
+// modulesLoadDropDir is the directory for the saptune modules-load drop-in
+// files, which load the modules during boot
+var modulesLoadDropDir = "/etc/modules-load.d"
+
+// modprobeConfDirs are the directories searched by modprobe for its
+// configuration files
+var modprobeConfDirs = []string{"/etc/modprobe.d", "/run/modprobe.d", "/usr/local/lib/modprobe.d", "/usr/lib/modprobe.d", "/lib/modprobe.d"}
+
+// modprobeCmd is the command to load and unload kernel modules
+var modprobeCmd = "/usr/sbin/modprobe"
+
+// modprobeDropIn contains the saptune settings of a kernel module
+type modprobeDropIn struct {
+	Blacklist bool
+	Options   map[string]string
+}
+
+// normModuleName returns the module name as used by the kernel in
+// /proc/modules and /sys/module ('-' replaced by '_')
+func normModuleName(module string) string {
+	return strings.ReplaceAll(module, "-", "_")
+}
+
+// modprobeDropInFile returns the name of the saptune modprobe drop-in file
+// of a kernel module
+func modprobeDropInFile(module string) string {
+	return path.Join(modprobeDropDir, fmt.Sprintf("saptune-%s.conf", normModuleName(module)))
+}
+
+// modulesLoadDropInFile returns the name of the saptune modules-load drop-in
+// file of a kernel module
+func modulesLoadDropInFile(module string) string {
+	return path.Join(modulesLoadDropDir, fmt.Sprintf("saptune-%s.conf", normModuleName(module)))
+}
+
+// dropInHeader returns the saptune specific comment of a drop-in file
+func dropInHeader(fileName string) string {
+	return fmt.Sprintf("### %s\n### file autogenerated by saptune!\n###\n### Please do NOT change or delete!\n###\n\n", fileName)
+}
+
+// IsModuleLoaded returns true, if the kernel module is listed in /proc/modules
+func IsModuleLoaded(module string) bool {
+	content, err := os.ReadFile(procModules)
+	if err != nil {
+		WarningLog("IsModuleLoaded: failed to read '%s' - %v", procModules, err)
+		return false
+	}
+	name := normModuleName(module)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsModuleBlacklisted returns true, if the kernel module is blacklisted in
+// one of the modprobe configuration files
+func IsModuleBlacklisted(module string) bool {
+	name := normModuleName(module)
+	for _, dir := range modprobeConfDirs {
+		_, files := ListDir(dir, "")
+		for _, file := range files {
+			if !strings.HasSuffix(file, ".conf") {
+				continue
+			}
+			content, err := os.ReadFile(path.Join(dir, file))
+			if err != nil {
+				continue
+			}
+			for _, line := range strings.Split(string(content), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) > 1 && fields[0] == "blacklist" && normModuleName(fields[1]) == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// GetModuleState returns the state of a kernel module
+// 'load' - the module is loaded
+// 'blacklist' - the module is not loaded and blacklisted
+// 'unload' - the module is not loaded
+func GetModuleState(module string) string {
+	if IsModuleLoaded(module) {
+		return "load"
+	}
+	if IsModuleBlacklisted(module) {
+		return "blacklist"
+	}
+	return "unload"
+}
+
+// CmpModuleStates compares the expected state of a kernel module with the
+// current state. A blacklisted module is not loaded, so 'blacklist'
+// satisfies the expected state 'unload'
+func CmpModuleStates(actState, expState string) bool {
+	if actState == expState {
+		return true
+	}
+	return actState == "blacklist" && expState == "unload"
+}
+
+// GetModuleParam returns the value of a kernel module parameter from
+// /sys/module/<module>/parameters or 'NA', if not available
+func GetModuleParam(module, param string) string {
+	val, err := os.ReadFile(path.Join(sysModuleDir, normModuleName(module), "parameters", param))
+	if err != nil {
+		return "NA"
+	}
+	return strings.TrimSpace(string(val))
+}
+
+// readModprobeDropIn reads the saptune modprobe drop-in file of a module
+func readModprobeDropIn(module string) modprobeDropIn {
+	dropIn := modprobeDropIn{Options: make(map[string]string)}
+	content, err := os.ReadFile(modprobeDropInFile(module))
+	if err != nil {
+		return dropIn
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "blacklist":
+			dropIn.Blacklist = true
+		case "options":
+			for _, opt := range fields[2:] {
+				kv := strings.SplitN(opt, "=", 2)
+				if len(kv) == 2 {
+					dropIn.Options[kv[0]] = kv[1]
+				} else {
+					dropIn.Options[kv[0]] = ""
+				}
+			}
+		}
+	}
+	return dropIn
+}
+
+// writeModprobeDropIn writes the saptune modprobe drop-in file of a module
+// an empty drop-in removes the file
+func writeModprobeDropIn(module string, dropIn modprobeDropIn) error {
+	fileName := modprobeDropInFile(module)
+	if !dropIn.Blacklist && len(dropIn.Options) == 0 {
+		if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			return ErrorLog("failed to remove modprobe drop-in file '%s' - %v", fileName, err)
+		}
+		return nil
+	}
+	var ret bytes.Buffer
+	ret.WriteString(dropInHeader(fileName))
+	name := normModuleName(module)
+	if dropIn.Blacklist {
+		ret.WriteString(fmt.Sprintf("blacklist %s\n", name))
+	}
+	if len(dropIn.Options) != 0 {
+		opts := []string{}
+		for opt := range dropIn.Options {
+			opts = append(opts, opt)
+		}
+		sort.Strings(opts)
+		ret.WriteString(fmt.Sprintf("options %s", name))
+		for _, opt := range opts {
+			ret.WriteString(fmt.Sprintf(" %s=%s", opt, dropIn.Options[opt]))
+		}
+		ret.WriteRune('\n')
+	}
+	if err := os.MkdirAll(modprobeDropDir, 0755); err != nil {
+		return ErrorLog("failed to create needed directories for the modprobe drop-in file: %v", err)
+	}
+	return os.WriteFile(fileName, ret.Bytes(), 0644)
+}
+
+// setModuleBootLoad adds or removes the saptune modules-load drop-in file,
+// which loads the module during boot
+func setModuleBootLoad(module string, load bool) error {
+	fileName := modulesLoadDropInFile(module)
+	if !load {
+		if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			return ErrorLog("failed to remove modules-load drop-in file '%s' - %v", fileName, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(modulesLoadDropDir, 0755); err != nil {
+		return ErrorLog("failed to create needed directories for the modules-load drop-in file: %v", err)
+	}
+	content := dropInHeader(fileName) + normModuleName(module) + "\n"
+	return os.WriteFile(fileName, []byte(content), 0644)
+}
+
+// runModprobe loads (unload = false) or unloads (unload = true) a module
+func runModprobe(module string, unload bool) error {
+	cmdArgs := []string{module}
+	if unload {
+		if !IsModuleLoaded(module) {
+			return nil
+		}
+		cmdArgs = []string{"-r", module}
+	} else if IsModuleLoaded(module) {
+		return nil
+	}
+	if out, err := exec.Command(modprobeCmd, cmdArgs...).CombinedOutput(); err != nil {
+		return ErrorLog("failed to run '%s %s' - %v: %s", modprobeCmd, strings.Join(cmdArgs, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// setModuleRuntimeState loads or unloads a module to reach the given state
+func setModuleRuntimeState(module, state string) error {
+	switch state {
+	case "load":
+		return runModprobe(module, false)
+	case "unload", "blacklist":
+		return runModprobe(module, true)
+	}
+	return nil
+}
+
+// SetModuleState sets the state of a kernel module ('load', 'unload' or
+// 'blacklist') and writes the saptune drop-in files, so that the state
+// survives a reboot
+func SetModuleState(module, state string) error {
+	if state != "load" && state != "unload" && state != "blacklist" {
+		return ErrorLog("unsupported state '%s' for kernel module '%s'", state, module)
+	}
+	dropIn := readModprobeDropIn(module)
+	dropIn.Blacklist = state == "blacklist"
+	if err := writeModprobeDropIn(module, dropIn); err != nil {
+		return err
+	}
+	if err := setModuleBootLoad(module, state == "load"); err != nil {
+		return err
+	}
+	return setModuleRuntimeState(module, state)
+}
+
+// ResetModuleState removes the saptune settings for the state of a kernel
+// module from the drop-in files and brings the module back to the given
+// state
+func ResetModuleState(module, state string) error {
+	dropIn := readModprobeDropIn(module)
+	dropIn.Blacklist = false
+	if err := writeModprobeDropIn(module, dropIn); err != nil {
+		return err
+	}
+	if err := setModuleBootLoad(module, false); err != nil {
+		return err
+	}
+	return setModuleRuntimeState(module, state)
+}
+
+// setModuleParamRuntime writes the value of a module parameter to
+// /sys/module, if the module is loaded
+func setModuleParamRuntime(module, param, value string) error {
+	if value == "NA" || !IsModuleLoaded(module) {
+		return nil
+	}
+	paramFile := path.Join(sysModuleDir, normModuleName(module), "parameters", param)
+	if GetModuleParam(module, param) == value {
+		return nil
+	}
+	info, err := os.Stat(paramFile)
+	if err != nil || info.Mode().Perm()&0200 == 0 {
+		// parameter is read-only at runtime
+		NoticeLog("parameter '%s' of kernel module '%s' can not be changed at runtime. The new value will be used after the module is reloaded or the system is rebooted.", param, module)
+		return nil
+	}
+	if err := os.WriteFile(paramFile, []byte(value), 0644); err != nil {
+		return ErrorLog("failed to set parameter '%s' of kernel module '%s' to '%s' - %v", param, module, value, err)
+	}
+	return nil
+}
+
+// SetModuleParam sets a kernel module parameter in the saptune modprobe
+// drop-in file and, if possible, in the running kernel
+func SetModuleParam(module, param, value string) error {
+	dropIn := readModprobeDropIn(module)
+	dropIn.Options[param] = value
+	if err := writeModprobeDropIn(module, dropIn); err != nil {
+		return err
+	}
+	return setModuleParamRuntime(module, param, value)
+}
+
+// ResetModuleParam removes a kernel module parameter from the saptune
+// modprobe drop-in file and sets the given value in the running kernel.
+// A value of 'NA' leaves the running kernel untouched
+func ResetModuleParam(module, param, value string) error {
+	dropIn := readModprobeDropIn(module)
+	delete(dropIn.Options, param)
+	if err := writeModprobeDropIn(module, dropIn); err != nil {
+		return err
+	}
+	return setModuleParamRuntime(module, param, value)
+}
@@ -0,0 +1,130 @@
+package system
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestKernelModules(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "kmodtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldProcModules := procModules
+	oldSysModuleDir := sysModuleDir
+	oldModprobeDropDir := modprobeDropDir
+	oldModulesLoadDropDir := modulesLoadDropDir
+	oldModprobeConfDirs := modprobeConfDirs
+	oldModprobeCmd := modprobeCmd
+	defer func() {
+		procModules = oldProcModules
+		sysModuleDir = oldSysModuleDir
+		modprobeDropDir = oldModprobeDropDir
+		modulesLoadDropDir = oldModulesLoadDropDir
+		modprobeConfDirs = oldModprobeConfDirs
+		modprobeCmd = oldModprobeCmd
+	}()
+	procModules = path.Join(tmpDir, "modules")
+	sysModuleDir = path.Join(tmpDir, "module")
+	modprobeDropDir = path.Join(tmpDir, "modprobe.d")
+	modulesLoadDropDir = path.Join(tmpDir, "modules-load.d")
+	modprobeConfDirs = []string{modprobeDropDir}
+	modprobeCmd = "/usr/bin/true"
+
+	procCont := "nvme_core 139264 1 nvme, Live 0x0000000000000000\nfloppy 86016 0 - Live 0x0000000000000000\n"
+	if err := os.WriteFile(procModules, []byte(procCont), 0644); err != nil {
+		t.Fatal(err)
+	}
+	paramDir := path.Join(sysModuleDir, "nvme_core", "parameters")
+	if err := os.MkdirAll(paramDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(paramDir, "io_timeout"), []byte("30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsModuleLoaded("nvme-core") {
+		t.Error("module 'nvme-core' should be loaded")
+	}
+	if state := GetModuleState("floppy"); state != "load" {
+		t.Errorf("expected 'load', got '%s'", state)
+	}
+	if state := GetModuleState("cdrom"); state != "unload" {
+		t.Errorf("expected 'unload', got '%s'", state)
+	}
+	if val := GetModuleParam("nvme_core", "io_timeout"); val != "30" {
+		t.Errorf("expected '30', got '%s'", val)
+	}
+	if val := GetModuleParam("nvme_core", "unknown"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+
+	// blacklist
+	if err := SetModuleState("cdrom", "blacklist"); err != nil {
+		t.Error(err)
+	}
+	if state := GetModuleState("cdrom"); state != "blacklist" {
+		t.Errorf("expected 'blacklist', got '%s'", state)
+	}
+	if err := SetModuleState("cdrom", "unknown"); err == nil {
+		t.Error("expected an error for an unsupported state")
+	}
+	// load
+	if err := SetModuleState("floppy", "load"); err != nil {
+		t.Error(err)
+	}
+	content, err := os.ReadFile(modulesLoadDropInFile("floppy"))
+	if err != nil || string(content) != dropInHeader(modulesLoadDropInFile("floppy"))+"floppy\n" {
+		t.Errorf("got '%s', %v", string(content), err)
+	}
+
+	// module parameter
+	if err := SetModuleParam("nvme_core", "io_timeout", "4294967295"); err != nil {
+		t.Error(err)
+	}
+	if err := SetModuleParam("nvme_core", "multipath", "N"); err != nil {
+		t.Error(err)
+	}
+	if val := GetModuleParam("nvme_core", "io_timeout"); val != "4294967295" {
+		t.Errorf("expected '4294967295', got '%s'", val)
+	}
+	content, _ = os.ReadFile(modprobeDropInFile("nvme_core"))
+	expCont := dropInHeader(modprobeDropInFile("nvme_core")) + "options nvme_core io_timeout=4294967295 multipath=N\n"
+	if string(content) != expCont {
+		t.Errorf("got '%s'", string(content))
+	}
+
+	// revert
+	if err := ResetModuleParam("nvme_core", "io_timeout", "30"); err != nil {
+		t.Error(err)
+	}
+	if val := GetModuleParam("nvme_core", "io_timeout"); val != "30" {
+		t.Errorf("expected '30', got '%s'", val)
+	}
+	if err := ResetModuleParam("nvme_core", "multipath", "NA"); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(modprobeDropInFile("nvme_core")); !os.IsNotExist(err) {
+		t.Errorf("drop-in file '%s' should be removed", modprobeDropInFile("nvme_core"))
+	}
+	if err := ResetModuleState("cdrom", "unload"); err != nil {
+		t.Error(err)
+	}
+	if state := GetModuleState("cdrom"); state != "unload" {
+		t.Errorf("expected 'unload', got '%s'", state)
+	}
+	if err := ResetModuleState("floppy", "load"); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(modulesLoadDropInFile("floppy")); !os.IsNotExist(err) {
+		t.Errorf("drop-in file '%s' should be removed", modulesLoadDropInFile("floppy"))
+	}
+
+	// modprobe failure
+	modprobeCmd = "/usr/bin/false"
+	if err := SetModuleState("cdrom", "load"); err == nil {
+		t.Error("expected an error from modprobe")
+	}
+}
@@ -13,6 +13,7 @@ import (
 const (
 	MemMainTotalKey = "MemTotal"
 	MemSwapTotalKey = "SwapTotal"
+	MemHugepageSize = "Hugepagesize"
 )
 
 // ParseMeminfo parse /proc/meminfo into key(string) - value(int) pairs.
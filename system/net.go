@@ -0,0 +1,231 @@
+package system
+
+// Handle network interface settings like MTU, ring sizes, channels (queue
+// counts) and offloads. Most of these settings are only available by the
+// ethtool ioctls, so the ethtool command is used
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ethtoolCmd is the command to query and change the network interface
+// settings
+var ethtoolCmd = "/usr/sbin/ethtool"
+
+// sysNetDir contains the network interfaces
+var sysNetDir = "/sys/class/net"
+
+// IsNetParam matches the supported parameters of the section [net]
+var IsNetParam = regexp.MustCompile(`^(MTU|ring_(rx|rx-mini|rx-jumbo|tx)|channels_(rx|tx|other|combined)|offload_[\w-]+)$`)
+
+// ethtoolFeatures maps the short feature names used by 'ethtool -K' to the
+// feature names listed by 'ethtool -k'
+var ethtoolFeatures = map[string]string{
+	"rx":     "rx-checksumming",
+	"tx":     "tx-checksumming",
+	"sg":     "scatter-gather",
+	"tso":    "tcp-segmentation-offload",
+	"ufo":    "udp-fragmentation-offload",
+	"gso":    "generic-segmentation-offload",
+	"gro":    "generic-receive-offload",
+	"lro":    "large-receive-offload",
+	"rxvlan": "rx-vlan-offload",
+	"txvlan": "tx-vlan-offload",
+	"ntuple": "ntuple-filters",
+	"rxhash": "receive-hashing",
+}
+
+// GetNetInterfaces returns the network interfaces of the system without
+// the loopback device
+func GetNetInterfaces() []string {
+	ifaces := []string{}
+	dirs, files := ListDir(sysNetDir, "")
+	// the entries in /sys/class/net are symbolic links
+	for _, iface := range append(dirs, files...) {
+		if iface == "lo" || iface == "bonding_masters" {
+			continue
+		}
+		ifaces = append(ifaces, iface)
+	}
+	return ifaces
+}
+
+// GetNetDriver returns the driver of a network interface or an empty
+// string for virtual interfaces
+func GetNetDriver(iface string) string {
+	driver, err := filepath.EvalSymlinks(path.Join(sysNetDir, iface, "device", "driver"))
+	if err != nil {
+		return ""
+	}
+	return path.Base(driver)
+}
+
+// GetNetInterfacesByTag returns the network interfaces, whose name (info
+// 'pat') or driver (info 'driver') match the pattern
+func GetNetInterfacesByTag(info, pattern string) []string {
+	ifaces := []string{}
+	reg, err := regexp.Compile(pattern)
+	if err != nil {
+		WarningLog("wrong syntax of network interface pattern '%s' - %v", pattern, err)
+		return ifaces
+	}
+	for _, iface := range GetNetInterfaces() {
+		val := iface
+		if info == "driver" {
+			val = GetNetDriver(iface)
+		}
+		if val != "" && reg.MatchString(val) {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	return ifaces
+}
+
+// splitNetParam returns the ethtool setting type ('MTU', 'ring',
+// 'channels' or 'offload') and the ethtool parameter name
+func splitNetParam(param string) (string, string) {
+	fields := strings.SplitN(param, "_", 2)
+	if len(fields) != 2 {
+		return fields[0], ""
+	}
+	return fields[0], fields[1]
+}
+
+// runEthtool calls the ethtool command and returns the output
+func runEthtool(args ...string) (string, error) {
+	out, err := exec.Command(ethtoolCmd, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("'%s %s' failed - %v: %s", ethtoolCmd, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// parseEthtoolSettings parses the 'Current hardware settings' block of
+// the output of 'ethtool -g' and 'ethtool -l' into a map. The keys are the
+// lower case parameter names as used by 'ethtool -G' and 'ethtool -L'
+// (e.g. 'rx-jumbo' or 'combined')
+func parseEthtoolSettings(out string) map[string]string {
+	settings := make(map[string]string)
+	current := false
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "Current hardware settings") {
+			current = true
+			continue
+		}
+		if !current {
+			continue
+		}
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(fields[0])), " ", "-")
+		val := strings.TrimSpace(fields[1])
+		if val == "n/a" || val == "" {
+			val = "NA"
+		}
+		settings[key] = val
+	}
+	return settings
+}
+
+// parseEthtoolFeatures parses the output of 'ethtool -k' into a map of
+// feature names and their state ('on' or 'off')
+func parseEthtoolFeatures(out string) map[string]string {
+	features := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 || strings.HasPrefix(line, "Features for") {
+			continue
+		}
+		state := strings.Fields(fields[1])
+		if len(state) == 0 {
+			continue
+		}
+		features[strings.TrimSpace(fields[0])] = state[0]
+	}
+	return features
+}
+
+// GetNetParam returns the current value of a parameter of a network
+// interface or 'NA', if not available (e.g. if the ethtool command is
+// missing)
+func GetNetParam(iface, param string) string {
+	ptype, name := splitNetParam(param)
+	var settings map[string]string
+	if ptype != "MTU" && !CmdIsAvailable(ethtoolCmd) {
+		InfoLog("command '%s' not found, so '%s' of network interface '%s' is not available", ethtoolCmd, param, iface)
+		return "NA"
+	}
+	switch ptype {
+	case "MTU":
+		mtu, err := os.ReadFile(path.Join(sysNetDir, iface, "mtu"))
+		if err != nil {
+			return "NA"
+		}
+		return strings.TrimSpace(string(mtu))
+	case "ring", "channels":
+		opt := "-g"
+		if ptype == "channels" {
+			opt = "-l"
+		}
+		out, err := runEthtool(opt, iface)
+		if err != nil {
+			DebugLog("GetNetParam: %v", err)
+			return "NA"
+		}
+		settings = parseEthtoolSettings(out)
+	case "offload":
+		out, err := runEthtool("-k", iface)
+		if err != nil {
+			DebugLog("GetNetParam: %v", err)
+			return "NA"
+		}
+		settings = parseEthtoolFeatures(out)
+		if feature, ok := ethtoolFeatures[name]; ok {
+			name = feature
+		}
+	}
+	if val, ok := settings[name]; ok {
+		return val
+	}
+	return "NA"
+}
+
+// SetNetParam sets a parameter of a network interface
+func SetNetParam(iface, param, value string) error {
+	if value == "NA" || GetNetParam(iface, param) == value {
+		return nil
+	}
+	ptype, name := splitNetParam(param)
+	if ptype != "MTU" && !CmdIsAvailable(ethtoolCmd) {
+		WarningLog("command '%s' not found, skipping '%s' of network interface '%s'", ethtoolCmd, param, iface)
+		return nil
+	}
+	var err error
+	switch ptype {
+	case "MTU":
+		if _, err = strconv.Atoi(value); err == nil {
+			err = os.WriteFile(path.Join(sysNetDir, iface, "mtu"), []byte(value), 0644)
+		}
+	case "ring":
+		_, err = runEthtool("-G", iface, name, value)
+	case "channels":
+		_, err = runEthtool("-L", iface, name, value)
+	case "offload":
+		_, err = runEthtool("-K", iface, name, value)
+	default:
+		err = fmt.Errorf("unsupported parameter")
+	}
+	if err != nil {
+		return ErrorLog("failed to set '%s' of network interface '%s' to '%s' - %v", param, iface, value, err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package system
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+var ethtoolRingOut = `Ring parameters for eth0:
+Pre-set maximums:
+RX:		4096
+RX Mini:	n/a
+RX Jumbo:	0
+TX:		4096
+Current hardware settings:
+RX:		256
+RX Mini:	n/a
+RX Jumbo:	0
+TX:		512
+`
+
+var ethtoolFeaturesOut = `Features for eth0:
+rx-checksumming: on
+tx-checksumming: on
+	tx-checksum-ipv4: off [fixed]
+tcp-segmentation-offload: on
+generic-receive-offload: off
+large-receive-offload: off [fixed]
+`
+
+func TestParseEthtool(t *testing.T) {
+	settings := parseEthtoolSettings(ethtoolRingOut)
+	if settings["rx"] != "256" || settings["tx"] != "512" || settings["rx-mini"] != "NA" || settings["rx-jumbo"] != "0" {
+		t.Errorf("got '%+v'", settings)
+	}
+	features := parseEthtoolFeatures(ethtoolFeaturesOut)
+	if features["generic-receive-offload"] != "off" || features["tx-checksum-ipv4"] != "off" || features["tcp-segmentation-offload"] != "on" || len(features) != 6 {
+		t.Errorf("got '%+v'", features)
+	}
+}
+
+func TestNetParams(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "nettest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	oldNetDir := sysNetDir
+	oldEthtoolCmd := ethtoolCmd
+	defer func() {
+		sysNetDir = oldNetDir
+		ethtoolCmd = oldEthtoolCmd
+	}()
+	sysNetDir = path.Join(tmpDir, "net")
+	for _, iface := range []string{"lo", "eth0", "eth1", "br0"} {
+		if err := os.MkdirAll(path.Join(sysNetDir, iface), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path.Join(sysNetDir, iface, "mtu"), []byte("1500\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for iface, drv := range map[string]string{"eth0": "ena", "eth1": "mlx5_core"} {
+		drvDir := path.Join(tmpDir, "drivers", drv)
+		if err := os.MkdirAll(drvDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(path.Join(sysNetDir, iface, "device"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(drvDir, path.Join(sysNetDir, iface, "device", "driver")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// fake ethtool printing the ring parameters and logging the
+	// arguments of the changes
+	ethtoolCmd = path.Join(tmpDir, "ethtool")
+	script := "#!/bin/sh\ncase \"$1\" in\n-g) cat " + path.Join(tmpDir, "ring") + ";;\n-G) echo \"$@\" > " + path.Join(tmpDir, "args") + ";;\n*) exit 1;;\nesac\n"
+	if err := os.WriteFile(ethtoolCmd, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(tmpDir, "ring"), []byte(ethtoolRingOut), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ifaces := GetNetInterfaces()
+	if len(ifaces) != 3 || ifaces[0] != "br0" || ifaces[1] != "eth0" || ifaces[2] != "eth1" {
+		t.Errorf("got '%+v'", ifaces)
+	}
+	if drv := GetNetDriver("eth1"); drv != "mlx5_core" {
+		t.Errorf("expected 'mlx5_core', got '%s'", drv)
+	}
+	if drv := GetNetDriver("br0"); drv != "" {
+		t.Errorf("expected '', got '%s'", drv)
+	}
+	ifaces = GetNetInterfacesByTag("driver", "^ena$")
+	if len(ifaces) != 1 || ifaces[0] != "eth0" {
+		t.Errorf("got '%+v'", ifaces)
+	}
+	ifaces = GetNetInterfacesByTag("pat", "eth")
+	if len(ifaces) != 2 {
+		t.Errorf("got '%+v'", ifaces)
+	}
+
+	if val := GetNetParam("eth0", "MTU"); val != "1500" {
+		t.Errorf("expected '1500', got '%s'", val)
+	}
+	if err := SetNetParam("eth0", "MTU", "9000"); err != nil {
+		t.Error(err)
+	}
+	if val := GetNetParam("eth0", "MTU"); val != "9000" {
+		t.Errorf("expected '9000', got '%s'", val)
+	}
+	if val := GetNetParam("eth0", "ring_tx"); val != "512" {
+		t.Errorf("expected '512', got '%s'", val)
+	}
+	if val := GetNetParam("eth0", "channels_combined"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	if err := SetNetParam("eth0", "ring_rx", "4096"); err != nil {
+		t.Error(err)
+	}
+	args, _ := os.ReadFile(path.Join(tmpDir, "args"))
+	if string(args) != "-G eth0 rx 4096\n" {
+		t.Errorf("got '%s'", string(args))
+	}
+	if err := SetNetParam("eth0", "offload_gro", "off"); err == nil {
+		t.Error("expected an error from ethtool")
+	}
+	// missing ethtool command
+	ethtoolCmd = path.Join(tmpDir, "ethtool_missing")
+	if val := GetNetParam("eth0", "ring_tx"); val != "NA" {
+		t.Errorf("expected 'NA', got '%s'", val)
+	}
+	if err := SetNetParam("eth0", "ring_rx", "2048"); err != nil {
+		t.Error(err)
+	}
+	if val := GetNetParam("eth0", "MTU"); val != "9000" {
+		t.Errorf("expected '9000', got '%s'", val)
+	}
+}
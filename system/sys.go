@@ -22,7 +22,7 @@ func getKeyStringFromPath(basePath string, parameter string, logFrom string) (st
 	// filesize (which is the case for /proc/sys files, returns always 0)
 	// This might not enough for sysctl parameter like
 	// 'net.ipv4.ip_local_reserved_ports'
-	srcFile := path.Join(basePath, strings.Replace(parameter, ".", "/", -1))
+	srcFile := path.Join(basePath, keyToPath(parameter))
 	file, err := os.Open(srcFile)
 	if err != nil {
 		WarningLog("failed to read %v string key '%s': %v", logFrom, parameter, err)
@@ -44,7 +44,7 @@ func getKeyStringFromPath(basePath string, parameter string, logFrom string) (st
 // GetSysChoice read a /sys/ key that comes with current value and alternative
 // choices, return the current choice or empty string.
 func GetSysChoice(parameter string) (string, error) {
-	val, err := os.ReadFile(path.Join("/sys", strings.Replace(parameter, ".", "/", -1)))
+	val, err := os.ReadFile(path.Join("/sys", keyToPath(parameter)))
 	if err != nil {
 		WarningLog("failed to read sys key of choices '%s': %v", parameter, err)
 		return "PNA", err
@@ -75,7 +75,7 @@ func SetSysString(parameter, value string) error {
 		WarningLog("value is '%s', so sys key '%s' is/was not supported by os, skipping.", value, parameter)
 		return nil
 	}
-	err := os.WriteFile(path.Join("/sys", strings.Replace(parameter, ".", "/", -1)), []byte(value), 0644)
+	err := os.WriteFile(path.Join("/sys", keyToPath(parameter)), []byte(value), 0644)
 	if os.IsNotExist(err) {
 		WarningLog("sys key '%s' is not supported by os, skipping.", parameter)
 	} else if err != nil {
@@ -97,9 +97,9 @@ func TestSysString(parameter, value string) error {
 		WarningLog("failed to get sys key '%s': %v", parameter, err)
 		return err
 	}
-	if err = os.WriteFile(path.Join("/sys", strings.Replace(parameter, ".", "/", -1)), []byte(value), 0644); err == nil {
+	if err = os.WriteFile(path.Join("/sys", keyToPath(parameter)), []byte(value), 0644); err == nil {
 		// set key back to previous value, because this was only a test
-		err = os.WriteFile(path.Join("/sys", strings.Replace(parameter, ".", "/", -1)), []byte(save), 0644)
+		err = os.WriteFile(path.Join("/sys", keyToPath(parameter)), []byte(save), 0644)
 	}
 	return err
 }
@@ -114,6 +114,11 @@ func GetSysSearchParam(syskey string) (string, string) {
 	nrreq := regexp.MustCompile(`block.*queue\.nr_requests$`)
 	rakb := regexp.MustCompile(`block.*queue\.read_ahead_kb$`)
 	mskb := regexp.MustCompile(`block.*queue\.max_sectors_kb$`)
+	rqaff := regexp.MustCompile(`block.*queue\.rq_affinity$`)
+	nomrg := regexp.MustCompile(`block.*queue\.nomerges$`)
+	wbtlat := regexp.MustCompile(`block.*queue\.wbt_lat_usec$`)
+	addrnd := regexp.MustCompile(`block.*queue\.add_random$`)
+	iosched := regexp.MustCompile(`block.*queue\.iosched\.(\w+)$`)
 	dev := regexp.MustCompile(`block\.(.*)\.queue\..*$`)
 	d := dev.FindStringSubmatch(syskey)
 	bdev := ""
@@ -164,6 +169,37 @@ func GetSysSearchParam(syskey string) (string, string) {
 	case mskb.MatchString(syskey):
 		searchParam = "MAX_SECTORS_KB_" + bdev
 		sect = "block"
+	case IsRqAffinity.MatchString(syskey):
+		searchParam = "sys:block." + bdev + ".queue.rq_affinity"
+		sect = "sys"
+	case rqaff.MatchString(syskey):
+		searchParam = "RQ_AFFINITY_" + bdev
+		sect = "block"
+	case IsNomerges.MatchString(syskey):
+		searchParam = "sys:block." + bdev + ".queue.nomerges"
+		sect = "sys"
+	case nomrg.MatchString(syskey):
+		searchParam = "NOMERGES_" + bdev
+		sect = "block"
+	case IsWbtLat.MatchString(syskey):
+		searchParam = "sys:block." + bdev + ".queue.wbt_lat_usec"
+		sect = "sys"
+	case wbtlat.MatchString(syskey):
+		searchParam = "WBT_LAT_USEC_" + bdev
+		sect = "block"
+	case IsAddRandom.MatchString(syskey):
+		searchParam = "sys:block." + bdev + ".queue.add_random"
+		sect = "sys"
+	case addrnd.MatchString(syskey):
+		searchParam = "ADD_RANDOM_" + bdev
+		sect = "block"
+	case IsIOSched.MatchString(syskey):
+		tunable, dev := SplitIOSchedKey(syskey)
+		searchParam = "sys:block." + dev + ".queue.iosched." + tunable
+		sect = "sys"
+	case iosched.MatchString(syskey):
+		searchParam = "IOSCHED." + iosched.FindStringSubmatch(syskey)[1] + "_" + bdev
+		sect = "block"
 	}
 	return searchParam, sect
 }
@@ -234,3 +234,26 @@ func TestGetNrTags(t *testing.T) {
 	}
 	t.Logf("nrtags is '%+v', elev is '%+v', bdev is '%+v'\n", nrtags, elev, bdev)
 }
+
+func TestGetSysSearchParamBlkQueue(t *testing.T) {
+	tests := map[string][]string{
+		"RQ_AFFINITY_sdc":                         {"sys:block.sdc.queue.rq_affinity", "sys"},
+		"sys:block.sdc.queue.rq_affinity":         {"RQ_AFFINITY_sdc", "block"},
+		"NOMERGES_sdc":                            {"sys:block.sdc.queue.nomerges", "sys"},
+		"sys:block.sdc.queue.nomerges":            {"NOMERGES_sdc", "block"},
+		"WBT_LAT_USEC_sdc":                        {"sys:block.sdc.queue.wbt_lat_usec", "sys"},
+		"sys:block.sdc.queue.wbt_lat_usec":        {"WBT_LAT_USEC_sdc", "block"},
+		"ADD_RANDOM_sdc":                          {"sys:block.sdc.queue.add_random", "sys"},
+		"sys:block.sdc.queue.add_random":          {"ADD_RANDOM_sdc", "block"},
+		"IOSCHED.fifo_batch_sdc":                  {"sys:block.sdc.queue.iosched.fifo_batch", "sys"},
+		"sys:block.sdc.queue.iosched.fifo_batch":  {"IOSCHED.fifo_batch_sdc", "block"},
+		"IOSCHED.read_expire_nvme0n1":             {"sys:block.nvme0n1.queue.iosched.read_expire", "sys"},
+		"sys:block.nvme0n1.queue.iosched.low_lat": {"IOSCHED.low_lat_nvme0n1", "block"},
+	}
+	for skey, exp := range tests {
+		searchParam, sect := GetSysSearchParam(skey)
+		if searchParam != exp[0] || sect != exp[1] {
+			t.Errorf("'%s': expected '%s', '%s', got '%s', '%s'", skey, exp[0], exp[1], searchParam, sect)
+		}
+	}
+}
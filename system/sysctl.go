@@ -238,7 +238,7 @@ func SetSysctlString(parameter, value string) error {
 	if value == "" {
 		value = "\n"
 	}
-	err := os.WriteFile(path.Join("/proc/sys", strings.Replace(parameter, ".", "/", -1)), []byte(value), 0644)
+	err := os.WriteFile(path.Join("/proc/sys", keyToPath(parameter)), []byte(value), 0644)
 	if os.IsNotExist(err) {
 		WarningLog("sysctl key '%s' is not supported by os, skipping.", parameter)
 	} else if err != nil {
@@ -278,7 +278,7 @@ func SetSysctlUint64Field(param string, field int, value uint64) error {
 
 // IsPagecacheAvailable check, if system supports pagecache limit
 func IsPagecacheAvailable() bool {
-	_, err := os.ReadFile(path.Join("/proc/sys", strings.Replace(SysctlPagecacheLimitMB, ".", "/", -1)))
+	_, err := os.ReadFile(path.Join("/proc/sys", keyToPath(SysctlPagecacheLimitMB)))
 	return err == nil
 }
 
@@ -301,3 +301,72 @@ func IsValidSysctlLocations(location string) bool {
 	}
 	return validLocation
 }
+
+// IsGlobKey returns true, if a sysctl or sys key contains glob patterns
+// like 'net.ipv4.conf.*.rp_filter'
+func IsGlobKey(key string) bool {
+	return strings.ContainsAny(key, "*?")
+}
+
+// GlobSysctlKeys returns all sysctl keys available in /proc/sys, which
+// match the glob pattern of a sysctl key (e.g. 'net.ipv4.conf.*.rp_filter')
+func GlobSysctlKeys(pattern string) []string {
+	return globKeys("/proc/sys", pattern)
+}
+
+// GlobSysKeys returns all sys keys available in /sys, which match the glob
+// pattern of a sys key (e.g. 'block.*.queue.rq_affinity')
+func GlobSysKeys(pattern string) []string {
+	return globKeys("/sys", pattern)
+}
+
+// keyToPath converts a sysctl or sys key to the related path below /proc/sys
+// or /sys in the same way as sysctl(8) does. If the first separator of the
+// key is a '.', the characters '.' and '/' are swapped, so that a path
+// component containing a '.' (e.g. the vlan interface 'eth0.100') is written
+// with a '/' in the key ('net.ipv4.conf.eth0/100.rp_filter'). Otherwise the
+// key is already a path and used as it is
+func keyToPath(key string) string {
+	if idx := strings.IndexAny(key, "./"); idx < 0 || key[idx] == '/' {
+		return key
+	}
+	return strings.Map(swapDotSlash, key)
+}
+
+// pathToKey converts a path below /proc/sys or /sys to the related key
+// (see keyToPath)
+func pathToKey(relPath string) string {
+	return strings.Map(swapDotSlash, relPath)
+}
+
+// swapDotSlash swaps the characters '.' and '/'
+func swapDotSlash(r rune) rune {
+	switch r {
+	case '.':
+		return '/'
+	case '/':
+		return '.'
+	}
+	return r
+}
+
+// globKeys returns all keys (path components separated by '.') of the
+// files below basePath, which match the glob pattern of a key
+// a '.' in a path component (e.g. the sysctl keys of the vlan interface
+// 'eth0.100') is written as '/' in the key (see keyToPath)
+func globKeys(basePath, pattern string) []string {
+	keys := []string{}
+	matches, err := filepath.Glob(path.Join(basePath, keyToPath(pattern)))
+	if err != nil {
+		WarningLog("wrong syntax of glob pattern '%s' - %v", pattern, err)
+		return keys
+	}
+	for _, match := range matches {
+		if info, err := os.Stat(match); err != nil || info.IsDir() {
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(match, basePath), "/")
+		keys = append(keys, pathToKey(rel))
+	}
+	return keys
+}
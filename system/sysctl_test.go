@@ -1,6 +1,11 @@
 package system
 
-import "testing"
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
 
 func TestReadSysctl(t *testing.T) {
 	if value, err := GetSysctlInt("vm.max_map_count"); err != nil {
@@ -148,3 +153,61 @@ func TestIsValidSysctlLocations(t *testing.T) {
 		t.Errorf("file '%s' reported as valid sysctl file location, but is invalid", file)
 	}
 }
+
+func TestKeyToPath(t *testing.T) {
+	tests := map[string]string{
+		"vm.max_map_count":                 "vm/max_map_count",
+		"net.ipv4.conf.eth0/100.rp_filter": "net/ipv4/conf/eth0.100/rp_filter",
+		"net/ipv4/conf/eth0.100/rp_filter": "net/ipv4/conf/eth0.100/rp_filter",
+		"block/sda/queue/scheduler":        "block/sda/queue/scheduler",
+		"kernel":                           "kernel",
+	}
+	for key, exp := range tests {
+		if val := keyToPath(key); val != exp {
+			t.Errorf("'%s': expected '%s', got '%s'", key, exp, val)
+		}
+	}
+	if key := pathToKey("net/ipv4/conf/eth0.100/rp_filter"); key != "net.ipv4.conf.eth0/100.rp_filter" {
+		t.Errorf("got '%s'", key)
+	}
+}
+
+func TestGlobKeys(t *testing.T) {
+	if !IsGlobKey("net.ipv4.conf.*.rp_filter") || IsGlobKey("vm.max_map_count") {
+		t.Error("wrong glob key detection")
+	}
+	tstDir := t.TempDir()
+	for _, dir := range []string{"conf/all", "conf/eth0", "conf/eth0.100", "conf/nofile"} {
+		if err := os.MkdirAll(path.Join(tstDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"conf/all/rp_filter", "conf/eth0/rp_filter", "conf/eth0.100/rp_filter", "conf/all/forwarding"} {
+		if err := os.WriteFile(path.Join(tstDir, file), []byte("1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a '.' in a path component is written as '/' in the key
+	keys := globKeys(tstDir, "conf.*.rp_filter")
+	if strings.Join(keys, " ") != "conf.all.rp_filter conf.eth0.rp_filter conf.eth0/100.rp_filter" {
+		t.Errorf("got: '%v'", keys)
+	}
+	keys = globKeys(tstDir, "conf.all.*")
+	if strings.Join(keys, " ") != "conf.all.forwarding conf.all.rp_filter" {
+		t.Errorf("got: '%v'", keys)
+	}
+	// directories are no keys
+	if keys = globKeys(tstDir, "conf.*"); len(keys) != 0 {
+		t.Errorf("got: '%v'", keys)
+	}
+	if keys = globKeys(tstDir, "conf.*.unknown"); len(keys) != 0 {
+		t.Errorf("got: '%v'", keys)
+	}
+	if keys = globKeys(tstDir, "conf.eth0/100.*"); strings.Join(keys, " ") != "conf.eth0/100.rp_filter" {
+		t.Errorf("got: '%v'", keys)
+	}
+	keys = GlobSysctlKeys("net.ipv4.conf.*.rp_filter")
+	if !strings.Contains(strings.Join(keys, " "), "net.ipv4.conf.all.rp_filter") {
+		t.Errorf("got: '%v'", keys)
+	}
+}
@@ -28,6 +28,18 @@ const SaptuneSavedStateDir = "/run/saptune/saved_state"
 // separated from the note state file directory
 const SaptuneParameterStateDir = "/run/saptune/parameter"
 
+// SaptuneBaselineDir defines the directory where to store the first-ever
+// start values of the parameters. Survives a reboot
+const SaptuneBaselineDir = "/var/lib/saptune/baseline"
+
+// SaptuneSnapshotDir defines the directory where to store the snapshots of
+// the system tuning. Survives a reboot
+const SaptuneSnapshotDir = "/var/lib/saptune/snapshots"
+
+// SaptuneHistoryFile defines the file, which contains the history of all
+// parameter changes. One JSON entry per line, only appended
+const SaptuneHistoryFile = "/var/log/saptune/history.jsonl"
+
 // RPMBldVers is the version of the RPM build process (suse_version)
 // defaults to '15'
 // needs to be a string as replacement with -X during build does not work
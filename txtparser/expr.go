@@ -0,0 +1,243 @@
+package txtparser
+
+// Evaluate computed values of the note definition files
+// e.g. 'kernel.shmmax = ${MemTotalBytes} * 0.8'
+// supported are the operators '+', '-', '*', '/', '%', parentheses, the
+// functions 'min()' and 'max()', host facts as variables '${<name>}' and
+// numbers with the binary size units 'K', 'M', 'G' and 'T' (e.g. '4G')
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// isExprFunc matches the functions supported in expressions
+var isExprFunc = regexp.MustCompile(`\b(min|max)\s*\(`)
+
+// IsUnitSize matches a number with a binary size unit (e.g. '4G')
+var IsUnitSize = regexp.MustCompile(`^\d+(\.\d+)?[KMGT]$`)
+
+// unitShift contains the binary size units and their shift values
+var unitShift = map[byte]uint{'K': 10, 'M': 20, 'G': 30, 'T': 40}
+
+// IsExpression returns true, if the value contains a variable or a function
+// and needs to be evaluated
+func IsExpression(value string) bool {
+	return strings.Contains(value, "${") || isExprFunc.MatchString(value)
+}
+
+// exprParser holds the state of the evaluation of an expression
+type exprParser struct {
+	expr  string
+	pos   int
+	facts map[string]uint64
+}
+
+// EvalExpression evaluates an expression with the given host facts as
+// variables. The result is rounded down to an integer
+func EvalExpression(expr string, facts map[string]uint64) (string, error) {
+	p := &exprParser{expr: expr, facts: facts}
+	val, err := p.parseSum()
+	if err != nil {
+		return "", err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.expr) {
+		return "", fmt.Errorf("unexpected '%s' at position %d", p.expr[p.pos:], p.pos+1)
+	}
+	if math.IsInf(val, 0) || math.IsNaN(val) {
+		return "", fmt.Errorf("result is not a number")
+	}
+	return strconv.FormatFloat(math.Floor(val), 'f', 0, 64), nil
+}
+
+// skipSpaces moves the position behind the following white spaces
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.expr) && (p.expr[p.pos] == ' ' || p.expr[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek returns the next not white space character or 0 at the end of the
+// expression
+func (p *exprParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.expr) {
+		return 0
+	}
+	return p.expr[p.pos]
+}
+
+// parseSum handles additions and subtractions
+func (p *exprParser) parseSum() (float64, error) {
+	val, err := p.parseProduct()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return val, nil
+		}
+		p.pos++
+		rval, err := p.parseProduct()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			val = val + rval
+		} else {
+			val = val - rval
+		}
+	}
+}
+
+// parseProduct handles multiplications, divisions and modulo
+func (p *exprParser) parseProduct() (float64, error) {
+	val, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return val, nil
+		}
+		p.pos++
+		rval, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			val = val * rval
+		case '/', '%':
+			if rval == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			if op == '/' {
+				val = val / rval
+			} else {
+				val = math.Mod(val, rval)
+			}
+		}
+	}
+}
+
+// parseUnary handles the sign of a value
+func (p *exprParser) parseUnary() (float64, error) {
+	if p.peek() == '-' {
+		p.pos++
+		val, err := p.parseUnary()
+		return -val, err
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary handles numbers, variables, functions and parentheses
+func (p *exprParser) parsePrimary() (float64, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return 0, fmt.Errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		val, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing ')' at position %d", p.pos+1)
+		}
+		p.pos++
+		return val, nil
+	case c == '$':
+		return p.parseVariable()
+	case c >= '0' && c <= '9' || c == '.':
+		return p.parseNumber()
+	case c >= 'a' && c <= 'z':
+		return p.parseFunction()
+	}
+	return 0, fmt.Errorf("unexpected '%c' at position %d", c, p.pos+1)
+}
+
+// parseNumber handles a number with an optional binary size unit
+func (p *exprParser) parseNumber() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.expr) && (p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' || p.expr[p.pos] == '.') {
+		p.pos++
+	}
+	val, err := strconv.ParseFloat(p.expr[start:p.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("wrong number '%s'", p.expr[start:p.pos])
+	}
+	if p.pos < len(p.expr) {
+		if shift, ok := unitShift[p.expr[p.pos]]; ok {
+			p.pos++
+			val = val * float64(uint64(1)<<shift)
+		}
+	}
+	return val, nil
+}
+
+// parseVariable handles a host fact '${<name>}'
+func (p *exprParser) parseVariable() (float64, error) {
+	if !strings.HasPrefix(p.expr[p.pos:], "${") {
+		return 0, fmt.Errorf("missing '{' at position %d", p.pos+2)
+	}
+	end := strings.Index(p.expr[p.pos:], "}")
+	if end < 0 {
+		return 0, fmt.Errorf("missing '}' for variable at position %d", p.pos+1)
+	}
+	name := p.expr[p.pos+2 : p.pos+end]
+	p.pos = p.pos + end + 1
+	val, ok := p.facts[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown variable '%s'", name)
+	}
+	return float64(val), nil
+}
+
+// parseFunction handles the functions 'min()' and 'max()'
+func (p *exprParser) parseFunction() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.expr) && p.expr[p.pos] >= 'a' && p.expr[p.pos] <= 'z' {
+		p.pos++
+	}
+	name := p.expr[start:p.pos]
+	if name != "min" && name != "max" {
+		return 0, fmt.Errorf("unknown function '%s'", name)
+	}
+	if p.peek() != '(' {
+		return 0, fmt.Errorf("missing '(' for function '%s'", name)
+	}
+	p.pos++
+	args := []float64{}
+	for {
+		val, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, val)
+		c := p.peek()
+		p.pos++
+		if c == ')' {
+			break
+		}
+		if c != ',' {
+			return 0, fmt.Errorf("missing ')' for function '%s'", name)
+		}
+	}
+	ret := args[0]
+	for _, val := range args[1:] {
+		if name == "min" {
+			ret = math.Min(ret, val)
+		} else {
+			ret = math.Max(ret, val)
+		}
+	}
+	return ret, nil
+}
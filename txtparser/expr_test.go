@@ -0,0 +1,51 @@
+package txtparser
+
+import (
+	"testing"
+)
+
+func TestIsExpression(t *testing.T) {
+	for _, val := range []string{"${MemTotalBytes} * 0.8", "min(4G, 10)", "max (1,2)", "4096 ${CPUs}"} {
+		if !IsExpression(val) {
+			t.Errorf("'%s' not detected as expression", val)
+		}
+	}
+	for _, val := range []string{"4096", "4G", "noop", "0-3", "4096 87380 6291456", "$MemTotalBytes"} {
+		if IsExpression(val) {
+			t.Errorf("'%s' wrongly detected as expression", val)
+		}
+	}
+	if !IsUnitSize.MatchString("4G") || !IsUnitSize.MatchString("1.5T") || IsUnitSize.MatchString("4GB") || IsUnitSize.MatchString("G") {
+		t.Error("wrong detection of unit sizes")
+	}
+}
+
+func TestEvalExpression(t *testing.T) {
+	facts := map[string]uint64{"MemTotalBytes": 1000, "CPUs": 8, "NUMANodes": 2}
+	tests := map[string]string{
+		"${MemTotalBytes} * 0.8":        "800",
+		"${MemTotalBytes}*0.8":          "800",
+		"4G":                            "4294967296",
+		"1.5K":                          "1536",
+		"min(${MemTotalBytes}, 4K)":     "1000",
+		"max(${MemTotalBytes}, 4K, 1M)": "1048576",
+		"(${CPUs} + 2) * ${NUMANodes}":  "20",
+		"${CPUs} + 2 * ${NUMANodes}":    "12",
+		"${CPUs} / 3":                   "2",
+		"${CPUs} % 3":                   "2",
+		"-${CPUs} + 10":                 "2",
+		"10 - 2 - 3":                    "5",
+		"min(max(1, 2), 3)":             "2",
+	}
+	for expr, exp := range tests {
+		val, err := EvalExpression(expr, facts)
+		if err != nil || val != exp {
+			t.Errorf("'%s': expected '%s', got '%s' - '%v'", expr, exp, val, err)
+		}
+	}
+	for _, expr := range []string{"${Unknown} * 2", "${CPUs / 2", "${CPUs} / 0", "${CPUs} % 0", "avg(1, 2)", "min(1, 2", "(1 + 2", "1 +", "2 3", "1 & 2", "$CPUs", ""} {
+		if val, err := EvalExpression(expr, facts); err == nil {
+			t.Errorf("'%s': expected error, got '%s'", expr, val)
+		}
+	}
+}
@@ -14,17 +14,24 @@ const (
 	OperatorMoreThan      = ">"
 	OperatorMoreThanEqual = ">="
 	OperatorEqual         = "="
+	OperatorRange         = "><"
+	OperatorInSet         = "=|"
+	OperatorRegex         = "=~"
 )
 
 // Operator is the comparison or assignment operator used in an INI file entry
 type Operator string
 
 // RegexKeyOperatorValue breaks up a line into key, operator, value.
-var RegexKeyOperatorValue = regexp.MustCompile(`([\w.+_-]+)\s*([<=>]+)\s*["']*(.*?)["']*$`)
+var RegexKeyOperatorValue = regexp.MustCompile(`([\w.+_*?-]+)\s*([<=>|~]+)\s*["']*(.*?)["']*$`)
 
 // regKey gives the parameter part of the line from the note definition file
 var regKey = regexp.MustCompile(`(.*)\s*[<=>]+\s*["']*.*?["']*$`)
 
+// fsMountPrefix matches the mount point prefix of an option list in the
+// [filesystem] section ('/hana/shared /usr/sap/trans: +hard')
+var fsMountPrefix = regexp.MustCompile(`^\s*/[^:]*:`)
+
 // counter to control the [login] section info message
 var loginCnt = 0
 
@@ -85,7 +92,7 @@ func splitLineIntoKOV(curSection, line string) []string {
 			return nil
 		}
 		kov = RegexKeyOperatorValue.FindStringSubmatch(line)
-		if curSection == "grub" || curSection == "sys" || curSection == "service" {
+		if curSection == "grub" || curSection == "sys" || curSection == "service" || curSection == "kernelmodule" || curSection == "irq" {
 			kov = splitSectLine(curSection, line, kov)
 		}
 	}
@@ -125,6 +132,9 @@ func splitSectLine(sect, line string, kov []string) []string {
 	if sect == "service" {
 		sect = "systemd"
 	}
+	if sect == "kernelmodule" {
+		sect = "kmod"
+	}
 	if len(kov) == 0 {
 		// seams to be a single option and not
 		// a key=value pair
@@ -156,6 +166,9 @@ func ParseINI(input string) *INIFile {
 	}
 
 	reminder := ""
+	irqScope := ""
+	netdevs := []string{}
+	cgroupUnit := ""
 	bdevs := []string{}
 	skipSection := false
 	next := false
@@ -207,9 +220,8 @@ func ParseINI(input string) *INIFile {
 			sectionFields := strings.Split(currentSection, ":")
 
 			// collect system wide sysctl settings
-			if sectionFields[0] == "sysctl" && sysctlCnt == 0 {
-				sysctlCnt = sysctlCnt + 1
-				system.CollectGlobalSysctls(excludeDirs)
+			if sectionFields[0] == "sysctl" {
+				CollectGlobalSysctls()
 			}
 			// moved block device collection so that the info can be
 			// used inside the 'tag' checks
@@ -226,6 +238,12 @@ func ParseINI(input string) *INIFile {
 				chkOk, bdevs = chkSecTags(sectionFields, bdevs)
 			}
 			if chkOk {
+				// interrupt pattern from the section tag 'irqpat'
+				irqScope = getSectTag(sectionFields, "irqpat")
+				// systemd unit from the section tag 'unit'
+				cgroupUnit = getSectTag(sectionFields, "unit")
+				// network interfaces matching the section tags
+				netdevs = netDevCollect(sectionFields)
 				currentSection = sectionFields[0]
 				currentEntriesArray = make([]INIEntry, 0, 8)
 				currentEntriesMap = make(map[string]INIEntry)
@@ -262,6 +280,21 @@ func ParseINI(input string) *INIFile {
 		if next {
 			continue
 		}
+		// write the net section data
+		next, currentEntriesArray, currentEntriesMap = writeNetSectionData(currentSection, netdevs, kov, currentEntriesArray, currentEntriesMap)
+		if next {
+			continue
+		}
+		// write the cgroup section data
+		next, currentEntriesArray, currentEntriesMap = writeCgroupSectionData(currentSection, cgroupUnit, kov, currentEntriesArray, currentEntriesMap)
+		if next {
+			continue
+		}
+		// write the irq section data
+		next, currentEntriesArray, currentEntriesMap = writeIRQSectionData(currentSection, irqScope, kov, currentEntriesArray, currentEntriesMap)
+		if next {
+			continue
+		}
 		// handle tunables with more than one value
 		currentEntriesArray, currentEntriesMap = writeMultiValueData(currentSection, kov, currentEntriesArray, currentEntriesMap)
 	}
@@ -292,7 +325,7 @@ func blkInfoNeeded(sectFields []string) bool {
 	if sectFields[0] == "block" {
 		found = true
 	} else {
-		tags := []string{"blkvendor", "blkmodel", "blkpat"}
+		tags := []string{"blkvendor", "blkmodel", "blkpat", "mount"}
 		for _, tag := range tags {
 			if isTagAvail(tag, sectFields) {
 				found = true
@@ -331,31 +364,53 @@ func handleUserTaskMax(lc int, kov []string) (bool, int) {
 
 // writeFSSectionData adds the values from the filesystem section to the
 // data structures
+// supported are '<fstype>_options' with <fstype> from system.FSOptTypes
+// The option list can be restricted to dedicated mount points by a prefix
+// '<mount point> [<mount point> ...]:' in the value. In this case one entry
+// per mount point and option is created.
 func writeFSSectionData(curSec string, kov []string, curEntriesArray []INIEntry, curEntriesMap map[string]INIEntry) (bool, []INIEntry, map[string]INIEntry) {
 	next := true
 	if curSec != "filesystem" {
 		return false, curEntriesArray, curEntriesMap
 	}
 
-	switch kov[1] {
-	case "xfs_options":
-		if kov[3] == "" {
-			// empty xfs_options - 'untouched'
-			key := "xfsopt_*"
-			entry := INIEntry{
-				Section:  curSec,
-				Key:      key,
-				Operator: Operator(kov[2]),
-				Value:    kov[3],
-			}
-			curEntriesArray = append(curEntriesArray, entry)
-			curEntriesMap[entry.Key] = entry
-			return next, curEntriesArray, curEntriesMap
+	fstype := strings.TrimSuffix(kov[1], "_options")
+	if fstype == kov[1] || !isSupportedFSType(fstype) {
+		system.WarningLog("unsupported parameter name '%s' for section '%s'", kov[1], curSec)
+		return next, curEntriesArray, curEntriesMap
+	}
+	if kov[3] == "" {
+		// empty <fstype>_options - 'untouched'
+		key := fmt.Sprintf("%sopt_*", fstype)
+		entry := INIEntry{
+			Section:  curSec,
+			Key:      key,
+			Operator: Operator(kov[2]),
+			Value:    kov[3],
 		}
-		for _, option := range strings.Split(kov[3], ",") {
+		curEntriesArray = append(curEntriesArray, entry)
+		curEntriesMap[entry.Key] = entry
+		return next, curEntriesArray, curEntriesMap
+	}
+	options := kov[3]
+	mntPts := []string{""}
+	if fsMountPrefix.MatchString(options) {
+		// option list restricted to mount points
+		fields := strings.SplitN(options, ":", 2)
+		mntPts = strings.Fields(fields[0])
+		options = fields[1]
+	}
+	for _, mntPt := range mntPts {
+		for _, option := range strings.Split(options, ",") {
 			option = strings.TrimSpace(option)
+			if option == "" {
+				continue
+			}
 			opt := strings.TrimLeft(option, "+-")
-			key := fmt.Sprintf("xfsopt_%s", opt)
+			key := fmt.Sprintf("%sopt_%s", fstype, opt)
+			if mntPt != "" {
+				key = key + "@" + mntPt
+			}
 
 			entry := INIEntry{
 				Section:  curSec,
@@ -366,12 +421,21 @@ func writeFSSectionData(curSec string, kov []string, curEntriesArray []INIEntry,
 			curEntriesArray = append(curEntriesArray, entry)
 			curEntriesMap[entry.Key] = entry
 		}
-	default:
-		system.WarningLog("unsupported parameter name '%s' for section '%s'", kov[1], curSec)
 	}
 	return next, curEntriesArray, curEntriesMap
 }
 
+// isSupportedFSType checks, if the file system type is supported in
+// section [filesystem]
+func isSupportedFSType(fstype string) bool {
+	for _, fst := range system.FSOptTypes {
+		if fst == fstype {
+			return true
+		}
+	}
+	return false
+}
+
 // writeLimitSectionData adds the values from the limit section to the
 // data structures
 func writeLimitSectionData(curSec string, kov []string, curEntriesArray []INIEntry, curEntriesMap map[string]INIEntry) (bool, []INIEntry, map[string]INIEntry) {
@@ -430,9 +494,119 @@ func writeBlockSectionData(curSec string, bdevs, kov []string, curEntriesArray [
 	return next, curEntriesArray, curEntriesMap
 }
 
+// writeNetSectionData adds the values from the net section to the
+// data structures
+func writeNetSectionData(curSec string, netdevs, kov []string, curEntriesArray []INIEntry, curEntriesMap map[string]INIEntry) (bool, []INIEntry, map[string]INIEntry) {
+	if curSec != "net" {
+		return false, curEntriesArray, curEntriesMap
+	}
+	// netdevs contains all network interfaces valid for the
+	// current net section regarding to the used tags
+	for _, netdev := range netdevs {
+		entry := INIEntry{
+			Section:  curSec,
+			Key:      fmt.Sprintf("%s@%s", kov[1], netdev),
+			Operator: Operator(kov[2]),
+			Value:    kov[3],
+		}
+		curEntriesArray = append(curEntriesArray, entry)
+		curEntriesMap[entry.Key] = entry
+	}
+	return true, curEntriesArray, curEntriesMap
+}
+
+// writeCgroupSectionData adds the values from the cgroup section to the
+// data structures
+// the systemd unit from the section tag 'unit' or the default unit is
+// added to the parameter name
+func writeCgroupSectionData(curSec, unit string, kov []string, curEntriesArray []INIEntry, curEntriesMap map[string]INIEntry) (bool, []INIEntry, map[string]INIEntry) {
+	if curSec != "cgroup" {
+		return false, curEntriesArray, curEntriesMap
+	}
+	if unit == "" {
+		unit = system.DefaultCgroupUnit
+	}
+	entry := INIEntry{
+		Section:  curSec,
+		Key:      fmt.Sprintf("%s@%s", kov[1], unit),
+		Operator: Operator(kov[2]),
+		Value:    kov[3],
+	}
+	curEntriesArray = append(curEntriesArray, entry)
+	curEntriesMap[entry.Key] = entry
+	return true, curEntriesArray, curEntriesMap
+}
+
+// netDevCollect returns the network interfaces valid for a net section
+// regarding to the tags 'netpat' and 'netdriver'
+func netDevCollect(sectFields []string) []string {
+	if sectFields[0] != "net" {
+		return []string{}
+	}
+	netdevs := system.GetNetInterfaces()
+	for _, secTag := range sectFields[1:] {
+		tagField := strings.Split(secTag, "=")
+		if len(tagField) != 2 || (tagField[0] != "netpat" && tagField[0] != "netdriver") {
+			continue
+		}
+		// as it is possible to have more than one tag in a
+		// section we need the overlap
+		matching := system.GetNetInterfacesByTag(strings.TrimPrefix(tagField[0], "net"), tagField[1])
+		newdevs := []string{}
+		for _, a := range netdevs {
+			for _, b := range matching {
+				if a == b {
+					newdevs = append(newdevs, a)
+				}
+			}
+		}
+		netdevs = newdevs
+	}
+	return netdevs
+}
+
+// writeIRQSectionData adds the values from the irq section to the
+// data structures
+// the interrupt pattern from the section tag 'irqpat' is added to the
+// parameter name to scope the setting to the matching interrupts
+func writeIRQSectionData(curSec, irqScope string, kov []string, curEntriesArray []INIEntry, curEntriesMap map[string]INIEntry) (bool, []INIEntry, map[string]INIEntry) {
+	if curSec != "irq" {
+		return false, curEntriesArray, curEntriesMap
+	}
+	key := kov[1]
+	if irqScope != "" && key != "irq:"+system.IRQBalanceBannedCPUs {
+		key = key + "@" + irqScope
+	}
+	entry := INIEntry{
+		Section:  curSec,
+		Key:      key,
+		Operator: Operator(kov[2]),
+		Value:    kov[3],
+	}
+	curEntriesArray = append(curEntriesArray, entry)
+	curEntriesMap[entry.Key] = entry
+	return true, curEntriesArray, curEntriesMap
+}
+
+// getSectTag returns the value of a section tag or an empty string, if
+// the tag is not used in the section definition
+func getSectTag(sectFields []string, tag string) string {
+	for _, secTag := range sectFields[1:] {
+		tagField := strings.Split(secTag, "=")
+		if len(tagField) == 2 && tagField[0] == tag {
+			return tagField[1]
+		}
+	}
+	return ""
+}
+
 // writeMultiValueData handles tunables with more than one value
 func writeMultiValueData(curSec string, kov []string, curEntriesArray []INIEntry, curEntriesMap map[string]INIEntry) ([]INIEntry, map[string]INIEntry) {
-	value := strings.Replace(kov[3], " ", "\t", -1)
+	value := kov[3]
+	if kov[2] != OperatorRegex {
+		// a regular expression may contain spaces
+		value = strings.Replace(value, " ", "\t", -1)
+	}
 	entry := INIEntry{
 		Section:  curSec,
 		Key:      kov[1],
@@ -444,6 +618,56 @@ func writeMultiValueData(curSec string, kov []string, curEntriesArray []INIEntry
 	return curEntriesArray, curEntriesMap
 }
 
+// ExpandGlobKeys expands the keys of the sections 'sysctl' and 'sys'
+// containing glob patterns (e.g. 'net.ipv4.conf.*.rp_filter') to the
+// matching keys available in /proc/sys and /sys. Each expanded key gets its
+// own entry, so that verify and revert work per key.
+// Keys explicitly defined in the same section take precedence over the
+// expanded keys, the first matching glob pattern over the following ones.
+func ExpandGlobKeys(ini *INIFile) *INIFile {
+	ret := &INIFile{
+		AllValues: make([]INIEntry, 0, len(ini.AllValues)),
+		KeyValue:  make(map[string]map[string]INIEntry),
+	}
+	for section := range ini.KeyValue {
+		ret.KeyValue[section] = make(map[string]INIEntry)
+	}
+	for _, entry := range ini.AllValues {
+		if (entry.Section != "sysctl" && entry.Section != "sys") || !system.IsGlobKey(entry.Key) {
+			ret.AllValues = append(ret.AllValues, entry)
+			ret.KeyValue[entry.Section][entry.Key] = entry
+			continue
+		}
+		prefix := ""
+		keys := []string{}
+		if entry.Section == "sys" {
+			prefix = "sys:"
+			keys = system.GlobSysKeys(strings.TrimPrefix(entry.Key, prefix))
+		} else {
+			keys = system.GlobSysctlKeys(entry.Key)
+		}
+		if len(keys) == 0 {
+			system.InfoLog("no parameter found matching '%s' in section '%s'. Skipping", entry.Key, entry.Section)
+			continue
+		}
+		for _, key := range keys {
+			if _, ok := ini.KeyValue[entry.Section][prefix+key]; ok {
+				// explicitly defined key
+				continue
+			}
+			if _, ok := ret.KeyValue[entry.Section][prefix+key]; ok {
+				// already expanded by a previous glob pattern
+				continue
+			}
+			expEntry := entry
+			expEntry.Key = prefix + key
+			ret.AllValues = append(ret.AllValues, expEntry)
+			ret.KeyValue[entry.Section][expEntry.Key] = expEntry
+		}
+	}
+	return ret
+}
+
 // writeReminderSectionData adds the values from the reminder section to the
 // end of the data structures
 func writeReminderSectionData(rem string) ([]INIEntry, map[string]INIEntry, string) {
@@ -463,6 +687,15 @@ func writeReminderSectionData(rem string) ([]INIEntry, map[string]INIEntry, stri
 	return curEntriesArray, curEntriesMap, curSection
 }
 
+// CollectGlobalSysctls collects the system wide sysctl settings, but only
+// once per saptune call
+func CollectGlobalSysctls() {
+	if sysctlCnt == 0 {
+		sysctlCnt = sysctlCnt + 1
+		system.CollectGlobalSysctls(excludeDirs)
+	}
+}
+
 // GetSysctlExcludes gets the content of the /etc/sysconfig/saptune
 // variable 'SKIP_SYSCTL_FILES' and provides it as slice
 func GetSysctlExcludes(skipFiles string) {
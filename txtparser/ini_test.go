@@ -384,6 +384,10 @@ func TestBlkInfoNeeded(t *testing.T) {
 	if blkInfoNeeded(sectFields) {
 		t.Error("should be 'false', but returns 'true'")
 	}
+	sectFields = []string{"sys", "mount=/hana/data"}
+	if !blkInfoNeeded(sectFields) {
+		t.Error("should be 'true', but returns 'false'")
+	}
 }
 
 func TestGetSysctlExcludes(t *testing.T) {
@@ -396,3 +400,184 @@ func TestGetSysctlExcludes(t *testing.T) {
 	t.Log(excludeDirs)
 	excludeDirs = excludeDirsOrg
 }
+
+func TestWriteFSSectionData(t *testing.T) {
+	entriesArray := []INIEntry{}
+	entriesMap := map[string]INIEntry{}
+	next, entriesArray, entriesMap := writeFSSectionData("sysctl", []string{"", "xfs_options", "=", "-nobarrier"}, entriesArray, entriesMap)
+	if next || len(entriesArray) != 0 {
+		t.Errorf("wrong section should not be handled")
+	}
+	_, entriesArray, entriesMap = writeFSSectionData("filesystem", []string{"", "xfs_options", "=", "-nobarrier, +relatime"}, entriesArray, entriesMap)
+	_, entriesArray, entriesMap = writeFSSectionData("filesystem", []string{"", "nfs_options", "=", "/hana/shared /usr/sap/trans: +hard, +vers=4.1"}, entriesArray, entriesMap)
+	_, entriesArray, entriesMap = writeFSSectionData("filesystem", []string{"", "ext4_options", "=", ""}, entriesArray, entriesMap)
+	_, entriesArray, entriesMap = writeFSSectionData("filesystem", []string{"", "vfat_options", "=", "noatime"}, entriesArray, entriesMap)
+	expKeys := []string{"xfsopt_nobarrier", "xfsopt_relatime", "nfsopt_hard@/hana/shared", "nfsopt_vers=4.1@/hana/shared", "nfsopt_hard@/usr/sap/trans", "nfsopt_vers=4.1@/usr/sap/trans", "ext4opt_*"}
+	expVals := []string{"-nobarrier", "+relatime", "+hard", "+vers=4.1", "+hard", "+vers=4.1", ""}
+	if len(entriesArray) != len(expKeys) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(expKeys), len(entriesArray), entriesArray)
+	}
+	for i, entry := range entriesArray {
+		if entry.Key != expKeys[i] || entry.Value != expVals[i] {
+			t.Errorf("expected '%s=%s', got '%s=%s'", expKeys[i], expVals[i], entry.Key, entry.Value)
+		}
+		if _, ok := entriesMap[entry.Key]; !ok {
+			t.Errorf("entry '%s' missing in map", entry.Key)
+		}
+	}
+}
+
+func TestSplitSectLine(t *testing.T) {
+	kov := splitLineIntoKOV("kernelmodule", "nvme_core.io_timeout = 4294967295")
+	if len(kov) != 4 || kov[1] != "kmod:nvme_core.io_timeout" || kov[3] != "4294967295" {
+		t.Errorf("got '%+v'", kov)
+	}
+	kov = splitLineIntoKOV("kernelmodule", "floppy = blacklist")
+	if len(kov) != 4 || kov[1] != "kmod:floppy" || kov[3] != "blacklist" {
+		t.Errorf("got '%+v'", kov)
+	}
+	kov = splitLineIntoKOV("service", "uuidd.socket = start")
+	if len(kov) != 4 || kov[1] != "systemd:uuidd.socket" || kov[3] != "start" {
+		t.Errorf("got '%+v'", kov)
+	}
+	kov = splitLineIntoKOV("grub", "showopts")
+	if len(kov) != 4 || kov[1] != "grub:showopts" || kov[3] != "showopts" {
+		t.Errorf("got '%+v'", kov)
+	}
+}
+
+func TestWriteIRQSectionData(t *testing.T) {
+	entriesArray := []INIEntry{}
+	entriesMap := map[string]INIEntry{}
+	next, entriesArray, entriesMap := writeIRQSectionData("sysctl", "", []string{"", "irq:nvme", "=", "0-3"}, entriesArray, entriesMap)
+	if next || len(entriesArray) != 0 {
+		t.Errorf("wrong section should not be handled")
+	}
+	_, entriesArray, entriesMap = writeIRQSectionData("irq", "", []string{"", "irq:nvme", "=", "0-3"}, entriesArray, entriesMap)
+	_, entriesArray, entriesMap = writeIRQSectionData("irq", "mlx5", []string{"", "irq:comp", "=", "4-7"}, entriesArray, entriesMap)
+	_, entriesArray, entriesMap = writeIRQSectionData("irq", "mlx5", []string{"", "irq:IRQBALANCE_BANNED_CPULIST", "=", "0-7"}, entriesArray, entriesMap)
+	expKeys := []string{"irq:nvme", "irq:comp@mlx5", "irq:IRQBALANCE_BANNED_CPULIST"}
+	if len(entriesArray) != len(expKeys) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(expKeys), len(entriesArray), entriesArray)
+	}
+	for i, entry := range entriesArray {
+		if entry.Key != expKeys[i] {
+			t.Errorf("expected '%s', got '%s'", expKeys[i], entry.Key)
+		}
+		if _, ok := entriesMap[entry.Key]; !ok {
+			t.Errorf("entry '%s' missing in map", entry.Key)
+		}
+	}
+	if scope := getSectTag([]string{"irq", "os=15-*", "irqpat=mlx5"}, "irqpat"); scope != "mlx5" {
+		t.Errorf("expected 'mlx5', got '%s'", scope)
+	}
+	if scope := getSectTag([]string{"irq"}, "irqpat"); scope != "" {
+		t.Errorf("expected '', got '%s'", scope)
+	}
+}
+
+func TestWriteNetSectionData(t *testing.T) {
+	entriesArray := []INIEntry{}
+	entriesMap := map[string]INIEntry{}
+	next, entriesArray, entriesMap := writeNetSectionData("sysctl", []string{"eth0"}, []string{"", "MTU", "=", "9000"}, entriesArray, entriesMap)
+	if next || len(entriesArray) != 0 {
+		t.Errorf("wrong section should not be handled")
+	}
+	next, entriesArray, entriesMap = writeNetSectionData("net", []string{"eth0", "eth1"}, []string{"", "ring_rx", "=", "4096"}, entriesArray, entriesMap)
+	if !next || len(entriesArray) != 2 || entriesArray[0].Key != "ring_rx@eth0" || entriesArray[1].Key != "ring_rx@eth1" {
+		t.Errorf("got '%+v'", entriesArray)
+	}
+	if _, ok := entriesMap["ring_rx@eth1"]; !ok {
+		t.Errorf("entry 'ring_rx@eth1' missing in map")
+	}
+	if devs := netDevCollect([]string{"sysctl"}); len(devs) != 0 {
+		t.Errorf("got '%+v'", devs)
+	}
+	if devs := netDevCollect([]string{"net", "netpat=saptune_unknown_if"}); len(devs) != 0 {
+		t.Errorf("got '%+v'", devs)
+	}
+}
+
+func TestWriteCgroupSectionData(t *testing.T) {
+	entriesArray := []INIEntry{}
+	entriesMap := map[string]INIEntry{}
+	next, entriesArray, entriesMap := writeCgroupSectionData("sysctl", "", []string{"", "CPUWeight", "=", "1000"}, entriesArray, entriesMap)
+	if next || len(entriesArray) != 0 {
+		t.Errorf("wrong section should not be handled")
+	}
+	_, entriesArray, entriesMap = writeCgroupSectionData("cgroup", "", []string{"", "CPUWeight", "=", "1000"}, entriesArray, entriesMap)
+	_, entriesArray, entriesMap = writeCgroupSectionData("cgroup", "sapinit@HA1.service", []string{"", "MemoryLow", "=", "8G"}, entriesArray, entriesMap)
+	if len(entriesArray) != 2 || entriesArray[0].Key != "CPUWeight@sap.slice" || entriesArray[1].Key != "MemoryLow@sapinit@HA1.service" {
+		t.Errorf("got '%+v'", entriesArray)
+	}
+	if _, ok := entriesMap["CPUWeight@sap.slice"]; !ok {
+		t.Errorf("entry 'CPUWeight@sap.slice' missing in map")
+	}
+	if unit := getSectTag([]string{"cgroup", "unit=sap.slice"}, "unit"); unit != "sap.slice" {
+		t.Errorf("expected 'sap.slice', got '%s'", unit)
+	}
+}
+
+func TestExpandGlobKeys(t *testing.T) {
+	ini := ParseINI(`
+[sysctl]
+net.ipv4.conf.*.rp_filter = 1
+net.ipv4.conf.all.rp_filter = 2
+net.ipv4.conf.*.doesnotexist = 1
+vm.swappiness = 10
+
+[sys]
+kernel.mm.transparent_hugepage.en*ed = never
+`)
+	exp := ExpandGlobKeys(ini)
+	if _, ok := exp.KeyValue["sysctl"]["net.ipv4.conf.*.rp_filter"]; ok {
+		t.Error("glob key not expanded")
+	}
+	if _, ok := exp.KeyValue["sysctl"]["net.ipv4.conf.*.doesnotexist"]; ok {
+		t.Error("glob key without match not removed")
+	}
+	if _, ok := exp.KeyValue["sysctl"]["net.ipv4.conf.default.rp_filter"]; !ok {
+		t.Errorf("missing expanded key - '%+v'", exp.KeyValue["sysctl"])
+	}
+	if exp.KeyValue["sysctl"]["net.ipv4.conf.default.rp_filter"].Value != "1" {
+		t.Errorf("wrong value for expanded key - '%+v'", exp.KeyValue["sysctl"]["net.ipv4.conf.default.rp_filter"])
+	}
+	if exp.KeyValue["sysctl"]["vm.swappiness"].Value != "10" {
+		t.Error("non glob key changed")
+	}
+	// the explicitly defined key takes precedence
+	cnt := 0
+	for _, entry := range exp.AllValues {
+		if entry.Key == "net.ipv4.conf.all.rp_filter" {
+			cnt++
+			if entry.Value != "2" {
+				t.Errorf("explicitly defined key overwritten - '%+v'", entry)
+			}
+		}
+	}
+	if cnt != 1 {
+		t.Errorf("key 'net.ipv4.conf.all.rp_filter' found %d times", cnt)
+	}
+	if _, err := os.Stat("/sys/kernel/mm/transparent_hugepage/enabled"); err == nil {
+		if exp.KeyValue["sys"]["sys:kernel.mm.transparent_hugepage.enabled"].Value != "never" {
+			t.Errorf("sys glob key not expanded - '%+v'", exp.KeyValue["sys"])
+		}
+	}
+}
+
+func TestParseINIComplianceOperators(t *testing.T) {
+	ini := ParseINI(`
+[sysctl]
+vm.swappiness >< 10..60
+kernel.numa_balancing =| 0 | 1
+vm.dirty_ratio =~ ^(10|20) ?$
+`)
+	exp := []INIEntry{
+		{Section: "sysctl", Key: "vm.swappiness", Operator: OperatorRange, Value: "10..60"},
+		{Section: "sysctl", Key: "kernel.numa_balancing", Operator: OperatorInSet, Value: "0\t|\t1"},
+		{Section: "sysctl", Key: "vm.dirty_ratio", Operator: OperatorRegex, Value: "^(10|20) ?$"},
+	}
+	if !reflect.DeepEqual(ini.AllValues, exp) {
+		t.Errorf("got: '%+v'", ini.AllValues)
+	}
+}
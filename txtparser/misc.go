@@ -1,8 +1,11 @@
 package txtparser
 
 import (
+	"fmt"
 	"github.com/SUSE/saptune/system"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
 // currently not used functions, for future use
@@ -56,3 +59,78 @@ func CalculateOptimumValue(operator Operator, currentValue string, expectedValue
 	}
 	return strconv.FormatInt(iCurrentValue, 10), nil
 }
+
+// IsComplianceOperator returns true, if the operator is one of the range
+// ('><'), in-set ('=|') or regex ('=~') operators
+func IsComplianceOperator(operator Operator) bool {
+	return operator == OperatorRange || operator == OperatorInSet || operator == OperatorRegex
+}
+
+// SplitInSetValue returns the members of the set of an in-set operator
+// value ('none|mq-deadline')
+func SplitInSetValue(expectedValue string) []string {
+	set := []string{}
+	for _, member := range strings.Split(expectedValue, "|") {
+		if member = strings.TrimSpace(member); member != "" {
+			set = append(set, member)
+		}
+	}
+	return set
+}
+
+// CalculateCompliantValue returns the value nearest to the current value,
+// which complies with the range ('><' with 'min..max'), in-set ('=|' with
+// 'val1|val2|...') or regex ('=~') operator and the expected value.
+// The current value is returned, if it already complies.
+// A non-compliant value of a range is set to the nearest range limit, of a
+// set to the first member of the set. For a regular expression no compliant
+// value can be derived, so false is returned.
+func CalculateCompliantValue(operator Operator, currentValue, expectedValue string) (string, bool, error) {
+	switch operator {
+	case OperatorRange:
+		limits := strings.SplitN(expectedValue, "..", 2)
+		if len(limits) != 2 {
+			return "", false, fmt.Errorf("wrong range '%s', needs to be 'min..max'", expectedValue)
+		}
+		min, err := strconv.ParseInt(strings.TrimSpace(limits[0]), 10, 64)
+		if err != nil {
+			return "", false, fmt.Errorf("lower limit of range '%s' is not an integer", expectedValue)
+		}
+		max, err := strconv.ParseInt(strings.TrimSpace(limits[1]), 10, 64)
+		if err != nil {
+			return "", false, fmt.Errorf("upper limit of range '%s' is not an integer", expectedValue)
+		}
+		if min > max {
+			return "", false, fmt.Errorf("lower limit of range '%s' is greater than the upper limit", expectedValue)
+		}
+		current, err := strconv.ParseInt(strings.TrimSpace(currentValue), 10, 64)
+		switch {
+		case err != nil || current < min:
+			current = min
+		case current > max:
+			current = max
+		}
+		return strconv.FormatInt(current, 10), true, nil
+	case OperatorInSet:
+		set := SplitInSetValue(expectedValue)
+		if len(set) == 0 {
+			return "", false, fmt.Errorf("empty set '%s'", expectedValue)
+		}
+		for _, member := range set {
+			if member == strings.TrimSpace(currentValue) {
+				return member, true, nil
+			}
+		}
+		return set[0], true, nil
+	case OperatorRegex:
+		reg, err := regexp.Compile(expectedValue)
+		if err != nil {
+			return "", false, fmt.Errorf("wrong regular expression '%s' - %v", expectedValue, err)
+		}
+		if reg.MatchString(strings.TrimSpace(currentValue)) {
+			return strings.TrimSpace(currentValue), true, nil
+		}
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("unsupported operator '%s'", operator)
+}
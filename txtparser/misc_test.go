@@ -61,3 +61,39 @@ func TestCalculateOptimumValue(t *testing.T) {
 		t.Error(val, err)
 	}
 }
+
+func TestCalculateCompliantValue(t *testing.T) {
+	tests := []struct {
+		op       Operator
+		cur, exp string
+		val      string
+		ok       bool
+	}{
+		{OperatorRange, "30", "10..60", "30", true},
+		{OperatorRange, "5", "10..60", "10", true},
+		{OperatorRange, "100", "10 .. 60", "60", true},
+		{OperatorRange, "", "10..60", "10", true},
+		{OperatorRange, "60", "10..60", "60", true},
+		{OperatorInSet, "mq-deadline", "none|mq-deadline", "mq-deadline", true},
+		{OperatorInSet, "bfq", "none | mq-deadline", "none", true},
+		{OperatorRegex, "mq-deadline", "^(none|mq-deadline)$", "mq-deadline", true},
+		{OperatorRegex, "bfq", "^(none|mq-deadline)$", "", false},
+	}
+	for _, tst := range tests {
+		val, ok, err := CalculateCompliantValue(tst.op, tst.cur, tst.exp)
+		if err != nil || val != tst.val || ok != tst.ok {
+			t.Errorf("'%s' '%s' '%s': got '%s', '%v', '%v'", tst.cur, tst.op, tst.exp, val, ok, err)
+		}
+	}
+	for _, tst := range []struct {
+		op  Operator
+		exp string
+	}{{OperatorRange, "10"}, {OperatorRange, "a..60"}, {OperatorRange, "10..b"}, {OperatorRange, "60..10"}, {OperatorInSet, " | "}, {OperatorRegex, "^(none"}, {OperatorEqual, "10"}} {
+		if val, _, err := CalculateCompliantValue(tst.op, "20", tst.exp); err == nil {
+			t.Errorf("'%s' '%s': expected error, got '%s'", tst.op, tst.exp, val)
+		}
+	}
+	if !IsComplianceOperator(OperatorRange) || !IsComplianceOperator(OperatorInSet) || !IsComplianceOperator(OperatorRegex) || IsComplianceOperator(OperatorMoreThanEqual) {
+		t.Error("wrong detection of compliance operators")
+	}
+}
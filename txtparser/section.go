@@ -107,6 +107,7 @@ func GetOverrides(filetype, ID string) (bool, *INIFile) {
 		// Parse the override file
 		ow, err = ParseINIFile(path.Join(OverrideTuningSheets, ID), false)
 		if err == nil {
+			ow = ExpandGlobKeys(ow)
 			// write section data to section runtime file
 			_ = StoreSectionInfo(ow, filetype, ID, true)
 			override = true
@@ -3,6 +3,7 @@ package txtparser
 import (
 	"fmt"
 	"github.com/SUSE/saptune/system"
+	"os"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -18,7 +19,7 @@ func isTagAvail(tag string, secFields []string) bool {
 			cnt = cnt + 1
 			continue
 		}
-		tagField := strings.Split(secTag, "=")
+		tagField := strings.SplitN(secTag, "=", 2)
 		if len(tagField) != 2 {
 			return false
 		}
@@ -29,6 +30,161 @@ func isTagAvail(tag string, secFields []string) bool {
 	return false
 }
 
+// deviceTags are the tags selecting devices or objects for the section.
+// Negation, value lists and OR groups are not supported for them
+var deviceTags = map[string]bool{"blkvendor": true, "blkmodel": true, "blkpat": true, "mount": true, "netpat": true, "netdriver": true, "irqpat": true, "unit": true}
+
+// regexTags are the tags, whose values are regular expressions. Negation
+// and OR groups are supported, but a '|' is part of the regular expression
+// and does not separate a value list
+var regexTags = map[string]bool{"vendor": true, "model": true}
+
+// tagQuiet controls the logging of tagInfoLog and tagMsgs collects the
+// messages, while tagQuiet is set
+var tagQuiet = false
+var tagMsgs = []string{}
+
+// tagInfoLog logs the reason, why a section tag does not match the running
+// system. During the check of a tag expression the messages are collected
+// and only logged, if the whole expression does not match
+func tagInfoLog(txt string, stuff ...interface{}) {
+	if tagQuiet {
+		tagMsgs = append(tagMsgs, fmt.Sprintf(txt, stuff...))
+		return
+	}
+	system.InfoLog(txt, stuff...)
+}
+
+// isTagExpression checks, if a section tag uses negation ('csp!=aws'),
+// value lists ('csp=azure|google') or OR groups ('csp=azure||virt=kvm')
+// a '|' in the value of a device tag or a regular expression tag is part of
+// the regular expression (e.g. 'blkpat=sd[ab]|nvme')
+func isTagExpression(secTag string) bool {
+	if strings.Contains(secTag, "!=") || strings.Contains(secTag, "||") {
+		return true
+	}
+	name := strings.SplitN(secTag, "=", 2)[0]
+	return strings.Contains(secTag, "|") && !deviceTags[name] && !regexTags[name]
+}
+
+// regTagExpression splits a single alternative of a tag expression into tag
+// name, operator and value
+var regTagExpression = regexp.MustCompile(`^(\w+)(!=|<=|>=|~=|=|<|>)(.*)$`)
+
+// factTags are the tags comparing host facts, which support the operators
+// '<', '<=', '>', '>=' (or '~=' for the hostname) additional to '='
+var factTags = map[string]bool{"mem": true, "cpus": true, "numa": true, "kernel": true, "hostname": true}
+
+// splitTagExpression splits a single alternative of a tag expression into
+// tag name, operator, list of values and negation
+func splitTagExpression(tagExpr string) (string, string, []string, bool, error) {
+	negate := false
+	tagField := regTagExpression.FindStringSubmatch(tagExpr)
+	if tagField == nil || tagField[3] == "" {
+		return "", "", nil, false, fmt.Errorf("wrong syntax of tag expression '%s'", tagExpr)
+	}
+	op := tagField[2]
+	if op == "!=" {
+		negate = true
+		op = "="
+	}
+	if op != "=" && !factTags[tagField[1]] {
+		return "", "", nil, false, fmt.Errorf("operator '%s' not supported for tag '%s'", tagField[2], tagField[1])
+	}
+	values := []string{tagField[3]}
+	if !regexTags[tagField[1]] {
+		values = strings.Split(tagField[3], "|")
+	}
+	for _, val := range values {
+		if val == "" {
+			return "", "", nil, false, fmt.Errorf("empty value in tag expression '%s'", tagExpr)
+		}
+	}
+	return tagField[1], op, values, negate, nil
+}
+
+// chkTagExpression checks a section tag with negation, value lists or OR
+// groups. The alternatives of an OR group are separated by '||', the values
+// of a value list by '|'. The tag matches, if one of the alternatives
+// matches. An alternative matches, if one of the values matches or - if
+// negated - none of the values matches
+func chkTagExpression(secTag string, secFields, blkDev []string) bool {
+	defer func() {
+		tagQuiet = false
+		tagMsgs = []string{}
+	}()
+	for _, alt := range strings.Split(secTag, "||") {
+		name, op, values, negate, err := splitTagExpression(alt)
+		if err != nil {
+			system.WarningLog("%v, skipping whole section '%v'. Please check. ", err, secFields)
+			return false
+		}
+		if deviceTags[name] {
+			system.WarningLog("negation, value lists and OR groups are not supported for tag '%s', skipping whole section '%v'. Please check. ", name, secFields)
+			return false
+		}
+		match := false
+		tagQuiet = true
+		for _, val := range values {
+			if factTags[name] {
+				match = chkFactTags(name, op, val, secFields)
+			} else {
+				match, _ = chkTag(name, val, secFields, blkDev)
+			}
+			if match {
+				break
+			}
+		}
+		tagQuiet = false
+		if negate {
+			if match {
+				tagMsgs = append(tagMsgs, fmt.Sprintf("tag '%s' in section definition '%v' does not match, because the running system matches one of the negated values. Skipping whole section with all lines till next valid section definition", alt, secFields))
+			}
+			match = !match
+		}
+		if match {
+			return true
+		}
+	}
+	for _, msg := range tagMsgs {
+		system.InfoLog(msg)
+	}
+	return false
+}
+
+// chkTag checks, if a single tag of a section is valid
+func chkTag(name, value string, secFields, blkDev []string) (bool, []string) {
+	ret := true
+	switch name {
+	case "os":
+		ret = chkOsTags(value, secFields)
+	case "arch":
+		ret = chkArchTags(value, secFields)
+	case "csp":
+		ret = chkCspTags(value, secFields)
+	case "virt":
+		ret = chkVirtTags(value, secFields)
+	case "blkvendor", "blkmodel", "blkpat":
+		ret, blkDev = chkBlkTags(name, value, secFields, blkDev)
+	case "mount":
+		ret, blkDev = chkMountTags(value, secFields, blkDev)
+	case "netpat", "netdriver":
+		ret = chkNetTags(name, value, secFields)
+	case "unit":
+		// systemd unit of the section [cgroup], no check needed
+		ret = true
+	case "irqpat":
+		ret = chkIRQTags(value, secFields)
+	case "vendor", "model":
+		ret = chkHWTags(name, value, secFields)
+	case "pmu_name":
+		ret = chkCPUTags(value, secFields)
+	default:
+		ret = chkOtherTags(name, value, secFields)
+	}
+	return ret, blkDev
+}
+
 // chkSecTags checks, if the tags of a section are valid
 func chkSecTags(secFields, blkDev []string) (bool, []string) {
 	ret := true
@@ -43,30 +199,24 @@ func chkSecTags(secFields, blkDev []string) (bool, []string) {
 			// support empty tags
 			continue
 		}
+		if isTagExpression(secTag) {
+			if ret = chkTagExpression(secTag, secFields, blkDev); !ret {
+				break
+			}
+			continue
+		}
+		if fact := regTagExpression.FindStringSubmatch(secTag); fact != nil && factTags[fact[1]] {
+			if ret = chkFactTags(fact[1], fact[2], fact[3], secFields); !ret {
+				break
+			}
+			continue
+		}
 		tagField := strings.Split(secTag, "=")
 		if len(tagField) != 2 {
 			system.WarningLog("wrong syntax of section tag '%s', skipping whole section '%v'. Please check. ", secTag, secFields)
 			return false, blkDev
 		}
-		switch tagField[0] {
-		case "os":
-			ret = chkOsTags(tagField[1], secFields)
-		case "arch":
-			ret = chkArchTags(tagField[1], secFields)
-		case "csp":
-			ret = chkCspTags(tagField[1], secFields)
-		case "virt":
-			ret = chkVirtTags(tagField[1], secFields)
-		case "blkvendor", "blkmodel", "blkpat":
-			ret, blkDev = chkBlkTags(tagField[0], tagField[1], secFields, blkDev)
-		case "vendor", "model":
-			ret = chkHWTags(tagField[0], tagField[1], secFields)
-		case "pmu_name":
-			ret = chkCPUTags(tagField[1], secFields)
-		default:
-			ret = chkOtherTags(tagField[0], tagField[1], secFields)
-		}
-		if !ret {
+		if ret, blkDev = chkTag(tagField[0], tagField[1], secFields, blkDev); !ret {
 			break
 		}
 	}
@@ -87,17 +237,17 @@ func chkOsTags(tagField string, secFields []string) bool {
 		// wildcard 15-* or 15.*
 		// check for supported os version
 		if osw[1] != "12" && osw[1] != "15" && osw[1] != "16" {
-			system.InfoLog("unsupported os version '%s' in section definition '%v'. Skipping whole section with all lines till next valid section definition", osw[1], secFields)
+			tagInfoLog("unsupported os version '%s' in section definition '%v'. Skipping whole section with all lines till next valid section definition", osw[1], secFields)
 			ret = false
 		}
 		// check runing os version
 		if !system.IsSLE(osw[1]) {
-			system.InfoLog("os version '%s' in section definition '%v' does not match running os version '%s'. Skipping whole section with all lines till next valid section definition", tagField, secFields, system.GetOsVers())
+			tagInfoLog("os version '%s' in section definition '%v' does not match running os version '%s'. Skipping whole section with all lines till next valid section definition", tagField, secFields, system.GetOsVers())
 			ret = false
 		}
 	} else {
 		// wrong syntax
-		system.InfoLog("wrong syntax for tag in section definition '%v'.  Skipping whole section with all lines till next valid section definition", secFields)
+		tagInfoLog("wrong syntax for tag in section definition '%v'.  Skipping whole section with all lines till next valid section definition", secFields)
 		ret = false
 	}
 	return ret
@@ -111,7 +261,7 @@ func chkMultiRelease(tagField string, secFields []string) bool {
 	if len(osmsps) > 1 {
 		// check for supported os version
 		if osmsps[1] != "12" && osmsps[1] != "15" && osmsps[1] != "16" {
-			system.InfoLog("unsupported os version '%s' in section definition '%v'. Skipping whole section with all lines till next valid section definition", osmsps[1], secFields)
+			tagInfoLog("unsupported os version '%s' in section definition '%v'. Skipping whole section with all lines till next valid section definition", osmsps[1], secFields)
 			return false
 		}
 	}
@@ -119,7 +269,7 @@ func chkMultiRelease(tagField string, secFields []string) bool {
 		// len == -> 15-SP6, 16.0
 		if tagField != system.GetOsVers() {
 			// os version does not match
-			system.InfoLog("os version '%s' in section definition '%v' does not match running os version '%s'. Skipping whole section with all lines till next valid section definition", tagField, secFields, system.GetOsVers())
+			tagInfoLog("os version '%s' in section definition '%v' does not match running os version '%s'. Skipping whole section with all lines till next valid section definition", tagField, secFields, system.GetOsVers())
 			return false
 		}
 		return true
@@ -139,7 +289,7 @@ func chkRelease(relList string, secFields []string) bool {
 		if len(relrange) == 1 {
 			// [2]
 			if rel != system.GetOsRel() {
-				system.InfoLog("os release '%s' in section definition '%v' does not match running os release '%s'. Skipping whole section with all lines till next valid section definition", rel, secFields, system.GetOsRel())
+				tagInfoLog("os release '%s' in section definition '%v' does not match running os release '%s'. Skipping whole section with all lines till next valid section definition", rel, secFields, system.GetOsRel())
 				continue
 			} else {
 				ret = true
@@ -149,7 +299,7 @@ func chkRelease(relList string, secFields []string) bool {
 
 		if relrange[0] == "" && relrange[1] == "" {
 			// wrong syntax [-]
-			system.InfoLog("wrong syntax for tag in section definition '%v'.  Skipping whole section with all lines till next valid section definition", secFields)
+			tagInfoLog("wrong syntax for tag in section definition '%v'.  Skipping whole section with all lines till next valid section definition", secFields)
 			continue
 		}
 		if relrange[0] == "" {
@@ -191,7 +341,7 @@ func chkArchTags(tagField string, secFields []string) bool {
 	}
 	if tagField != chkArch {
 		// arch does not match
-		system.InfoLog("system architecture '%s' in section definition '%v' does not match the architecture of the running system '%s'. Skipping whole section with all lines till next valid section definition", tagField, secFields, chkArch)
+		tagInfoLog("system architecture '%s' in section definition '%v' does not match the architecture of the running system '%s'. Skipping whole section with all lines till next valid section definition", tagField, secFields, chkArch)
 		ret = false
 	}
 	return ret
@@ -206,7 +356,7 @@ func chkCspTags(tagField string, secFields []string) bool {
 		if chkCsp == "" {
 			chkCsp = "not a cloud"
 		}
-		system.InfoLog("cloud service provider '%s' in section definition '%v' does not match the cloud service provider of the running system ('%s'). Skipping whole section with all lines till next valid section definition", tagField, secFields, chkCsp)
+		tagInfoLog("cloud service provider '%s' in section definition '%v' does not match the cloud service provider of the running system ('%s'). Skipping whole section with all lines till next valid section definition", tagField, secFields, chkCsp)
 		ret = false
 	}
 	return ret
@@ -228,18 +378,18 @@ func chkVirtTags(tagField string, secFields []string) bool {
 	if vopt != "" {
 		if !virt {
 			ret = false
-			system.InfoLog("virtualization class type '%s' in section definition '%v' does not match the virtualization class type of the running system. Skipping whole section with all lines till next valid section definition", tagField, secFields)
+			tagInfoLog("virtualization class type '%s' in section definition '%v' does not match the virtualization class type of the running system. Skipping whole section with all lines till next valid section definition", tagField, secFields)
 		}
 		return ret
 	}
 	// order of vopt and err check is by intention
 	if err != nil {
-		system.InfoLog("No virtualization detected - error with systemd-detect-virt. Skipping whole section '%v' with all lines till next valid section definition", secFields)
+		tagInfoLog("No virtualization detected - error with systemd-detect-virt. Skipping whole section '%v' with all lines till next valid section definition", secFields)
 		return false
 	}
 	if tagField != chkVirt {
 		// virtualization type does not match
-		system.InfoLog("virtualization type '%s' in section definition '%v' does not match the virtualization type of the running system ('%s'). Skipping whole section with all lines till next valid section definition", tagField, secFields, chkVirt)
+		tagInfoLog("virtualization type '%s' in section definition '%v' does not match the virtualization type of the running system ('%s'). Skipping whole section with all lines till next valid section definition", tagField, secFields, chkVirt)
 		ret = false
 	}
 	return ret
@@ -261,7 +411,7 @@ func chkOtherTags(file, tagField string, secFields []string) bool {
 		match, _ := regexp.MatchString(tagExpr, chkDmi)
 		if !match {
 			// content of file does not match
-			system.InfoLog("the string '%s' in section definition '%v' does not match the content of the file '/sys/class/dmi/id/%s' ('%s'). Skipping whole section with all lines till next valid section definition", tagField, secFields, file, chkDmi)
+			tagInfoLog("the string '%s' in section definition '%v' does not match the content of the file '/sys/class/dmi/id/%s' ('%s'). Skipping whole section with all lines till next valid section definition", tagField, secFields, file, chkDmi)
 			ret = false
 		}
 	}
@@ -275,7 +425,7 @@ func chkCPUTags(tagField string, secFields []string) bool {
 	chkCPUpf := system.CPUPlatform()
 	if tagField != chkCPUpf {
 		// CPU platform does not match
-		system.InfoLog("CPU platform '%s' in section definition '%v' does not match the CPU platform of the running system '%s'. Skipping whole section with all lines till next valid section definition", tagField, secFields, chkCPUpf)
+		tagInfoLog("CPU platform '%s' in section definition '%v' does not match the CPU platform of the running system '%s'. Skipping whole section with all lines till next valid section definition", tagField, secFields, chkCPUpf)
 		ret = false
 	}
 	return ret
@@ -295,7 +445,7 @@ func chkHWTags(info, tagField string, secFields []string) bool {
 	} else {
 		match, _ := regexp.MatchString(tagExpr, chkHW)
 		if !match {
-			system.InfoLog("hardware %s '%s' in section definition '%v' does not match the hardware %s of the running system ('%s'). Skipping whole section with all lines till next valid section definition", info, tagField, secFields, info, chkHW)
+			tagInfoLog("hardware %s '%s' in section definition '%v' does not match the hardware %s of the running system ('%s'). Skipping whole section with all lines till next valid section definition", info, tagField, secFields, info, chkHW)
 			ret = false
 		}
 	}
@@ -318,7 +468,7 @@ func chkBlkTags(info, tagField string, secFields, actbdev []string) (bool, []str
 	bdev := system.GetAvailBlockInfo(blkInfo, tagExpr)
 	if len(bdev) == 0 {
 		// pattern, vendor or model does not match
-		system.InfoLog("%s '%s' in section definition '%v' does not match any available block device %s of the running system. Skipping whole section with all lines till next valid section definition", info, tagField, secFields, info)
+		tagInfoLog("%s '%s' in section definition '%v' does not match any available block device %s of the running system. Skipping whole section with all lines till next valid section definition", info, tagField, secFields, info)
 	} else {
 		// as it is possible to have more than one tag in a
 		// section (vendor and module) we need the overlap for
@@ -350,3 +500,166 @@ func chkBlkTags(info, tagField string, secFields, actbdev []string) (bool, []str
 	}
 	return ret, bdev
 }
+
+// chkMountTags checks if the mount section tag is valid or not and returns
+// the list of valid block devices backing the file systems mounted at the
+// mount points. More than one mount point can be separated by '|'
+// (e.g. 'mount=/hana/data|/hana/log')
+func chkMountTags(tagField string, secFields, actbdev []string) (bool, []string) {
+	bdev := system.GetBlockDevicesByMount(actbdev, strings.Split(tagField, "|")...)
+	if len(bdev) == 0 {
+		tagInfoLog("mount point '%s' in section definition '%v' is not backed by any available block device of the running system. Skipping whole section with all lines till next valid section definition", tagField, secFields)
+		return false, bdev
+	}
+	return true, bdev
+}
+
+// chkIRQTags checks if the irqpat section tag is valid or not
+// the section is valid, if at least one interrupt of the running system
+// matches the pattern
+func chkIRQTags(tagField string, secFields []string) bool {
+	if len(system.GetIRQsByPattern(tagField)) == 0 {
+		tagInfoLog("irqpat '%s' in section definition '%v' does not match any interrupt of the running system. Skipping whole section with all lines till next valid section definition", tagField, secFields)
+		return false
+	}
+	return true
+}
+
+// chkNetTags checks if the netpat or netdriver section tag is valid or not
+// the section is valid, if at least one network interface of the running
+// system matches the pattern
+func chkNetTags(info, tagField string, secFields []string) bool {
+	info = strings.TrimPrefix(info, "net")
+	if len(system.GetNetInterfacesByTag(info, tagField)) == 0 {
+		tagInfoLog("net%s '%s' in section definition '%v' does not match any network interface of the running system. Skipping whole section with all lines till next valid section definition", info, tagField, secFields)
+		return false
+	}
+	return true
+}
+
+// chkFactTags checks, if a tag comparing a host fact is valid or not
+// 'mem' compares the size of the main memory (MemTotal of /proc/meminfo)
+// with a size with an optional binary unit (e.g. 'mem>=512G'), 'cpus' the
+// number of online CPUs, 'numa' the number of NUMA nodes, 'kernel' the
+// version of the running kernel (e.g. 'kernel>=6.4') and 'hostname' the
+// hostname with a string or a regular expression (e.g. 'hostname~=^hana')
+func chkFactTags(name, op, tagField string, secFields []string) bool {
+	actual := ""
+	cmp := 0
+	var err error
+	switch name {
+	case "hostname":
+		actual, _ = os.Hostname()
+		match := actual == tagField
+		switch op {
+		case "~=":
+			match, err = regexp.MatchString(tagField, actual)
+		case "=":
+		default:
+			err = fmt.Errorf("operator '%s' not supported", op)
+		}
+		if err != nil {
+			system.WarningLog("wrong section tag '%s%s%s' - %v, skipping whole section '%v'. Please check. ", name, op, tagField, err, secFields)
+			return false
+		}
+		if !match {
+			tagInfoLog("hostname '%s' in section definition '%v' does not match the hostname of the running system ('%s'). Skipping whole section with all lines till next valid section definition", tagField, secFields, actual)
+		}
+		return match
+	case "kernel":
+		actual = system.GetKernelRelease()
+		cmp, err = cmpKernelVersion(actual, tagField)
+	case "mem":
+		var expected string
+		memTotal := system.ParseMeminfo()[system.MemMainTotalKey] * 1024
+		actual = strconv.FormatUint(memTotal, 10)
+		if expected, err = EvalExpression(tagField, nil); err == nil {
+			cmp = cmpUint(memTotal, expected)
+		}
+	case "cpus":
+		actual = strconv.Itoa(system.GetOnlineCPUCount())
+		cmp, err = cmpNumber(actual, tagField)
+	case "numa":
+		numaNodes := len(system.GetNUMANodes())
+		if numaNodes == 0 {
+			// system without NUMA support
+			numaNodes = 1
+		}
+		actual = strconv.Itoa(numaNodes)
+		cmp, err = cmpNumber(actual, tagField)
+	}
+	if err != nil || op == "~=" {
+		if err == nil {
+			err = fmt.Errorf("operator '%s' not supported", op)
+		}
+		system.WarningLog("wrong section tag '%s%s%s' - %v, skipping whole section '%v'. Please check. ", name, op, tagField, err, secFields)
+		return false
+	}
+	match := false
+	switch op {
+	case "=":
+		match = cmp == 0
+	case "<":
+		match = cmp < 0
+	case "<=":
+		match = cmp <= 0
+	case ">":
+		match = cmp > 0
+	case ">=":
+		match = cmp >= 0
+	}
+	if !match {
+		tagInfoLog("%s '%s%s' in section definition '%v' does not match the running system ('%s'). Skipping whole section with all lines till next valid section definition", name, op, tagField, secFields, actual)
+	}
+	return match
+}
+
+// cmpUint compares a number with the number string of a tag and returns
+// -1, 0 or 1
+func cmpUint(actual uint64, expected string) int {
+	exp, _ := strconv.ParseUint(expected, 10, 64)
+	switch {
+	case actual < exp:
+		return -1
+	case actual > exp:
+		return 1
+	}
+	return 0
+}
+
+// cmpNumber compares the number strings of a host fact and a tag and
+// returns -1, 0 or 1
+func cmpNumber(actual, expected string) (int, error) {
+	if _, err := strconv.ParseUint(expected, 10, 64); err != nil {
+		return 0, fmt.Errorf("'%s' is not a number", expected)
+	}
+	act, _ := strconv.ParseUint(actual, 10, 64)
+	return cmpUint(act, expected), nil
+}
+
+// cmpKernelVersion compares the numeric components of the kernel release
+// (e.g. '6.4.0-150600.23-default') with the version of a tag (e.g. '6.4')
+// and returns -1, 0 or 1. Only as many components as given in the tag are
+// compared, so 'kernel=6.4' matches all 6.4 kernels
+func cmpKernelVersion(release, version string) (int, error) {
+	split := func(r rune) bool { return r == '.' || r == '-' }
+	relFields := strings.FieldsFunc(release, split)
+	for i, verField := range strings.FieldsFunc(version, split) {
+		ver, err := strconv.Atoi(verField)
+		if err != nil {
+			return 0, fmt.Errorf("wrong kernel version '%s'", version)
+		}
+		rel := 0
+		if i < len(relFields) {
+			// non-numeric components like 'default' count as 0
+			rel, _ = strconv.Atoi(relFields[i])
+		}
+		if rel < ver {
+			return -1, nil
+		}
+		if rel > ver {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
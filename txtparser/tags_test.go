@@ -4,6 +4,10 @@ import (
 	"github.com/SUSE/saptune/system"
 	"os"
 	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -126,6 +130,37 @@ func TestChkHWTags(t *testing.T) {
 		t.Errorf("tag '%s' matches content of %s file, but shouldn't", tag, info)
 	}
 
+	// '|' is part of the regular expression and no value list
+	tests := map[string]bool{
+		"model=Xeon.*(Gold|Platinum)":   false,
+		"model=SUSE (sapconf|saptune)":  true,
+		"model!=SUSE (sapconf|saptune)": false,
+		"model!=Xeon.*(Gold|Platinum)":  true,
+	}
+	for tag, exp := range tests {
+		if ret, _ := chkSecTags([]string{"sysctl", tag}, []string{}); ret != exp {
+			t.Errorf("'%s': expected '%v', got '%v'", tag, exp, ret)
+		}
+	}
+	if isTagExpression("model=Xeon.*(Gold|Platinum)") {
+		t.Error("regular expression wrongly detected as tag expression")
+	}
+	// pmu_name is compared literally, so '|' separates a value list
+	if !isTagExpression("pmu_name=skylake|icelake") {
+		t.Error("value list of pmu_name not detected as tag expression")
+	}
+	if name, _, vals, _, err := splitTagExpression("pmu_name=skylake|icelake"); err != nil || name != "pmu_name" || !reflect.DeepEqual(vals, []string{"skylake", "icelake"}) {
+		t.Errorf("got '%s', '%+v', '%v'", name, vals, err)
+	}
+	if ret, _ := chkSecTags([]string{"sysctl", "pmu_name=saptune_pf1|saptune_pf2"}, []string{}); ret {
+		t.Error("pmu_name value list matches, but shouldn't")
+	}
+	if pf := system.CPUPlatform(); pf != "" {
+		if ret, _ := chkSecTags([]string{"sysctl", "pmu_name=saptune_pf1|" + pf}, []string{}); !ret {
+			t.Errorf("pmu_name value list does not match CPU platform '%s'", pf)
+		}
+	}
+
 	os.Rename(path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/testdata/product_name"), path.Join(os.Getenv("GOPATH"), "/src/github.com/SUSE/saptune/testdata/product_name_OrG"))
 	tag = "SUSE saptune"
 	ret = chkHWTags(info, tag, secFields)
@@ -204,3 +239,125 @@ func TestIsTagAvail(t *testing.T) {
 		t.Error("expected 'false', because of wrong syntax, but got 'true'")
 	}
 }
+
+func TestChkTagExpression(t *testing.T) {
+	chkArch := runtime.GOARCH
+	if chkArch == "amd64" {
+		chkArch = "x86_64"
+	}
+	secFields := []string{"sysctl"}
+	tests := map[string]bool{
+		"arch=" + chkArch:                      true,
+		"arch!=" + chkArch:                     false,
+		"arch!=s390x":                          true,
+		"arch=s390x|" + chkArch:                true,
+		"arch=s390x|i586":                      false,
+		"arch!=s390x|" + chkArch:               false,
+		"arch=s390x||arch=" + chkArch:          true,
+		"arch=s390x||arch!=" + chkArch:         false,
+		"arch=s390x||arch!=i586":               true,
+		"arch=s390x|i586||arch=" + chkArch:     true,
+		"arch=s390x||arch=i586||arch=aarch128": false,
+	}
+	for tag, exp := range tests {
+		if !isTagExpression(tag) && tag != "arch="+chkArch {
+			t.Errorf("'%s' not detected as tag expression", tag)
+		}
+		if ret, _ := chkSecTags(append(secFields, tag), []string{}); ret != exp {
+			t.Errorf("'%s': expected '%v', got '%v'", tag, exp, ret)
+		}
+	}
+	// wrong syntax and unsupported tags
+	for _, tag := range []string{"arch!=", "!=s390x", "arch=s390x|", "arch=s390x||", "blkpat!=sda", "netpat=eth0||arch=" + chkArch} {
+		if ret, _ := chkSecTags(append(secFields, tag), []string{}); ret {
+			t.Errorf("'%s': expected 'false', got 'true'", tag)
+		}
+	}
+	// '|' is part of the regular expression of device tags
+	if isTagExpression("blkpat=sd[ab]|nvme") {
+		t.Error("'blkpat=sd[ab]|nvme' wrongly detected as tag expression")
+	}
+	if len(tagMsgs) != 0 || tagQuiet {
+		t.Errorf("tag messages not reset - '%v', '%v'", tagMsgs, tagQuiet)
+	}
+	// OR group in front of a device tag
+	if !isTagAvail("blkvendor", []string{"block", "csp=azure||virt=kvm", "blkvendor=HUGO"}) {
+		t.Error("tag 'blkvendor' not found")
+	}
+}
+
+func TestChkFactTags(t *testing.T) {
+	secFields := []string{"sysctl"}
+	cpus := strconv.Itoa(system.GetOnlineCPUCount())
+	host, _ := os.Hostname()
+	release := system.GetKernelRelease()
+	major := strings.Split(release, ".")[0]
+	tests := map[string]bool{
+		"cpus=" + cpus:        true,
+		"cpus>=" + cpus:       true,
+		"cpus>" + cpus:        false,
+		"cpus<100000":         true,
+		"numa>=1":             true,
+		"numa<1":              false,
+		"mem>=1M":             true,
+		"mem<1K":              false,
+		"mem<=1024T":          true,
+		"kernel=" + major:     true,
+		"kernel>=" + major:    true,
+		"kernel<" + major:     false,
+		"kernel>2.6.32":       true,
+		"hostname=" + host:    true,
+		"hostname~=^" + host:  true,
+		"hostname=no_host":    false,
+		"hostname~=^no_host$": false,
+		"cpus!=" + cpus:       false,
+		"cpus=1|" + cpus:      true,
+		"cpus<1||numa>=1":     true,
+	}
+	for tag, exp := range tests {
+		if ret, _ := chkSecTags(append(secFields, tag), []string{}); ret != exp {
+			t.Errorf("'%s': expected '%v', got '%v'", tag, exp, ret)
+		}
+	}
+	// wrong syntax
+	for _, tag := range []string{"cpus>=many", "mem>=lots", "kernel>=6.x", "hostname>=a", "cpus~=1", "hostname~=^(a", "arch>=x86_64"} {
+		if ret, _ := chkSecTags(append(secFields, tag), []string{}); ret {
+			t.Errorf("'%s': expected 'false', got 'true'", tag)
+		}
+	}
+}
+
+func TestCmpKernelVersion(t *testing.T) {
+	tests := []struct {
+		release, version string
+		cmp              int
+	}{
+		{"6.4.0-150600.23-default", "6.4", 0},
+		{"6.4.0-150600.23-default", "6.4.0-150600.24", -1},
+		{"6.4.0-150600.23-default", "5.14", 1},
+		{"6.4.0-150600.23-default", "6.10", -1},
+		{"5.14.21-150500.55.52-default", "5.14.21", 0},
+		{"6.4", "6.4.1", -1},
+	}
+	for _, tst := range tests {
+		if cmp, err := cmpKernelVersion(tst.release, tst.version); err != nil || cmp != tst.cmp {
+			t.Errorf("'%s' '%s': expected '%d', got '%d' - '%v'", tst.release, tst.version, tst.cmp, cmp, err)
+		}
+	}
+	if _, err := cmpKernelVersion("6.4.0", "6.a"); err == nil {
+		t.Error("expected error for wrong kernel version")
+	}
+}
+
+func TestChkMountTags(t *testing.T) {
+	secFields := []string{"block", "mount=/does/not/exist"}
+	if ret, bdev := chkMountTags("/does/not/exist", secFields, []string{"sda"}); ret || len(bdev) != 0 {
+		t.Errorf("expected 'false' and no block devices, got '%v', '%v'", ret, bdev)
+	}
+	if isTagExpression("mount=/hana/data|/hana/log") {
+		t.Error("'mount=/hana/data|/hana/log' wrongly detected as tag expression")
+	}
+	if ret, _ := chkSecTags([]string{"block", "mount!=/hana/data"}, []string{"sda"}); ret {
+		t.Error("negation should not be supported for tag 'mount'")
+	}
+}